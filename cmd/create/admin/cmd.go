@@ -171,7 +171,7 @@ func run(cmd *cobra.Command, _ []string) {
 		if len(passwordArg) != 0 {
 			delete(outputObject, "password")
 		}
-		err = output.Print(outputObject)
+		err = output.Print(os.Stdout, outputObject)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
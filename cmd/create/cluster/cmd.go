@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -1330,8 +1331,11 @@ func run(cmd *cobra.Command, _ []string) {
 	} else {
 		versionFilter = ""
 	}
-	regionList, regionAZ, err := r.OCMClient.GetRegionList(multiAZ, roleARN, externalID, versionFilter,
+	ctx, cancel := r.OperationContext()
+	regionList, regionAZ, err := r.OCMClient.GetRegionList(ctx, multiAZ, roleARN, externalID, versionFilter,
 		awsClient, isHostedCP, shardPinningEnabled)
+	err = r.OperationError(ctx, err)
+	cancel()
 	if err != nil {
 		r.Reporter.Errorf(fmt.Sprintf("%s", err))
 		os.Exit(1)
@@ -1605,7 +1609,10 @@ func run(cmd *cobra.Command, _ []string) {
 			}
 
 			if selectAvailabilityZones {
-				optionsAvailabilityZones, err := awsClient.DescribeAvailabilityZones()
+				ctx, cancel := r.OperationContext()
+				optionsAvailabilityZones, err := awsClient.DescribeAvailabilityZones(ctx)
+				err = r.OperationError(ctx, err)
+				cancel()
 				if err != nil {
 					r.Reporter.Errorf("Failed to get the list of the availability zone: %s", err)
 					os.Exit(1)
@@ -1620,7 +1627,10 @@ func run(cmd *cobra.Command, _ []string) {
 		}
 
 		if isAvailabilityZonesSet || selectAvailabilityZones {
-			err = validateAvailabilityZones(multiAZ, availabilityZones, awsClient)
+			ctx, cancel := r.OperationContext()
+			err = validateAvailabilityZones(ctx, multiAZ, availabilityZones, awsClient)
+			err = r.OperationError(ctx, err)
+			cancel()
 			if err != nil {
 				r.Reporter.Errorf(fmt.Sprintf("%s", err))
 				os.Exit(1)
@@ -1676,8 +1686,11 @@ func run(cmd *cobra.Command, _ []string) {
 	}
 	// Compute node instance type:
 	computeMachineType := args.computeMachineType
-	computeMachineTypeList, err := r.OCMClient.GetAvailableMachineTypesInRegion(region, availabilityZones, roleARN,
-		awsClient)
+	ctx, cancel = r.OperationContext()
+	computeMachineTypeList, err := r.OCMClient.GetAvailableMachineTypesInRegion(ctx, region, availabilityZones,
+		roleARN, externalID, awsClient)
+	err = r.OperationError(ctx, err)
+	cancel()
 	if err != nil {
 		r.Reporter.Errorf(fmt.Sprintf("%s", err))
 		os.Exit(1)
@@ -2570,13 +2583,14 @@ func selectAvailabilityZonesInteractively(cmd *cobra.Command, optionsAvailabilit
 	return availabilityZones, nil
 }
 
-func validateAvailabilityZones(multiAZ bool, availabilityZones []string, awsClient aws.Client) error {
+func validateAvailabilityZones(ctx context.Context, multiAZ bool, availabilityZones []string,
+	awsClient aws.Client) error {
 	err := ocm.ValidateAvailabilityZonesCount(multiAZ, len(availabilityZones))
 	if err != nil {
 		return err
 	}
 
-	regionAvailabilityZones, err := awsClient.DescribeAvailabilityZones()
+	regionAvailabilityZones, err := awsClient.DescribeAvailabilityZones(ctx)
 	if err != nil {
 		return fmt.Errorf("Failed to get the list of the availability zone: %s", err)
 	}
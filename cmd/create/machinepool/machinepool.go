@@ -278,8 +278,12 @@ func addMachinePool(cmd *cobra.Command, clusterKey string, cluster *cmv1.Cluster
 
 	// Machine pool instance type:
 	instanceType := args.instanceType
-	instanceTypeList, err := r.OCMClient.GetAvailableMachineTypesInRegion(cluster.Region().ID(), availabilityZonesFilter,
-		cluster.AWS().STS().RoleARN(), r.AWSClient)
+	ctx, cancel := r.OperationContext()
+	instanceTypeList, err := r.OCMClient.GetAvailableMachineTypesInRegion(ctx, cluster.Region().ID(),
+		availabilityZonesFilter, cluster.AWS().STS().RoleARN(), cluster.AWS().STS().ExternalID(),
+		r.AWSClient)
+	err = r.OperationError(ctx, err)
+	cancel()
 	if err != nil {
 		r.Reporter.Errorf(fmt.Sprintf("%s", err))
 		os.Exit(1)
@@ -434,7 +438,7 @@ func addMachinePool(cmd *cobra.Command, clusterKey string, cluster *cmv1.Cluster
 	}
 
 	if output.HasFlag() {
-		if err = output.Print(createdMachinePool); err != nil {
+		if err = output.Print(os.Stdout, createdMachinePool); err != nil {
 			r.Reporter.Errorf("Unable to print machine pool: %v", err)
 			os.Exit(1)
 		}
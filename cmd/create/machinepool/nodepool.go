@@ -253,8 +253,12 @@ func addNodePool(cmd *cobra.Command, clusterKey string, cluster *cmv1.Cluster, r
 
 	availabilityZonesFilter := cluster.Nodes().AvailabilityZones()
 	instanceType := args.instanceType
-	instanceTypeList, err := r.OCMClient.GetAvailableMachineTypesInRegion(cluster.Region().ID(),
-		availabilityZonesFilter, cluster.AWS().STS().RoleARN(), r.AWSClient)
+	ctx, cancel := r.OperationContext()
+	instanceTypeList, err := r.OCMClient.GetAvailableMachineTypesInRegion(ctx, cluster.Region().ID(),
+		availabilityZonesFilter, cluster.AWS().STS().RoleARN(), cluster.AWS().STS().ExternalID(),
+		r.AWSClient)
+	err = r.OperationError(ctx, err)
+	cancel()
 	if err != nil {
 		r.Reporter.Errorf(fmt.Sprintf("%s", err))
 		os.Exit(1)
@@ -325,7 +329,7 @@ func addNodePool(cmd *cobra.Command, clusterKey string, cluster *cmv1.Cluster, r
 	}
 
 	if output.HasFlag() {
-		if err = output.Print(createdNodePool); err != nil {
+		if err = output.Print(os.Stdout, createdNodePool); err != nil {
 			r.Reporter.Errorf("Unable to print machine pool: %v", err)
 			os.Exit(1)
 		}
@@ -453,7 +453,7 @@ func (s *CreateUnmanagedOidcConfigAutoStrategy) execute(r *rosa.Runtime) {
 		os.Exit(1)
 	}
 	if output.HasFlag() {
-		err = output.Print(oidcConfig)
+		err = output.Print(os.Stdout, oidcConfig)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -606,7 +606,7 @@ func (s *CreateManagedOidcConfigAutoStrategy) execute(r *rosa.Runtime) {
 	}
 	s.oidcConfigInput.IssuerUrl = oidcConfig.IssuerUrl()
 	if output.HasFlag() {
-		err = output.Print(oidcConfig)
+		err = output.Print(os.Stdout, oidcConfig)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
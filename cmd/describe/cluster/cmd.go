@@ -86,7 +86,7 @@ func run(cmd *cobra.Command, argv []string) {
 				r.Reporter.Errorf("%s", err)
 				os.Exit(1)
 			}
-			err = output.Print(f)
+			err = output.Print(os.Stdout, f)
 			if err != nil {
 				r.Reporter.Errorf("%s", err)
 				os.Exit(1)
@@ -106,7 +106,7 @@ func run(cmd *cobra.Command, argv []string) {
 				r.Reporter.Errorf("%s", err)
 				os.Exit(1)
 			}
-			err = output.Print(f)
+			err = output.Print(os.Stdout, f)
 			if err != nil {
 				r.Reporter.Errorf("%s", err)
 				os.Exit(1)
@@ -98,7 +98,7 @@ func run(_ *cobra.Command, _ []string) {
 		os.Exit(0)
 	}
 	if output.HasFlag() {
-		err = output.Print(accountRoles)
+		err = output.Print(os.Stdout, accountRoles)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -57,7 +57,7 @@ func run(_ *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(clusters)
+		err = output.Print(os.Stdout, clusters)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
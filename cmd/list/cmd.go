@@ -37,6 +37,7 @@ import (
 	"github.com/openshift/rosa/cmd/list/userroles"
 	"github.com/openshift/rosa/cmd/list/version"
 	"github.com/openshift/rosa/pkg/arguments"
+	"github.com/openshift/rosa/pkg/ocm"
 )
 
 var Cmd = &cobra.Command{
@@ -66,6 +67,7 @@ func init() {
 	flags := Cmd.PersistentFlags()
 	arguments.AddProfileFlag(flags)
 	arguments.AddRegionFlag(flags)
+	Cmd.RegisterFlagCompletionFunc("region", ocm.RegionCompletion)
 
 	globallyAvailableCommands := []*cobra.Command{
 		accountroles.Cmd, userroles.Cmd,
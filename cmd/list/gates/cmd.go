@@ -174,7 +174,7 @@ func run(cmd *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(versionGates)
+		err = output.Print(os.Stdout, versionGates)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -65,7 +65,7 @@ func run(_ *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(idps)
+		err = output.Print(os.Stdout, idps)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -66,7 +66,7 @@ func run(_ *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(ingresses)
+		err = output.Print(os.Stdout, ingresses)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+	errors "github.com/zgalor/weberr"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// clusterConflictingFlags are the flags that select instance types that aren't related to the
+// region of the cluster.
+var clusterConflictingFlags = []string{"all", "region-prefix", "diff-region", "stream", "jsonl"}
+
+func validateClusterFlag(flags *pflag.FlagSet, offline string) error {
+	if !flags.Changed("cluster") {
+		return nil
+	}
+	_, err := ocm.GetClusterKey()
+	if err != nil {
+		return err
+	}
+	if offline != "" {
+		return fmt.Errorf("The '--cluster' flag can't be used in offline mode")
+	}
+	for _, name := range clusterConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--cluster' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// fetchCluster loads the cluster given with the '--cluster' flag. A cluster that doesn't exist is
+// a usage error, as it is most likely a typo. The cluster is searched in the AWS account of the role
+// given with '--role-arn', or else in the one of the AWS credentials, which is found without any
+// region as the region isn't known until the cluster is loaded.
+func fetchCluster(r *rosa.Runtime) (*cmv1.Cluster, error) {
+	clusterKey, err := ocm.GetClusterKey()
+	if err != nil {
+		return nil, rosa.UsageError(err)
+	}
+	if r.Creator == nil && args.roleARN != "" {
+		r.Creator, err = roleCreator(args.roleARN)
+		if err != nil {
+			return nil, rosa.UsageError(err)
+		}
+	}
+	if r.Creator == nil {
+		var awsClient aws.Client
+		awsClient, err = regionAWSClient(r, aws.DefaultRegion)
+		if err != nil {
+			return nil, err
+		}
+		r.Creator, err = awsClient.GetCreator()
+		if err != nil {
+			return nil, rosa.AuthError(fmt.Errorf("Failed to get AWS creator: %v", err))
+		}
+	}
+	r.Reporter.Debugf("Loading cluster '%s'", clusterKey)
+	cluster, err := r.OCMClient.GetCluster(clusterKey, r.Creator)
+	if errors.GetType(err) == errors.NotFound {
+		return nil, rosa.UsageError(err)
+	}
+	if err != nil {
+		return nil, rosa.UpstreamError(fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err))
+	}
+	return cluster, nil
+}
+
+// roleCreator returns the creator of the clusters of the AWS account of the role, without making any
+// request to AWS.
+func roleCreator(roleARN string) (*aws.Creator, error) {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid role ARN '%s': %v", roleARN, err)
+	}
+	return &aws.Creator{
+		ARN:       roleARN,
+		AccountID: parsed.AccountID,
+		IsSTS:     true,
+	}, nil
+}
+
+// clusterRegion returns the region of the cluster, failing if a different one was given with the
+// '--region' flag.
+func clusterRegion(cluster *cmv1.Cluster, region string) (string, error) {
+	if region != "" && region != cluster.Region().ID() {
+		return "", fmt.Errorf("Cluster '%s' is in region '%s', it doesn't match the region '%s' given with "+
+			"'--region'", cluster.Name(), cluster.Region().ID(), region)
+	}
+	return cluster.Region().ID(), nil
+}
+
+// filterForCluster keeps the machine types that can be used for a new machine pool of the cluster.
+// The accelerated computing ones also need quota for the default number of nodes of the cluster,
+// which is larger when it is multi-AZ.
+func filterForCluster(machineTypes ocm.MachineTypeList, cluster *cmv1.Cluster) ocm.MachineTypeList {
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return machineType.HasQuota(cluster.MultiAZ())
+	})
+}
@@ -0,0 +1,186 @@
+package instancetypes
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Cluster", func() {
+	buildCluster := func(multiAZ bool) *cmv1.Cluster {
+		cluster, err := cmv1.NewCluster().
+			Name("mycluster").
+			Region(cmv1.NewCloudRegion().ID("us-east-1")).
+			MultiAZ(multiAZ).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		return cluster
+	}
+
+	Describe("validateClusterFlag", func() {
+		newFlags := func(argv ...string) *pflag.FlagSet {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("cluster", "", "")
+			flags.Bool("all", false, "")
+			flags.String("diff-region", "", "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			return flags
+		}
+
+		AfterEach(func() {
+			ocm.SetClusterKey("")
+		})
+
+		It("accepts the flag alone", func() {
+			ocm.SetClusterKey("mycluster")
+			Expect(validateClusterFlag(newFlags("--cluster", "mycluster"), "")).To(Succeed())
+		})
+
+		It("rejects an invalid cluster key", func() {
+			ocm.SetClusterKey("my cluster")
+			err := validateClusterFlag(newFlags("--cluster", "my cluster"), "")
+			Expect(err).To(MatchError(ContainSubstring("Cluster name, identifier or external identifier 'my cluster'")))
+		})
+
+		It("rejects the flags that aren't related to the region of the cluster", func() {
+			ocm.SetClusterKey("mycluster")
+			err := validateClusterFlag(newFlags("--cluster", "mycluster", "--diff-region", "eu-west-1"), "")
+			Expect(err).To(MatchError("The '--cluster' flag can't be used together with '--diff-region'"))
+		})
+
+		It("rejects offline mode", func() {
+			ocm.SetClusterKey("mycluster")
+			err := validateClusterFlag(newFlags("--cluster", "mycluster"), "cassettes")
+			Expect(err).To(MatchError("The '--cluster' flag can't be used in offline mode"))
+		})
+	})
+
+	Describe("clusterRegion", func() {
+		It("uses the region of the cluster", func() {
+			region, err := clusterRegion(buildCluster(false), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(region).To(Equal("us-east-1"))
+		})
+
+		It("accepts the same region given with '--region'", func() {
+			region, err := clusterRegion(buildCluster(false), "us-east-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(region).To(Equal("us-east-1"))
+		})
+
+		It("rejects a different region given with '--region'", func() {
+			_, err := clusterRegion(buildCluster(false), "eu-west-1")
+			Expect(err).To(MatchError("Cluster 'mycluster' is in region 'us-east-1', it doesn't match the region " +
+				"'eu-west-1' given with '--region'"))
+		})
+	})
+
+	It("keeps the instance types with quota for the default nodes of the cluster", func() {
+		machineTypes := ocm.MachineTypeList{
+			buildGPUMachineType("g4dn.xlarge", "t4-gpu-4"),
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		}
+		machineTypes.UpdateAvailableQuota(buildQuotaCosts("t4-gpu-4", 10, 7))
+
+		filtered := filterForCluster(machineTypes, buildCluster(false))
+		Expect(filtered.IDs()).To(Equal([]string{"g4dn.xlarge", "m5.xlarge"}))
+
+		filtered = filterForCluster(machineTypes, buildCluster(true))
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge"}))
+	})
+
+	Describe("fetchCluster", func() {
+		var apiServer *ghttp.Server
+		var r *rosa.Runtime
+
+		BeforeEach(func() {
+			apiServer = MakeTCPServer()
+			client, err := ocm.NewClient().
+				Logger(logging.NewLogger()).
+				Config(&config.Config{
+					URL:         apiServer.URL(),
+					AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				}).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			r = &rosa.Runtime{
+				Reporter:  reporter.CreateReporterOrExit(),
+				Logger:    logging.NewLogger(),
+				OCMClient: client,
+				Creator:   &aws.Creator{AccountID: "123456789012"},
+			}
+			ocm.SetClusterKey("mycluster")
+		})
+
+		AfterEach(func() {
+			ocm.SetClusterKey("")
+			Expect(r.OCMClient.Close()).To(Succeed())
+			apiServer.Close()
+		})
+
+		It("loads the cluster", func() {
+			apiServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "ClusterList",
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [{"kind": "Cluster", "id": "123", "name": "mycluster", "region": {"id": "us-east-1"}}]
+				}`),
+			))
+			cluster, err := fetchCluster(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Region().ID()).To(Equal("us-east-1"))
+		})
+
+		It("searches the cluster in the account of the role without an AWS client", func() {
+			r.Creator = nil
+			args.roleARN = "arn:aws:iam::210987654321:role/ManagedOpenShift-Installer-Role"
+			defer func() {
+				args.roleARN = ""
+			}()
+			apiServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.URL.Query().Get("search")).To(ContainSubstring(":210987654321:"))
+				},
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "ClusterList",
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [{"kind": "Cluster", "id": "123", "name": "mycluster", "region": {"id": "eu-west-1"}}]
+				}`),
+			))
+			cluster, err := fetchCluster(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Region().ID()).To(Equal("eu-west-1"))
+			Expect(r.AWSClient).To(BeNil())
+			Expect(r.Creator.AccountID).To(Equal("210987654321"))
+		})
+
+		It("fails with a usage error when the cluster doesn't exist", func() {
+			apiServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "page": 1, "size": 0, "total": 0, "items": []}`),
+			))
+			_, err := fetchCluster(r)
+			Expect(err).To(MatchError("There is no cluster with identifier or name 'mycluster'"))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+		})
+	})
+})
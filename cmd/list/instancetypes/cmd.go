@@ -17,93 +17,2005 @@ limitations under the License.
 package instancetypes
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net/http"
 	"os"
-	"text/tabwriter"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gitlab.com/c0b/go-ordered-json"
 
+	"github.com/openshift/rosa/pkg/arguments"
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/aws/credentialsfile"
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/helper"
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/interactive"
+	"github.com/openshift/rosa/pkg/interactive/confirm"
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
 	"github.com/openshift/rosa/pkg/output"
 	"github.com/openshift/rosa/pkg/rosa"
 )
 
+var args struct {
+	sort               string
+	reverse            bool
+	columns            []string
+	minCPU             int
+	minMemory          string
+	categories         []string
+	architecture       string
+	memoryUnit         string
+	roleARN            string
+	externalID         string
+	hasQuota           bool
+	all                bool
+	quiet              bool
+	zones              []string
+	stream             bool
+	jsonl              bool
+	debugTiming        bool
+	regionPrefix       string
+	exclude            []string
+	family             string
+	count              bool
+	maxResults         int
+	head               int
+	tail               int
+	filterExec         string
+	filterExecTimeout  time.Duration
+	noHeaders          bool
+	gpu                bool
+	strict             bool
+	showQuota          bool
+	showDeprecated     bool
+	showMaxPods        bool
+	wide               bool
+	dryRun             bool
+	explain            bool
+	role               string
+	diffRegion         string
+	validateQuota      string
+	raw                bool
+	offline            string
+	priceTier          string
+	supportsIPv6       bool
+	refreshCache       bool
+	cacheTTL           time.Duration
+	minGeneration      int
+	includeUnparseable bool
+	page               int
+	size               int
+	groupBy            string
+	recommend          bool
+	cpu                int
+	memory             string
+	top                int
+	precision          int
+	thousandsSeparator string
+	decimalSeparator   string
+	concurrency        int
+	includeUnavailable bool
+	summary            bool
+	watch              bool
+	interval           time.Duration
+	saveCassette       string
+	ensureMultiAZ      bool
+	minAZCount         int
+}
+
+var memoryUnits = []string{"iec", "si"}
+
+var sortKeys = []string{"id", "cpu", "memory", "category"}
+
 var Cmd = &cobra.Command{
 	Use:     "instance-types",
 	Aliases: []string{"instancetypes"},
 	Short:   "List Instance types",
-	Long:    "List Instance types that are available for use with ROSA.",
-	Example: `  # List all instance types
-  rosa list instance-types`,
-	Run: run,
+	Long: fmt.Sprintf(`List Instance types that are available for use with ROSA in the selected AWS region.
+
+Exit codes:
+  %d  The instance types were listed, or there were none to list and '--quiet' was given.
+  %d  Unexpected error.
+  %d  Invalid flags or arguments.
+  %d  Not logged in to OCM, or the OCM or AWS credentials were rejected.
+  %d  A request to OCM or AWS failed or timed out.
+  %d  The account doesn't have the quota asked with '--validate-quota'.
+  %d  There were no instance types to list.`,
+		rosa.ExitSuccess, rosa.ExitUnexpected, rosa.ExitUsage, rosa.ExitAuth, rosa.ExitUpstream, rosa.ExitQuota,
+		rosa.ExitNoResults),
+	Example: `  # List the instance types available in the current AWS region
+  rosa list instance-types
+
+  # List the instance types available in a region using an STS role
+  rosa list instance-types --region us-east-2 \
+  --role-arn arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role
+
+  # List the instance types available using the credentials of a named AWS profile
+  rosa list instance-types --profile dev
+
+  # List the instance types available to a role, assuming it with the credentials of a named AWS
+  # profile for the requests sent to AWS
+  rosa list instance-types --region us-east-2 --profile dev \
+  --role-arn arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role
+
+  # List the instance types available to a role, assuming it with the temporary credentials written
+  # to a file for the requests sent to AWS
+  rosa list instance-types --region us-east-2 --credentials-file /run/secrets/aws-credentials \
+  --role-arn arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role
+
+  # List the instance types available assuming a role whose trust policy requires an external ID
+  rosa list instance-types --role-arn arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role \
+  --external-id 8f4a2c1e
+
+  # List the instance types offered in the zones of a canned list that belong to the region
+  rosa list instance-types --region us-east-2 --availability-zones us-east-2a,us-east-1b
+
+  # List the instance types offered in every availability zone of a region, and the zones of each one
+  rosa list instance-types --region us-east-2 --availability-zones all
+
+  # List the instance types offered in at least three availability zones, for a multi-AZ cluster
+  rosa list instance-types --region us-east-2 --ensure-multi-az
+
+  # Show how many pods a node of each instance type can run, for capacity planning
+  rosa list instance-types --region us-east-2 --show-max-pods
+
+  # List the instance types available in every region
+  rosa list instance-types --region all
+
+  # List the instance types and write a summary line to stderr, for example to paste in a ticket
+  rosa list instance-types --region us-east-1 --summary
+
+  # List the instance types of every region fetching eight regions at the same time
+  rosa list instance-types --region all --concurrency 8
+
+  # List also the instance types without enough quota, and why
+  rosa list instance-types --has-quota=false
+
+  # List the instance types available in the only region starting with 'eu-west-3'
+  rosa list instance-types --region-prefix eu-west-3
+
+  # List all the instance types supported by ROSA in any region
+  rosa list instance-types --all
+
+  # List instance types with the largest amount of memory first
+  rosa list instance-types --sort memory --reverse
+
+  # List the 5 instance types with the largest amount of memory
+  rosa list instance-types --sort memory --reverse --max-results 5
+
+  # List the three instance types with the most CPU cores
+  rosa list instance-types --sort cpu --tail 3
+
+  # List only the instance types allowed by a policy script that filters their JSON list
+  rosa list instance-types --region us-east-1 --filter-exec ./allowed-instance-types.sh
+
+  # List only the ID, CPU cores and memory of the instance types
+  rosa list instance-types --columns id,cpu,memory
+
+  # Print the requests to OCM and AWS that would be made, without making them
+  rosa list instance-types --region us-east-1 --role-arn arn:aws:iam::123456789012:role/Installer --dry-run
+
+  # List the instance types replaying the OCM responses recorded in a cassette directory
+  rosa list instance-types --all --offline ./cassettes
+
+  # List the instance types with more columns, including their family and GPUs
+  rosa list instance-types --wide
+
+  # Print the ID and the number of CPU cores of each instance type with a Go template
+  rosa list instance-types --template '{{.id}} {{.cpu.value}}'
+
+  # Compare the instance types available in two regions
+  rosa list instance-types --region us-east-1 --diff-region eu-west-1
+
+  # Count the instance types of each category, with the range of their CPU cores and memory
+  rosa list instance-types --group-by category
+
+  # List the instance types that can be used for a new machine pool of a cluster
+  rosa list instance-types --cluster mycluster
+
+  # Check that the account has quota for 4 instances of 'g4dn.xlarge', failing when it hasn't
+  rosa list instance-types --region us-east-1 --validate-quota g4dn.xlarge:4
+
+  # Print the response of OCM for the instance types of a region, without filtering it
+  rosa list instance-types --region us-east-1 --raw
+
+  # Print the fields of the JSON output, to write 'jq' queries
+  rosa list instance-types --explain
+
+  # List the instance types as comma separated values, with the memory in bytes
+  rosa list instance-types --columns id,name,cpu,memory -o csv
+
+  # Save the instance types of a region as JSON, keeping the warnings in the terminal
+  rosa list instance-types --region us-east-1 -o json --output-file instance-types.json
+
+  # Print the table of the instance types of a region and also save them as JSON
+  rosa list instance-types --region us-east-1 --format json --output-file instance-types.json
+
+  # List the IDs of the instance types without the header, for use in scripts
+  rosa list instance-types --columns id --no-headers
+
+  # List instance types with at least 16 CPU cores and 64 GiB of memory
+  rosa list instance-types --min-cpu 16 --min-memory 64Gi
+
+  # List only memory optimized instance types
+  rosa list instance-types --category memory_optimized
+
+  # List only the instance types of the 'm5' family
+  rosa list instance-types --family m5 --columns id,family,cpu,memory
+
+  # List only the instance types with GPUs or other hardware accelerators
+  rosa list instance-types --gpu
+
+  # List the instance types with the memory formatted as in most of Europe, for example '15,63 GiB'
+  rosa list instance-types --precision 2 --thousands-separator . --decimal-separator ,
+
+  # Recommend the available instance type that best fits 8 CPU cores and 32 GiB of memory
+  rosa list instance-types --recommend --cpu 8 --memory 32Gi
+
+  # Recommend the three best fitting instance types with GPUs
+  rosa list instance-types --recommend --cpu 16 --gpu --top 3
+
+  # List only arm64 instance types
+  rosa list instance-types --architecture arm64
+
+  # List the instance types except the 'm5' family and 'c5.xlarge'
+  rosa list instance-types --exclude 'm5.*' --exclude c5.xlarge
+
+  # Print how many memory optimized instance types are available
+  rosa list instance-types --category memory_optimized --count
+
+  # Check every five minutes if the GPU instance types become available after a quota increase
+  rosa list instance-types --gpu --include-unavailable --watch --interval 5m
+
+  # Print all the instance types as they are fetched, one JSON object per line
+  rosa list instance-types --all --jsonl
+
+  # List the instance types offered in any of the given availability zones, and in which ones
+  rosa list instance-types --region us-east-1 --availability-zones us-east-1a,us-east-1b`,
+	PreRunE: preRunE,
+	Run:     run,
 }
 
 func init() {
-	output.AddFlag(Cmd)
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.sort,
+		"sort",
+		"id",
+		fmt.Sprintf("Sort the instance types by the given key. Allowed keys are %s", sortKeys),
+	)
+	Cmd.RegisterFlagCompletionFunc("sort", sortCompletion)
+	flags.BoolVar(
+		&args.reverse,
+		"reverse",
+		false,
+		"Reverse the sort order.",
+	)
+	flags.StringSliceVar(
+		&args.columns,
+		"columns",
+		defaultColumns,
+		fmt.Sprintf("Columns to display in the table. Allowed columns are %s", columnNames()),
+	)
+	Cmd.RegisterFlagCompletionFunc("columns", columnsCompletion)
+	flags.BoolVarP(
+		&args.wide,
+		"wide",
+		"w",
+		false,
+		fmt.Sprintf("Display more columns in the table: %s. It can't be used together with '--columns'.",
+			wideColumns),
+	)
+	flags.StringVar(
+		&args.groupBy,
+		"group-by",
+		"",
+		fmt.Sprintf("Display the number of instance types of each group, with the range of their CPU cores "+
+			"and memory, instead of the instance types. Allowed values are %s.", groupKeys),
+	)
+	flags.BoolVar(
+		&args.recommend,
+		"recommend",
+		false,
+		"Display only the available instance type that satisfies '--cpu' and '--memory' with the smallest "+
+			"over-provisioning. The other filters, like '--gpu' or '--category', are also applied.",
+	)
+	flags.IntVar(
+		&args.cpu,
+		"cpu",
+		0,
+		"Number of CPU cores required by '--recommend'.",
+	)
+	flags.StringVar(
+		&args.memory,
+		"memory",
+		"",
+		"Amount of memory required by '--recommend', for example '32Gi'.",
+	)
+	flags.IntVar(
+		&args.top,
+		"top",
+		1,
+		"Number of instance types displayed by '--recommend', ranked from the best fit.",
+	)
+	flags.IntVar(
+		&args.concurrency,
+		"concurrency",
+		defaultConcurrency,
+		"Number of regions whose instance types are fetched at the same time with '--region all'. The "+
+			"table of each region is displayed as soon as it is fetched.",
+	)
+	flags.IntVar(
+		&args.minCPU,
+		"min-cpu",
+		0,
+		"List only instance types with at least this number of CPU cores.",
+	)
+	flags.StringVar(
+		&args.minMemory,
+		"min-memory",
+		"",
+		"List only instance types with at least this amount of memory, for example '64Gi' or '131072Mi'.",
+	)
+	flags.StringSliceVar(
+		&args.categories,
+		"category",
+		nil,
+		"List only instance types of the given category. Can be repeated to list several categories.",
+	)
+	flags.StringVar(
+		&args.family,
+		"family",
+		"",
+		"List only instance types whose family starts with this value, ignoring case. The family is the "+
+			"part of the instance type before the size, for example 'm5' for 'm5.xlarge'.",
+	)
+	flags.IntVar(
+		&args.minGeneration,
+		"min-generation",
+		0,
+		"List only instance types whose generation is at least this one, for example 6 to keep 'm6i' and "+
+			"'c7g' but not 'm5'. The generation is the number in the family name.",
+	)
+	flags.BoolVar(
+		&args.includeUnparseable,
+		"include-unparseable",
+		false,
+		"Keep the instance types whose family doesn't encode a generation, like 'u-6tb1', when "+
+			"'--min-generation' is given.",
+	)
+	flags.BoolVar(
+		&args.gpu,
+		"gpu",
+		false,
+		"List only the accelerated computing instance types, which have GPUs or other hardware "+
+			"accelerators. When set to false these instance types are excluded.",
+	)
+	flags.StringVar(
+		&args.architecture,
+		"architecture",
+		"",
+		fmt.Sprintf("List only instance types with the given CPU architecture. Allowed values are %s",
+			ocm.Architectures),
+	)
+	Cmd.RegisterFlagCompletionFunc("architecture", architectureCompletion)
+	flags.StringArrayVar(
+		&args.exclude,
+		"exclude",
+		nil,
+		"Don't list the instance types with this ID, or with an ID matching this glob pattern, "+
+			"for example 'm5.*'. Can be repeated to exclude several instance types.",
+	)
+	flags.StringVar(
+		&args.memoryUnit,
+		"memory-unit",
+		"iec",
+		fmt.Sprintf("Units used to display the memory of the instance types, binary (GiB) or decimal (GB). "+
+			"Allowed values are %s", memoryUnits),
+	)
+	Cmd.RegisterFlagCompletionFunc("memory-unit", memoryUnitCompletion)
+	flags.IntVar(
+		&args.precision,
+		"precision",
+		1,
+		fmt.Sprintf("Number of decimals used to display the memory of the instance types, up to %d.",
+			maxPrecision),
+	)
+	flags.StringVar(
+		&args.thousandsSeparator,
+		"thousands-separator",
+		"",
+		"Character used to group the thousands of the numbers in the table, for example ',' or '.'. "+
+			"By default the digits aren't grouped.",
+	)
+	flags.StringVar(
+		&args.decimalSeparator,
+		"decimal-separator",
+		".",
+		"Character used to separate the decimals of the numbers in the table, for example ','.",
+	)
+	flags.StringVar(
+		&args.roleARN,
+		"role-arn",
+		"",
+		"The Amazon Resource Name of the role that the API will assume to fetch available instance types. "+
+			"If not set, the access keys of the current AWS user are used. Together with '--profile', the "+
+			"credentials of the profile assume the role for the requests sent to AWS, like the one that "+
+			"lists the availability zones.",
+	)
+	credentialsfile.AddFlag(flags)
+	flags.StringVar(
+		&args.externalID,
+		"external-id",
+		"",
+		"An optional unique identifier that might be required when you assume a role in another account. "+
+			"Only allowed together with '--role-arn'.",
+	)
+	flags.BoolVar(
+		&args.hasQuota,
+		"has-quota",
+		true,
+		"List only instance types with enough quota to create a cluster. When set to false the instance "+
+			"types without enough quota are listed too, with the reason why they aren't available.",
+	)
+	flags.BoolVar(
+		&args.includeUnavailable,
+		"include-unavailable",
+		false,
+		"List also the instance types that aren't available, with the AVAILABLE and REASON columns. In "+
+			"JSON and YAML each instance type gets the 'available' and 'unavailable_reason' fields.",
+	)
+	flags.BoolVar(
+		&args.summary,
+		"summary",
+		false,
+		"Write a line to the standard error stream after the listing with the region, the number of "+
+			"instance types shown and filtered out, and the elapsed time. Nothing is sent anywhere.",
+	)
+	flags.BoolVar(
+		&args.watch,
+		"watch",
+		false,
+		"List the instance types again every '--interval' until Ctrl-C is pressed, highlighting the rows "+
+			"that changed since the previous poll. Only allowed with the table output.",
+	)
+	flags.DurationVar(
+		&args.interval,
+		"interval",
+		defaultWatchInterval,
+		fmt.Sprintf("Time between two polls of '--watch', for example '30s' or '5m'. It must be at least %s.",
+			minWatchInterval),
+	)
+	flags.BoolVar(
+		&args.showQuota,
+		"show-quota",
+		false,
+		"Add a QUOTA column with the number of instances the account has quota for. Only the "+
+			"accelerated computing instance types have a quota, '-' is shown for the rest.",
+	)
+	flags.BoolVar(
+		&args.showMaxPods,
+		"show-max-pods",
+		false,
+		"Add a MAX_PODS column with the number of pods that a node can run with OVN-Kubernetes, the "+
+			"default network of ROSA. The pods don't use the addresses of the network interfaces of the "+
+			"instance, so it is the pod limit of the kubelet.",
+	)
+	flags.BoolVar(
+		&args.showDeprecated,
+		"show-deprecated",
+		false,
+		"Include the instance types of the previous generation AWS families, like 'm4' or 'r3', which "+
+			"are hidden by default, and add a DEPRECATED column telling which ones they are.",
+	)
+	flags.BoolVar(
+		&args.all,
+		"all",
+		false,
+		"List all the instance types supported by ROSA, without checking their availability "+
+			"in the region. (No other arguments accepted.)",
+	)
+	flags.BoolVar(
+		&args.quiet,
+		"quiet",
+		false,
+		"Don't warn nor fail when there are no instance types to list, just print an empty result.",
+	)
+	flags.StringSliceVar(
+		&args.zones,
+		"availability-zones",
+		nil,
+		fmt.Sprintf("List the instance types offered in any of the given availability zones of the region, "+
+			"showing the zones each one is offered in. Use '%s' for every zone of the region.", allZonesValue),
+	)
+	Cmd.RegisterFlagCompletionFunc("availability-zones", availabilityZonesCompletion)
+	flags.BoolVar(
+		&args.strict,
+		"strict",
+		false,
+		"Fail if any of the availability zones doesn't belong to the region, instead of ignoring it "+
+			"with a warning.",
+	)
+	flags.BoolVar(
+		&args.ensureMultiAZ,
+		"ensure-multi-az",
+		false,
+		"List only the instance types offered in enough availability zones of the region for a multi-AZ "+
+			"cluster. Checks every zone of the region unless '--availability-zones' is given.",
+	)
+	flags.IntVar(
+		&args.minAZCount,
+		"min-az-count",
+		defaultMinAZCount,
+		"Number of availability zones that an instance type must be offered in with '--ensure-multi-az'.",
+	)
+	flags.BoolVar(
+		&args.noHeaders,
+		"no-headers",
+		false,
+		"Don't print the header row of the table. Ignored when '--output' is used.",
+	)
+	flags.IntVar(
+		&args.maxResults,
+		"max-results",
+		0,
+		"Maximum number of instance types to list, after filtering and sorting them. "+
+			"Zero means no limit.",
+	)
+	flags.IntVar(
+		&args.head,
+		"head",
+		0,
+		"List only the first N instance types, after filtering and sorting them.",
+	)
+	flags.IntVar(
+		&args.tail,
+		"tail",
+		0,
+		"List only the last N instance types, after filtering and sorting them.",
+	)
+	flags.StringVar(
+		&args.filterExec,
+		"filter-exec",
+		"",
+		"Program that filters the instance types. It reads their JSON list from its standard input and "+
+			"writes to its standard output the JSON list of the ones to keep.",
+	)
+	flags.DurationVar(
+		&args.filterExecTimeout,
+		"filter-exec-timeout",
+		defaultFilterExecTimeout,
+		"How long the program given with '--filter-exec' can run before it is stopped.",
+	)
+	flags.BoolVar(
+		&args.count,
+		"count",
+		false,
+		"Print only the number of instance types matching the other flags, instead of listing them. "+
+			"A count of zero isn't an error.",
+	)
+	flags.BoolVar(
+		&args.stream,
+		"stream",
+		false,
+		"Print the instance types as they are fetched instead of waiting for the complete list. "+
+			"The results aren't sorted. Only allowed together with '--all'.",
+	)
+	flags.BoolVar(
+		&args.jsonl,
+		"jsonl",
+		false,
+		"Print the instance types as they are fetched, one JSON object per line. "+
+			"Only allowed together with '--all'.",
+	)
+	flags.StringVar(
+		&args.regionPrefix,
+		"region-prefix",
+		"",
+		"Select the region by the beginning of its name, for example 'eu-west'. The prefix must match "+
+			"exactly one region, unless in interactive mode, where it narrows the regions to choose from.",
+	)
+	flags.BoolVar(
+		&args.debugTiming,
+		"debug-timing",
+		false,
+		"Print to the standard error how long it took to resolve the region, describe the availability "+
+			"zones and fetch the instance types.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"Print the requests to OCM and AWS that would be made to list the instance types, with the "+
+			"resolved region, availability zones and role ARN, and exit without making any of them.",
+	)
+	flags.BoolVar(
+		&args.supportsIPv6,
+		"supports-ipv6",
+		false,
+		"List only the instance types that support IPv6 and dual-stack networking. It is ignored with a "+
+			"warning when the network capabilities aren't available. Add the 'network' column to see them, "+
+			"it shows '-' when they aren't known.",
+	)
+	flags.StringVar(
+		&args.priceTier,
+		"price-tier",
+		"",
+		fmt.Sprintf("List only the instance types whose hourly on-demand price is in this tier. "+
+			"Allowed values are %s. It is ignored with a warning when the prices aren't available.",
+			strings.Join(priceTierDescriptions(), ", ")),
+	)
+	Cmd.RegisterFlagCompletionFunc("price-tier", priceTierCompletion)
+	flags.StringVar(
+		&args.offline,
+		"offline",
+		"",
+		"Replay the OCM responses recorded in the JSON cassettes of this directory instead of "+
+			"connecting to OCM, failing for any request without a recorded response. It can also be set "+
+			"with the "+offlineEnv+" environment variable. Requires '--role-arn' or '--all'.",
+	)
+	flags.StringVar(
+		&args.diffRegion,
+		"diff-region",
+		"",
+		"Compare the instance types of the region with the ones of this region, adding a column for "+
+			"each region that tells if the instance type is available in it. With '-o json' the instance "+
+			"types only in each region and the common ones are printed in separate lists.",
+	)
+	flags.StringVar(
+		&args.validateQuota,
+		"validate-quota",
+		"",
+		"Check that the account has quota for a number of instances of an instance type in the region, "+
+			"for example 'g4dn.xlarge:4', printing the shortfall and exiting with code 5 when it hasn't.",
+	)
+	flags.BoolVar(
+		&args.raw,
+		"raw",
+		false,
+		"Print the bodies of the responses of OCM to the instance types query exactly as received, one "+
+			"per page, without filtering or formatting them. The query still uses '--region', "+
+			"'--role-arn' and '--external-id', or '--all'.",
+	)
+	if cassettesEnabled {
+		flags.StringVar(
+			&args.saveCassette,
+			"save-cassette",
+			"",
+			"Developer option that saves the requests sent to OCM and their responses to this file, as a "+
+				"cassette that '--offline' can replay. The credentials in the bodies are redacted.",
+		)
+		flags.MarkHidden("save-cassette")
+	}
+	flags.StringVar(
+		&args.role,
+		"role",
+		workerRole,
+		fmt.Sprintf("Node role to list the instance types for. Allowed values are %s. Only the '%s' "+
+			"role is supported, as OCM only returns the instance types of the worker nodes and selects "+
+			"the ones of the control plane and infra nodes itself. The other roles are rejected.",
+			nodeRoles, workerRole),
+	)
+	Cmd.RegisterFlagCompletionFunc("role", roleCompletion)
+	flags.BoolVar(
+		&args.refreshCache,
+		"refresh-cache",
+		false,
+		"Fetch the region lists again from OCM before each poll of '--watch', instead of reusing the "+
+			"ones cached by the previous poll. The cache only lives in memory while the command runs.",
+	)
+	flags.DurationVar(
+		&args.cacheTTL,
+		"cache-ttl",
+		ocm.DefaultRegionCacheTTL,
+		"How long the region lists cached in memory while the command runs are considered fresh "+
+			"before fetching them again from OCM, for example during a long '--watch'. Zero means that "+
+			"they never expire.",
+	)
+	flags.BoolVar(
+		&args.explain,
+		"explain",
+		false,
+		"Print the fields of the JSON output of each instance type and their types, without fetching "+
+			"the instance types.",
+	)
+	flags.IntVar(
+		&args.page,
+		"page",
+		1,
+		"Fetch only this page of instance types instead of all of them. Pages are numbered from 1.",
+	)
+	flags.IntVar(
+		&args.size,
+		"size",
+		100,
+		"Number of instance types in each page fetched with '--page'.",
+	)
+	interactive.AddFlag(flags)
+	output.AddFlagWithCSV(Cmd)
+	output.AddCompactFlag(Cmd)
+	output.AddTemplateFlags(Cmd)
+	output.AddTableFlags(Cmd)
+	output.AddOutputFileFlag(Cmd)
+	output.AddFileFormatFlag(Cmd)
+	ocm.AddOptionalClusterFlag(Cmd)
+	confirm.AddFlag(flags)
 }
 
-func run(cmd *cobra.Command, _ []string) {
-	r := rosa.NewRuntime().WithOCM()
-	defer r.Cleanup()
+func sortCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return sortKeys, cobra.ShellCompDirectiveDefault
+}
 
-	r.Reporter.Debugf("Fetching instance types")
+func columnsCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return columnNames(), cobra.ShellCompDirectiveDefault
+}
 
-	machineTypes, err := r.OCMClient.GetAvailableMachineTypes()
+func architectureCompletion(cmd *cobra.Command, args []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	return ocm.Architectures, cobra.ShellCompDirectiveDefault
+}
+
+func memoryUnitCompletion(cmd *cobra.Command, args []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	return memoryUnits, cobra.ShellCompDirectiveDefault
+}
+
+func roleCompletion(cmd *cobra.Command, args []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nodeRoles, cobra.ShellCompDirectiveDefault
+}
+
+func priceTierCompletion(cmd *cobra.Command, args []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	return priceTierNames(), cobra.ShellCompDirectiveDefault
+}
+
+// availabilityZonesCompletion completes the '--availability-zones' flag with the zones of the region
+// selected with the '--region' flag or the AWS configuration. Zones that were already given aren't
+// suggested again.
+func availabilityZonesCompletion(cmd *cobra.Command, args []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	awsClient, err := aws.NewClient().
+		Logger(logging.NewLogger()).
+		Region(arguments.GetRegion()).
+		Build()
+	if err != nil {
+		return []string{}, cobra.ShellCompDirectiveDefault
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ocm.CompletionTimeout)
+	defer cancel()
+	zones, err := awsClient.DescribeAvailabilityZones(ctx)
 	if err != nil {
-		r.Reporter.Errorf("Failed to fetch instance types: %v", err)
-		os.Exit(1)
+		return []string{}, cobra.ShellCompDirectiveDefault
+	}
+	if !strings.Contains(toComplete, ",") {
+		zones = append([]string{allZonesValue}, zones...)
+	}
+	return completeList(zones, toComplete), cobra.ShellCompDirectiveNoSpace
+}
+
+// completeList returns the completions for the last element of a comma separated list, keeping the
+// elements before it and leaving out the values that are already part of the list.
+func completeList(values []string, toComplete string) []string {
+	prefix := ""
+	given := []string{}
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		given = strings.Split(toComplete[:i], ",")
+	}
+	completions := []string{}
+	for _, value := range values {
+		if !helper.Contains(given, value) {
+			completions = append(completions, prefix+value)
+		}
+	}
+	return completions
+}
+
+// errNoMachineTypes is returned when there are no instance types to list. It is reported as a
+// warning instead of an error, but with its own exit code so that scripts can tell it apart from a
+// successful listing.
+var errNoMachineTypes = rosa.NoResultsError(errors.New(
+	"There are no machine types supported for your account. Contact Red Hat support."))
+
+// preRunE checks the flags that can be validated without making any request.
+func preRunE(cmd *cobra.Command, argv []string) error {
+	if args.roleARN != "" {
+		err := aws.ValidateRoleARN(args.roleARN)
+		if err != nil {
+			return rosa.UsageError(err)
+		}
+	}
+	return nil
+}
+
+func run(cmd *cobra.Command, argv []string) {
+	r := rosa.NewRuntime()
+
+	var err error
+	if args.watch {
+		err = runWatch(cmd, argv, r)
+	} else {
+		err = runE(cmd, argv, r)
+	}
+
+	// Exiting skips the deferred calls, so the connections are closed, and the responses recorded with
+	// '--save-cassette' saved, before it:
+	cleanupErr := r.CleanupOrError()
+	if err == nil {
+		err = cleanupErr
+	} else if cleanupErr != nil {
+		r.Reporter.Errorf("%s", cleanupErr)
+	}
+	if err == nil {
+		return
+	}
+	// Finding no instance types isn't a failure, so it is only a warning:
+	if errors.Is(err, errNoMachineTypes) {
+		r.Reporter.Warnf("%s", err)
+	} else {
+		r.Reporter.Errorf("%s", err)
+		hint := errorHint(err)
+		if hint != "" {
+			r.Reporter.Infof("%s", hint)
+		}
+	}
+	os.Exit(rosa.ExitCode(err))
+}
+
+// errorHint returns a suggestion of what to do when the command failed because the OCM or AWS
+// credentials were rejected, or an empty string for the rest of the errors.
+func errorHint(err error) string {
+	var apiErr *ocm.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusUnauthorized:
+			return "The OCM token has expired or isn't valid, run 'rosa login' to log in again"
+		case http.StatusForbidden:
+			return "The OCM account isn't allowed to list instance types, check its roles and organization " +
+				"with 'rosa whoami'"
+		}
+	}
+	var authErr *aws.AuthError
+	if errors.As(err, &authErr) {
+		return fmt.Sprintf("The AWS credentials were rejected with '%s', check them with 'rosa whoami --check'",
+			authErr.Code)
+	}
+	return ""
+}
+
+func runE(cmd *cobra.Command, _ []string, r *rosa.Runtime) (err error) {
+	err = validateAllFlag(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	if args.externalID != "" && args.roleARN == "" {
+		return rosa.UsageError(fmt.Errorf("The '--external-id' flag can only be used together with '--role-arn'"))
+	}
+	if !helper.Contains(sortKeys, args.sort) {
+		return rosa.UsageError(fmt.Errorf("Invalid sort key '%s'. Allowed keys are %s", args.sort, sortKeys))
+	}
+	names := args.columns
+	if args.wide {
+		if cmd.Flags().Changed("columns") {
+			return rosa.UsageError(fmt.Errorf("The '--wide' flag can't be used together with '--columns'"))
+		}
+		names = wideColumns
+	}
+	selectedColumns, err := selectColumns(names)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	minMemory, err := instancetypes.ParseMemory(args.minMemory)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateRecommendFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	recommendMemory, err := instancetypes.ParseMemory(args.memory)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateLimitFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	if args.minGeneration < 0 {
+		return rosa.UsageError(fmt.Errorf("Invalid minimum generation %d. It must be zero or greater",
+			args.minGeneration))
+	}
+	if !helper.Contains(memoryUnits, args.memoryUnit) {
+		return rosa.UsageError(fmt.Errorf("Invalid memory unit '%s'. Allowed values are %s", args.memoryUnit,
+			memoryUnits))
+	}
+	err = validateNumberFormat()
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	if args.architecture != "" && !helper.Contains(ocm.Architectures, args.architecture) {
+		return rosa.UsageError(fmt.Errorf("Invalid architecture '%s'. Allowed values are %s", args.architecture,
+			ocm.Architectures))
+	}
+	err = instancetypes.ValidateExcludePatterns(args.exclude)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateZonesFlag(args.zones)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateRole(args.role)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validatePriceTier(args.priceTier)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateDiffRegionFlag(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateQuotaFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateRawFlag(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateGroupByFlag(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateSummaryFlag(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateWatchFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateMultiAZFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateFilterExecFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = output.ValidateTemplate()
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = output.ValidateFileFormat()
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateFileFormatFlag(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+
+	err = validateStreamFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	paged, err := validatePageFlags(cmd.Flags())
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	offline := offlineDir()
+	err = validateOfflineFlags(cmd.Flags(), offline)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	err = validateClusterFlag(cmd.Flags(), offline)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	if args.includeUnavailable {
+		if cmd.Flags().Changed("has-quota") {
+			return rosa.UsageError(fmt.Errorf("The '--include-unavailable' flag can't be used together with " +
+				"'--has-quota'"))
+		}
+		// The instance types without quota are the ones that aren't available:
+		args.hasQuota = false
+	}
+	if args.concurrency < 1 {
+		return rosa.UsageError(fmt.Errorf("Invalid concurrency %d. It must be greater than zero", args.concurrency))
+	}
+	if args.cacheTTL < 0 {
+		return rosa.UsageError(fmt.Errorf("The value of the '--cache-ttl' flag can't be negative"))
+	}
+
+	csvOutput := output.Output() == output.CSV
+	if args.noHeaders && ((output.HasFlag() && !csvOutput) || args.jsonl) {
+		r.Reporter.Warnf("The '--no-headers' flag is ignored when the output isn't a table")
+	}
+	if output.Compact() && output.Output() != "json" {
+		r.Reporter.Warnf("The '--compact' flag is ignored when the output isn't JSON")
+	}
+
+	// Warnings and debug messages keep going to the terminal, only the results go to the file:
+	writer, closeOutput, err := output.OpenFile(r.Writer)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	r.Writer = writer
+	defer func() {
+		closeErr := closeOutput()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	summary := newListingSummary()
+	if args.summary {
+		defer func() {
+			if err == nil {
+				summary.write(os.Stderr)
+			}
+		}()
+	}
+
+	if args.explain {
+		return explain(r.Writer)
+	}
+	if args.dryRun {
+		return dryRun(cmd, r.Writer)
+	}
+
+	gpu := gpuFilter(cmd.Flags())
+
+	r.Offline = offline
+	r.SaveCassette = args.saveCassette
+	err = r.WithOCMOrError()
+	if err != nil {
+		return err
+	}
+	// The region list is needed more than once, for example for the display names of the regions. Each
+	// poll of '--watch' runs this again with the same client, so the cache is also cleared before each
+	// of them:
+	r.OCMClient.SetRegionCacheTTL(args.cacheTTL)
+	if args.refreshCache {
+		r.OCMClient.ClearRegionCache()
+	}
+
+	if args.stream || args.jsonl {
+		r.Reporter.Debugf("Streaming all instance types")
+		ctx, cancel := r.OperationContext()
+		defer cancel()
+		stream := func(fn func(page ocm.MachineTypeList) error) error {
+			return r.OCMClient.StreamAvailableMachineTypes(ctx, fn)
+		}
+		count, err := streamMachineTypes(r, r.Writer, stream, selectedColumns, minMemory, gpu, args.jsonl)
+		err = r.OperationError(ctx, err)
+		if err != nil {
+			return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", err))
+		}
+		if count == 0 && !args.quiet {
+			return errNoMachineTypes
+		}
+		return nil
+	}
+
+	timer := newPhaseTimer()
+	if args.debugTiming {
+		defer timer.report(r.Reporter)
+	}
+
+	var machineTypes ocm.MachineTypeList
+	var availabilityZones []string
+	if args.all && args.raw {
+		r.Reporter.Debugf("Fetching the raw response for all instance types")
+		ctx, cancel := r.OperationContext()
+		pages, err := r.OCMClient.GetRawMachineTypes(ctx)
+		err = r.OperationError(ctx, err)
+		cancel()
+		if err != nil {
+			return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", err))
+		}
+		return writeRaw(r.Writer, pages)
+	}
+	if args.all {
+		r.Reporter.Debugf("Fetching all instance types")
+		stop := timer.start("machine types")
+		stopSpinner := startSpinner(r)
+		ctx, cancel := r.OperationContext()
+		if paged {
+			var total int
+			machineTypes, total, err = instancetypes.ListPage(ctx, r.OCMClient, nil,
+				instancetypes.Filters{All: true}, args.page, args.size)
+			if err == nil {
+				defer printPageInfo(r, total, csvOutput)
+			}
+		} else {
+			machineTypes, err = instancetypes.List(ctx, r.OCMClient, nil, instancetypes.Filters{All: true})
+		}
+		err = r.OperationError(ctx, err)
+		cancel()
+		stopSpinner()
+		stop()
+		if err != nil {
+			return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", err))
+		}
+	} else {
+		err = arguments.ValidateProfile()
+		if err != nil {
+			return rosa.UsageError(err)
+		}
+
+		// With '--region all' the AWS client uses the region of the AWS environment, and the instance
+		// types of every region are listed after resolving the list of regions:
+		allRegions := arguments.GetRegion() == allRegionsValue
+		if !allRegions && cmd.Flags().Changed("concurrency") {
+			return rosa.UsageError(fmt.Errorf("The '--concurrency' flag can only be used together with "+
+				"'--region %s'", allRegionsValue))
+		}
+		if allRegions {
+			if paged {
+				return rosa.UsageError(fmt.Errorf(
+					"The '--page' and '--size' flags can't be used together with '--region all'"))
+			}
+			err = validateAllRegionsFlag(cmd.Flags())
+			if err != nil {
+				return rosa.UsageError(err)
+			}
+			err = cmd.Flags().Set("region", "")
+			if err != nil {
+				return err
+			}
+		}
+
+		// The '--region' flag is a persistent flag of the parent 'list' command, so it must not be
+		// registered again here. It takes precedence over the AWS environment and profile, and is used
+		// as the default answer when the region is selected interactively.
+		stop := timer.start("region resolution")
+		region, err := aws.GetRegion(arguments.GetRegion())
+		if err != nil {
+			return rosa.UsageError(fmt.Errorf("Error getting region: %w", err))
+		}
+		if region == "" && !interactive.Enabled() && !allRegions && args.regionPrefix == "" &&
+			!cmd.Flags().Changed("cluster") {
+			return rosa.UsageError(fmt.Errorf("Expected a valid AWS region. %s", aws.RegionSourcesHint))
+		}
+
+		// The region, the availability zones and the role of the cluster are used instead of asking
+		// for them, so the cluster is loaded before deciding if the AWS client is needed:
+		var cluster *cmv1.Cluster
+		if cmd.Flags().Changed("cluster") {
+			cluster, err = fetchCluster(r)
+			if err != nil {
+				return err
+			}
+			region, err = clusterRegion(cluster, arguments.GetRegion())
+			if err != nil {
+				return rosa.UsageError(err)
+			}
+			// The AWS client is created for the region of the cluster too:
+			err = cmd.Flags().Set("region", region)
+			if err != nil {
+				return err
+			}
+			if args.roleARN == "" {
+				args.roleARN = cluster.AWS().STS().RoleARN()
+				args.externalID = cluster.AWS().STS().ExternalID()
+			}
+		}
+
+		// The access keys of the AWS user are only needed when there is no role for OCM to assume, and
+		// in offline mode there are no AWS credentials. The polls of '--watch' keep the client of the
+		// first one, so all of them send the same access keys:
+		if offline == "" && args.roleARN == "" && r.AWSClient == nil {
+			if credentialsfile.Path() != "" {
+				return rosa.UsageError(fmt.Errorf("The '--credentials-file' flag can only be used together " +
+					"with '--role-arn', as OCM can't use temporary credentials without their session token"))
+			}
+			r.AWSClient, err = newAccessKeysClient(r, region)
+			if err != nil {
+				return err
+			}
+		}
+
+		ctx, cancel := r.OperationContext()
+		regionList, _, err := r.OCMClient.GetRegionList(ctx, false, args.roleARN, args.externalID, "",
+			r.AWSClient, false, false)
+		err = r.OperationError(ctx, err)
+		cancel()
+		if err != nil {
+			return rosa.UpstreamError(err)
+		}
+		if allRegions {
+			stop()
+			return runAllRegions(r, regionList, selectedColumns, minMemory, gpu, timer)
+		}
+		regionOptions := regionList
+		if args.regionPrefix != "" {
+			if cmd.Flags().Changed("region") {
+				return rosa.UsageError(fmt.Errorf("The '--region-prefix' flag can't be used together with '--region'"))
+			}
+			regionOptions = filterRegionsByPrefix(regionList, args.regionPrefix)
+			if !interactive.Enabled() {
+				region, err = uniqueRegion(regionOptions, args.regionPrefix)
+				if err != nil {
+					return rosa.UsageError(err)
+				}
+			} else if len(regionOptions) == 0 {
+				return rosa.UsageError(fmt.Errorf("No region starts with '%s'. Available regions are %s",
+					args.regionPrefix, regionList))
+			}
+		}
+		if interactive.Enabled() && cluster == nil {
+			if arguments.GetRegion() == "" {
+				if last := lastRegion(r, regionOptions); last != "" {
+					region = last
+				}
+			}
+			// The region list was cached, so this doesn't need another request to OCM:
+			ctx, cancel := r.OperationContext()
+			displayNames, err := r.OCMClient.GetRegionDisplayNames(ctx, false, args.roleARN, args.externalID, "",
+				r.AWSClient, false, false)
+			cancel()
+			if err != nil {
+				r.Reporter.Debugf("Showing the regions without their display names: %v", err)
+			}
+			// The time spent waiting for the answer isn't part of the resolution of the region:
+			stop()
+			option, err := interactive.GetOption(interactive.Input{
+				Question: "AWS region",
+				Help:     cmd.Flags().Lookup("region").Usage,
+				Options:  regionOptionLabels(regionOptions, displayNames),
+				Default:  regionOptionLabel(region, displayNames),
+				Required: true,
+			})
+			if err != nil {
+				return fmt.Errorf("Expected a valid AWS region: %s", err)
+			}
+			region = regionFromOption(option)
+			rememberRegion(r, region)
+		}
+		stop()
+		if !helper.Contains(regionList, region) {
+			return rosa.UsageError(fmt.Errorf("Region '%s' is not supported for this AWS account", region))
+		}
+		summary.region = region
+		if args.diffRegion != "" {
+			if !helper.Contains(regionList, args.diffRegion) {
+				return rosa.UsageError(fmt.Errorf("Region '%s' given with '--diff-region' is not supported "+
+					"for this AWS account", args.diffRegion))
+			}
+			return runDiffRegion(r, region, args.diffRegion, selectedColumns, minMemory, gpu, csvOutput)
+		}
+		if args.validateQuota != "" {
+			return runValidateQuota(r, region)
+		}
+		if args.raw {
+			r.Reporter.Debugf("Fetching the raw response for the instance types in region '%s'", region)
+			ctx, cancel := r.OperationContext()
+			pages, err := r.OCMClient.GetRawMachineTypesInRegion(ctx, region, args.roleARN, args.externalID,
+				r.AWSClient)
+			err = r.OperationError(ctx, err)
+			cancel()
+			if err != nil {
+				return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", err))
+			}
+			return writeRaw(r.Writer, pages)
+		}
+
+		if cluster != nil && len(args.zones) == 0 {
+			availabilityZones = cluster.Nodes().AvailabilityZones()
+		} else if offline == "" && (len(args.zones) > 0 || interactive.Enabled() || args.ensureMultiAZ) {
+			availabilityZones, err = resolveAvailabilityZones(r, cmd, region, multiAZZones(args.zones), timer)
+			if err != nil {
+				return err
+			}
+		}
+		err = checkMultiAZZones(region, availabilityZones)
+		if err != nil {
+			return err
+		}
+
+		stop = timer.start("machine types")
+		ctx, cancel = r.OperationContext()
+		if len(availabilityZones) > 0 {
+			r.Reporter.Debugf("Fetching instance types in availability zones %s", availabilityZones)
+		} else {
+			r.Reporter.Debugf("Fetching instance types in region '%s'", region)
+		}
+		stopSpinner := startSpinner(r)
+		filters := instancetypes.Filters{
+			Region:            region,
+			AvailabilityZones: availabilityZones,
+			HasQuota:          args.hasQuota,
+			RoleARN:           args.roleARN,
+			ExternalID:        args.externalID,
+		}
+		if paged {
+			var total int
+			machineTypes, total, err = instancetypes.ListPage(ctx, r.OCMClient, r.AWSClient, filters,
+				args.page, args.size)
+			if err == nil {
+				defer printPageInfo(r, total, csvOutput)
+			}
+		} else {
+			machineTypes, err = instancetypes.List(ctx, r.OCMClient, r.AWSClient, filters)
+		}
+		stopSpinner()
+		err = r.OperationError(ctx, err)
+		cancel()
+		stop()
+		if err != nil {
+			return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", err))
+		}
+		if cluster != nil && args.hasQuota {
+			machineTypes = filterForCluster(machineTypes, cluster)
+		}
+		if args.ensureMultiAZ {
+			machineTypes = filterMultiAZ(r, machineTypes, args.minAZCount)
+		}
 	}
 
+	summary.fetched = len(machineTypes)
 	if len(machineTypes) == 0 {
-		r.Reporter.Warnf("There are no machine types supported for your account. Contact Red Hat support.")
-		os.Exit(1)
+		if args.count {
+			return printCount(r.Writer, 0)
+		}
+		if !args.quiet {
+			return errNoMachineTypes
+		}
+		err = output.WriteFile([]*cmv1.MachineType{})
+		if err != nil {
+			return err
+		}
+		if csvOutput {
+			return writeCSV(r.Writer, selectedColumns, nil)
+		}
+		if output.HasFlag() {
+			return output.Print(r.Writer, []*cmv1.MachineType{})
+		}
+		return nil
 	}
 
-	if output.HasFlag() {
-		var instanceTypes []*cmv1.MachineType
-		for _, machine := range machineTypes {
-			instanceTypes = append(instanceTypes, machine.MachineType)
+	categories := args.categories
+	if interactive.Enabled() && len(categories) == 0 {
+		categories, err = interactive.GetMultipleOptions(interactive.Input{
+			Question: "Instance type categories",
+			Help:     cmd.Flags().Lookup("category").Usage,
+			Options:  instancetypes.Categories(machineTypes),
+		})
+		if err != nil {
+			return fmt.Errorf("Expected valid instance type categories: %s", err)
+		}
+	}
+	machineTypes, err = applyFilters(r, machineTypes, categories, minMemory, gpu)
+	if err != nil {
+		return err
+	}
+	machineTypes, err = applyPriceTier(r, machineTypes)
+	if err != nil {
+		return err
+	}
+	machineTypes = applyIPv6Filter(r, machineTypes)
+	machineTypes, err = applyFilterExec(r, machineTypes)
+	if err != nil {
+		return err
+	}
+	if args.count {
+		return printCount(r.Writer, len(machineTypes))
+	}
+	if args.groupBy != "" {
+		return printGroups(r.Writer, machineTypes, args.groupBy, csvOutput)
+	}
+	if args.recommend {
+		machineTypes, err = recommendMachineTypes(machineTypes, args.cpu, recommendMemory, args.top)
+		if err != nil {
+			return err
+		}
+	} else {
+		sortMachineTypes(machineTypes, args.sort, args.reverse)
+		if !confirmLargeListing(r.Reporter.IsTerminal(), len(machineTypes)) {
+			return nil
 		}
-		err = output.Print(instanceTypes)
+	}
+
+	// With the '--format' flag the file gets the same instance types as the standard output:
+	if output.FileFormat() != "" {
+		resource, err := jsonResource(limitResults(displayedMachineTypes(machineTypes)),
+			len(availabilityZones) > 0)
 		if err != nil {
-			r.Reporter.Errorf("%s", err)
-			os.Exit(1)
+			return err
+		}
+		err = output.WriteFile(resource)
+		if err != nil {
+			return err
 		}
-		os.Exit(0)
+	}
+	if output.HasFlag() && !csvOutput {
+		machineTypes = limitResults(displayedMachineTypes(machineTypes))
+		summary.shown = len(machineTypes)
+		resource, err := jsonResource(machineTypes, len(availabilityZones) > 0)
+		if err != nil {
+			return err
+		}
+		return output.Print(r.Writer, resource)
 	}
 
-	// Create the writer that will be used to print the tabulated results:
-	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(writer, "ID\tCATEGORY\tCPU_CORES\tMEMORY\t\n")
+	if interactive.Enabled() && !cmd.Flags().Changed("columns") && !args.wide {
+		selectedColumns, err = selectColumnsInteractively(cmd)
+		if err != nil {
+			return err
+		}
+	}
 
-	for _, machine := range machineTypes {
-		if !machine.Available {
+	summary.shown = len(limitResults(displayedMachineTypes(machineTypes)))
+	return printTable(r, r.Writer, selectedColumns, machineTypes, availabilityZones, gpu, csvOutput)
+}
+
+// printTable prints the machine types as a table, or as comma separated values, adding to the
+// selected columns the ones that explain the result: the availability zones, the GPUs and the
+// quota.
+func printTable(r *rosa.Runtime, w io.Writer, selectedColumns []column, machineTypes ocm.MachineTypeList,
+	availabilityZones []string, gpu *bool, csvOutput bool) error {
+	var extraColumns []string
+	if len(availabilityZones) > 0 {
+		extraColumns = append(extraColumns, zonesColumn)
+	}
+	if gpu != nil && *gpu {
+		extraColumns = append(extraColumns, gpuColumn)
+	}
+	if !args.hasQuota {
+		extraColumns = append(extraColumns, availableColumn, reasonColumn)
+	}
+	if args.showQuota {
+		extraColumns = append(extraColumns, quotaColumn)
+	}
+	if args.showDeprecated {
+		extraColumns = append(extraColumns, deprecatedColumn)
+	}
+	if args.showMaxPods {
+		extraColumns = append(extraColumns, maxPodsColumn)
+	}
+	for _, name := range extraColumns {
+		if !hasColumn(selectedColumns, name) {
+			extraColumn, _ := selectColumns([]string{name})
+			selectedColumns = append(selectedColumns, extraColumn...)
+		}
+	}
+
+	rows := displayedMachineTypes(machineTypes)
+	total := len(rows)
+	rows = limitResults(rows)
+	if csvOutput {
+		return writeCSV(w, selectedColumns, rows)
+	}
+
+	err := writeTable(w, selectedColumns, rows)
+	if err != nil {
+		return err
+	}
+	recordWatchRows(w, selectedColumns, rows)
+	if len(rows) < total && args.maxResults > 0 {
+		r.Reporter.Infof("Showing %d of %d instance types, use '--max-results 0' to list all of them",
+			len(rows), total)
+	}
+	return nil
+}
+
+// writeTable writes the machine types to w as a table with the selected columns, preceded by a
+// header row unless '--no-headers' was given.
+func writeTable(w io.Writer, selected []column, machineTypes ocm.MachineTypeList) error {
+	return newTable(selected).Write(w, machineTypes)
+}
+
+// writeCSV writes the machine types to w as comma separated values with the selected columns,
+// preceded by a header line unless '--no-headers' was given.
+func writeCSV(w io.Writer, selected []column, machineTypes ocm.MachineTypeList) error {
+	return newTable(selected).WriteCSV(w, machineTypes)
+}
+
+// truncateResults returns at most limit machine types, or all of them when limit is zero.
+func truncateResults(machineTypes ocm.MachineTypeList, limit int) ocm.MachineTypeList {
+	if limit > 0 && len(machineTypes) > limit {
+		return machineTypes[:limit]
+	}
+	return machineTypes
+}
+
+// lastRegion returns the region selected in the previous interactive run, or an empty string if
+// there is none or it isn't one of the given regions anymore.
+func lastRegion(r *rosa.Runtime, regions []string) string {
+	state, err := config.LoadState()
+	if err != nil {
+		r.Reporter.Debugf("Ignoring the last selected region: %v", err)
+		return ""
+	}
+	if !helper.Contains(regions, state.LastRegion) {
+		return ""
+	}
+	return state.LastRegion
+}
+
+// rememberRegion saves the region selected interactively, so that it is the default next time.
+// Failing to save it doesn't prevent listing the instance types.
+func rememberRegion(r *rosa.Runtime, region string) {
+	state, err := config.LoadState()
+	if err == nil {
+		state.LastRegion = region
+		err = config.SaveState(state)
+	}
+	if err != nil {
+		r.Reporter.Debugf("Failed to remember the selected region: %v", err)
+	}
+}
+
+// filterRegionsByPrefix returns the regions whose name starts with the given prefix.
+func filterRegionsByPrefix(regions []string, prefix string) []string {
+	var matches []string
+	for _, region := range regions {
+		if strings.HasPrefix(region, prefix) {
+			matches = append(matches, region)
+		}
+	}
+	return matches
+}
+
+// uniqueRegion returns the only region matching the prefix, so that scripts never pick a region by
+// accident.
+func uniqueRegion(matches []string, prefix string) (string, error) {
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("No region starts with '%s'", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("Region prefix '%s' is ambiguous, it matches %s", prefix, matches)
+	}
+}
+
+// regionOptionLabels returns the options of the interactive region prompt, with the display name of
+// each region after its ID when it has one.
+func regionOptionLabels(regions []string, displayNames map[string]string) []string {
+	labels := make([]string, len(regions))
+	for i, region := range regions {
+		labels[i] = regionOptionLabel(region, displayNames)
+	}
+	return labels
+}
+
+// regionOptionLabel returns the option of the interactive region prompt for a region, for example
+// 'us-east-1 (US East, N. Virginia)', or just the ID if it doesn't have a display name.
+func regionOptionLabel(region string, displayNames map[string]string) string {
+	displayName := displayNames[region]
+	if region == "" || displayName == "" {
+		return region
+	}
+	return fmt.Sprintf("%s (%s)", region, displayName)
+}
+
+// regionFromOption returns the ID of the region selected in the interactive region prompt.
+func regionFromOption(option string) string {
+	region, _, _ := strings.Cut(option, " ")
+	return region
+}
+
+// selectOutputColumns asks the user which columns to display. It is a variable so that tests can
+// replace the prompt.
+var selectOutputColumns = interactive.GetMultipleOptions
+
+// selectColumnsInteractively asks the user which columns to display, selecting the default ones
+// initially.
+func selectColumnsInteractively(cmd *cobra.Command) ([]column, error) {
+	names, err := selectOutputColumns(interactive.Input{
+		Question: "Columns",
+		Help:     cmd.Flags().Lookup("columns").Usage,
+		Options:  columnNames(),
+		Default:  defaultColumns,
+		Required: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Expected valid columns: %s", err)
+	}
+	return selectColumns(names)
+}
+
+// newAccessKeysClient creates the AWS client whose access keys are sent to OCM when there is no role
+// for it to assume. The keys are the ones of the 'osdCcsAdmin' user, as OCM can't use temporary
+// credentials without their session token. If the region isn't known yet, because it will be
+// selected interactively or all of them are listed, the client uses the default region, as the keys
+// don't depend on it.
+func newAccessKeysClient(r *rosa.Runtime, region string) (aws.Client, error) {
+	var awsClient aws.Client
+	var err error
+	if region == "" {
+		awsClient, err = regionAWSClient(r, aws.DefaultRegion)
+	} else {
+		// Without the supported regions the AWS client could be created for a region that ROSA
+		// doesn't support, failing later with a confusing error, so stop here instead:
+		var supportedRegions []string
+		supportedRegions, err = r.OCMClient.GetDatabaseRegionList()
+		if err != nil {
+			return nil, rosa.UpstreamError(fmt.Errorf("Failed to retrieve the regions supported by ROSA: %w", err))
+		}
+		awsClient, err = aws.NewClientForUserRegion(r.Logger, supportedRegions)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withAccessKeys(awsClient)
+}
+
+// accessKeysClient is an AWS client that always returns the same access keys.
+type accessKeysClient struct {
+	aws.Client
+	accessKeys *aws.AccessKey
+}
+
+func (c *accessKeysClient) GetAWSAccessKeys() (*aws.AccessKey, error) {
+	return c.accessKeys, nil
+}
+
+// withAccessKeys returns the AWS client with the access keys that are sent to OCM when there is no
+// role for it to assume. Getting them may replace the keys of the 'osdCcsAdmin' user, so they are
+// resolved once, and every request sends the same ones, including the concurrent requests of
+// '--region all'.
+func withAccessKeys(awsClient aws.Client) (aws.Client, error) {
+	accessKeys, err := awsClient.GetAWSAccessKeys()
+	if err != nil {
+		return nil, rosa.AuthError(fmt.Errorf("Failed to get the AWS access keys: %w", err))
+	}
+	return &accessKeysClient{
+		Client:     awsClient,
+		accessKeys: accessKeys,
+	}, nil
+}
+
+// regionAWSClient returns an AWS client for the region, the one of the runtime if it is for the same
+// region. When both '--profile' and '--role-arn' are given the client uses the credentials of the
+// role, assumed with the ones of the profile.
+func regionAWSClient(r *rosa.Runtime, region string) (aws.Client, error) {
+	if r.AWSClient != nil && r.AWSClient.GetRegion() == region {
+		return r.AWSClient, nil
+	}
+	builder := aws.NewClient().
+		Logger(r.Logger).
+		Region(region)
+	if args.roleARN != "" && arguments.GetProfile() != "" {
+		// The credentials of the profile are only used to assume the role:
+		builder = builder.AssumeRole(args.roleARN, args.externalID)
+	}
+	awsClient, err := builder.Build()
+	if err != nil {
+		return nil, rosa.UpstreamError(fmt.Errorf("Failed to create AWS client for region '%s': %w", region, err))
+	}
+	return awsClient, nil
+}
+
+// selectAvailabilityZones asks the user which availability zones to list. It is a variable so that
+// tests can replace the prompt.
+var selectAvailabilityZones = interactive.GetMultipleOptions
+
+// resolveAvailabilityZones returns the availability zones to list the instance types of: the ones
+// given with the '--availability-zones' flag or, in interactive mode, the ones selected by the
+// user. Zones that don't belong to the region are dropped or rejected before fetching any instance
+// types.
+func resolveAvailabilityZones(r *rosa.Runtime, cmd *cobra.Command, region string,
+	requested []string, timer *phaseTimer) ([]string, error) {
+	stop := timer.start("availability zones")
+	awsClient, err := regionAWSClient(r, region)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.OperationContext()
+	regionZones, err := awsClient.DescribeAvailabilityZones(ctx)
+	err = r.OperationError(ctx, err)
+	cancel()
+	stop()
+	if err != nil {
+		return nil, rosa.UpstreamError(fmt.Errorf("Failed to get the list of the availability zones: %w", err))
+	}
+	return selectZones(r, cmd, region, regionZones, requested)
+}
+
+// allZonesValue is the value of the '--availability-zones' flag that selects every zone of the
+// region.
+const allZonesValue = "all"
+
+// validateZonesFlag checks that the value that selects every zone isn't mixed with other zones.
+func validateZonesFlag(zones []string) error {
+	if len(zones) > 1 && helper.Contains(zones, allZonesValue) {
+		return fmt.Errorf("The '%s' value of the '--availability-zones' flag can't be combined with other zones",
+			allZonesValue)
+	}
+	return nil
+}
+
+// selectZones validates the requested availability zones against the ones of the region and, in
+// interactive mode, lets the user change the selection. The zones that don't belong to the region
+// are dropped with a warning, or rejected if '--strict' was given. Requesting 'all' selects all the
+// zones of the region.
+func selectZones(r *rosa.Runtime, cmd *cobra.Command, region string, regionZones []string,
+	requested []string) ([]string, error) {
+	var valid, invalid []string
+	if len(requested) == 1 && requested[0] == allZonesValue {
+		// The zones of the region don't need to be checked:
+		valid = regionZones
+		requested = nil
+	}
+	for _, zone := range requested {
+		if helper.Contains(regionZones, zone) {
+			valid = append(valid, zone)
 			continue
 		}
-		availableMachine := machine.MachineType
-		fmt.Fprintf(writer,
-			"%s\t%s\t%d\t%s\n",
-			availableMachine.ID(), availableMachine.Category(), int(availableMachine.CPU().Value()),
-			ByteCountIEC(int(availableMachine.Memory().Value()),
-				availableMachine.Memory().Unit()),
-		)
+		if args.strict {
+			return nil, rosa.UsageError(fmt.Errorf("Availability zone '%s' doesn't belong to region '%s'. "+
+				"Valid availability zones are %s", zone, region, regionZones))
+		}
+		invalid = append(invalid, zone)
+	}
+	if len(invalid) > 0 {
+		r.Reporter.Warnf("Ignoring availability zones %s that don't belong to region '%s'",
+			invalid, region)
+	}
+	if !interactive.Enabled() {
+		if len(valid) == 0 {
+			return nil, rosa.UsageError(fmt.Errorf("None of the availability zones belong to region '%s'. "+
+				"Valid availability zones are %s", region, regionZones))
+		}
+		return valid, nil
+	}
+	requested = valid
+	selected, err := selectAvailabilityZones(interactive.Input{
+		Question: "Availability zones",
+		Help:     cmd.Flags().Lookup("availability-zones").Usage,
+		Options:  regionZones,
+		Default:  requested,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Expected valid availability zones: %s", err)
+	}
+	return selected, nil
+}
+
+// allConflictingFlags are the flags that only make sense when checking the availability of the
+// instance types in a region, and so can't be combined with '--all'.
+var allConflictingFlags = []string{"availability-zones", "credentials-file", "external-id", "has-quota", "region",
+	"region-prefix", "role-arn", "strict"}
+
+// validateAllFlag returns an error naming the first conflicting flag set together with '--all'.
+func validateAllFlag(flags *pflag.FlagSet) error {
+	all := flags.Lookup("all")
+	if all == nil || !all.Changed || all.Value.String() != "true" {
+		return nil
+	}
+	for _, name := range allConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--all' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// validateStreamFlags checks that '--stream' and '--jsonl' are only used together with '--all', and
+// not with the flags that need the complete list of instance types.
+func validateStreamFlags(flags *pflag.FlagSet) error {
+	for _, name := range []string{"stream", "jsonl"} {
+		flag := flags.Lookup(name)
+		if flag == nil || flag.Value.String() != "true" {
+			continue
+		}
+		if !args.all {
+			return fmt.Errorf("The '--%s' flag can only be used together with '--all'", name)
+		}
+		for _, conflict := range []string{"sort", "reverse", "output", "count"} {
+			if flags.Changed(conflict) {
+				return fmt.Errorf("The '--%s' flag can't be used together with '--%s'", name, conflict)
+			}
+		}
+		if interactive.Enabled() {
+			return fmt.Errorf("The '--%s' flag can't be used in interactive mode", name)
+		}
+	}
+	return nil
+}
+
+// validatePageFlags checks the '--page' and '--size' flags, and returns true if any of them was given
+// so that only one page of instance types is fetched.
+func validatePageFlags(flags *pflag.FlagSet) (bool, error) {
+	if !flags.Changed("page") && !flags.Changed("size") {
+		return false, nil
+	}
+	if args.page < 1 {
+		return false, fmt.Errorf("Invalid page %d. It must be greater than zero", args.page)
+	}
+	if args.size < 1 {
+		return false, fmt.Errorf("Invalid page size %d. It must be greater than zero", args.size)
+	}
+	for _, conflict := range []string{"availability-zones", "stream", "jsonl"} {
+		if flags.Changed(conflict) {
+			return false, fmt.Errorf("The '--page' and '--size' flags can't be used together with '--%s'",
+				conflict)
+		}
 	}
-	writer.Flush()
+	return true, nil
 }
 
+// printPageInfo reports which page of instance types was fetched, unless the output is JSON or
+// YAML.
+func printPageInfo(r *rosa.Runtime, total int, csvOutput bool) {
+	if output.HasFlag() && !csvOutput {
+		return
+	}
+	pages := (total + args.size - 1) / args.size
+	if pages < 1 {
+		pages = 1
+	}
+	r.Reporter.Infof("Page %d of %d, %d instance types in total", args.page, pages, total)
+}
+
+// printCount writes the number of machine types to w, or prints it as an object with a 'count'
+// field when an output format was requested. In CSV format it is a single 'COUNT' column.
+func printCount(w io.Writer, count int) error {
+	if output.Output() == output.CSV {
+		return output.PrintCSV(w, []string{"COUNT"}, [][]string{{strconv.Itoa(count)}})
+	}
+	if output.HasFlag() {
+		return output.Print(w, map[string]interface{}{
+			"count": count,
+		})
+	}
+	_, err := fmt.Fprintln(w, count)
+	return err
+}
+
+// displayedMachineTypes returns the machine types that are displayed, which are only the available
+// ones unless those without quota are listed too.
+func displayedMachineTypes(machineTypes ocm.MachineTypeList) ocm.MachineTypeList {
+	return machineTypes.Filter(func(machine *ocm.MachineType) bool {
+		return machine.Available || !args.hasQuota
+	})
+}
+
+// withExtraFields converts the machine types to their JSON representation, adding the
+// availability zones each one is offered in, the number of instances the account has quota for,
+// whether it is deprecated and whether it is available and why not, which the OCM types don't have
+// fields for. The quota is null when it isn't known. The fields of OCM come from the SDK marshaller
+// and keep its order and number formats, so only the extra fields differ from the API.
+func withExtraFields(machineTypes ocm.MachineTypeList, zones bool, quota bool, deprecated bool,
+	availability bool, pods bool) ([]*ordered.OrderedMap, error) {
+	result := make([]*ordered.OrderedMap, 0, len(machineTypes))
+	for _, machineType := range machineTypes {
+		var b bytes.Buffer
+		err := cmv1.MarshalMachineType(machineType.MachineType, &b)
+		if err != nil {
+			return nil, err
+		}
+		item := ordered.NewOrderedMap()
+		err = json.Unmarshal(b.Bytes(), item)
+		if err != nil {
+			return nil, err
+		}
+		if zones {
+			item.Set("availability_zones", machineType.AvailabilityZones)
+		}
+		if quota {
+			item.Set("quota", nil)
+			if available, ok := machineType.AvailableQuota(); ok {
+				item.Set("quota", available)
+			}
+		}
+		if deprecated {
+			item.Set("deprecated", machineType.Deprecated())
+		}
+		if availability {
+			item.Set("available", machineType.Available && machineType.UnavailableReason() == "")
+			item.Set("unavailable_reason", machineType.UnavailableReason())
+		}
+		if pods {
+			item.Set("max_pods", maxPods(machineType))
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// sortMachineTypes sorts the machine types in place by the given key. Ties are broken by the
+// machine type ID so that the resulting order is always deterministic.
+func sortMachineTypes(machineTypes ocm.MachineTypeList, key string, reverse bool) {
+	sort.SliceStable(machineTypes, func(i, j int) bool {
+		a := machineTypes[i].MachineType
+		b := machineTypes[j].MachineType
+		var cmp int
+		switch key {
+		case "cpu":
+			cmp = compareValues(a.CPU().Value(), b.CPU().Value())
+		case "memory":
+			cmp = compareValues(instancetypes.MemoryBytes(a), instancetypes.MemoryBytes(b))
+		case "category":
+			cmp = strings.Compare(string(a.Category()), string(b.Category()))
+		}
+		if cmp == 0 {
+			cmp = strings.Compare(a.ID(), b.ID())
+		}
+		if reverse {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+func compareValues(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ByteCountIEC formats the given amount of memory using binary (IEC) prefixes, for example
+// '16.0 GiB'. The value is first converted to bytes according to its unit, so a small value in a
+// larger unit, like 512 MiB, is displayed with the prefix that fits it instead of in bytes. The
+// number of decimals and the separators are the ones given in the command line.
 func ByteCountIEC(b int, uValue string) string {
-	var unit int
-	if uValue == "B" {
-		unit = 1024
+	bytes, ok := instancetypes.ToBytes(b, uValue)
+	if !ok {
+		return fmt.Sprintf("%d %s", b, uValue)
+	}
+	return FormatBytes(int64(bytes), numberFormat())
+}
+
+// FormatBytes formats a number of bytes using binary (IEC) prefixes and the given number format.
+// Values below 1024 stay in bytes without decimals, for example '512 B'. From there on the largest
+// prefix whose value is at least one is used, so 1024 bytes are '1.0 KiB' and 1048576 are
+// '1.0 MiB'. A value that would round up to 1024 of a prefix uses the next one, so 1048575 bytes are
+// '1.0 MiB' and not '1024.0 KiB'.
+func FormatBytes(bytes int64, format NumberFormat) string {
+	return byteCount(bytes, 1024, "KMGTPE", "i", format)
+}
+
+// ByteCountSI formats the given amount of memory using decimal (SI) prefixes, for example
+// '17.2 GB'. The value is first converted to bytes according to its unit.
+func ByteCountSI(b int, uValue string) string {
+	bytes, ok := instancetypes.ToBytes(b, uValue)
+	if !ok {
+		return fmt.Sprintf("%d %s", b, uValue)
 	}
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
+	return byteCount(int64(bytes), 1000, "kMGTPE", "", numberFormat())
+}
+
+// byteCount formats a number of bytes with the given base and the prefixes of its powers, from the
+// first one.
+func byteCount(b int64, base int64, prefixes string, infix string, format NumberFormat) string {
+	if b > -base && b < base {
+		return formatNumber(float64(b), 0, format.ThousandsSeparator, format.DecimalSeparator) + " B"
 	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= int64(unit)
+	// Keep dividing while the rounded value would still be displayed as the base or more, so that
+	// values like 1048575 bytes are displayed as '1.0 MiB' instead of '1024.0 KiB':
+	scale := math.Pow10(format.Precision)
+	value, exp := float64(b), -1
+	for math.Abs(math.Round(value*scale)/scale) >= float64(base) && exp < len(prefixes)-1 {
+		value /= float64(base)
 		exp++
 	}
-	return fmt.Sprintf("%.1f %ciB",
-		float64(b)/float64(div), "KMGTPE"[exp])
+	return fmt.Sprintf("%s %c%sB", formatNumber(value, format.Precision, format.ThousandsSeparator,
+		format.DecimalSeparator), prefixes[exp], infix)
 }
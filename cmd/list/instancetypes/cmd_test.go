@@ -0,0 +1,333 @@
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	amsv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+func buildMachineType(id string, category cmv1.MachineTypeCategory, cpu float64, memory float64) *ocm.MachineType {
+	machineType, err := cmv1.NewMachineType().
+		ID(id).
+		Category(category).
+		CPU(cmv1.NewValue().Value(cpu).Unit("vCPU")).
+		Memory(cmv1.NewValue().Value(memory).Unit("B")).
+		Build()
+	Expect(err).NotTo(HaveOccurred())
+	return &ocm.MachineType{
+		MachineType: machineType,
+		Available:   true,
+	}
+}
+
+func buildGPUMachineType(id string, genericName string) *ocm.MachineType {
+	machineType, err := cmv1.NewMachineType().
+		ID(id).
+		GenericName(genericName).
+		Category(cmv1.MachineTypeCategoryAcceleratedComputing).
+		CPU(cmv1.NewValue().Value(4).Unit("vCPU")).
+		Memory(cmv1.NewValue().Value(17179869184).Unit("B")).
+		Build()
+	Expect(err).NotTo(HaveOccurred())
+	return &ocm.MachineType{MachineType: machineType}
+}
+
+// buildQuotaCosts returns the quota of the account for the machine type with the given generic name.
+func buildQuotaCosts(genericName string, allowed int, consumed int) *amsv1.QuotaCostList {
+	quotaCosts, err := amsv1.NewQuotaCostList().Items(
+		amsv1.NewQuotaCost().
+			Allowed(allowed).
+			Consumed(consumed).
+			RelatedResources(amsv1.NewRelatedResource().
+				ResourceName(genericName).
+				Product("rosa").
+				CloudProvider("aws").
+				BYOC("byoc").
+				Cost(1)),
+	).Build()
+	Expect(err).NotTo(HaveOccurred())
+	return quotaCosts
+}
+
+var _ = Describe("Instance types", func() {
+	var machineTypes ocm.MachineTypeList
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("c5.2xlarge", cmv1.MachineTypeCategoryComputeOptimized, 8, 17179869184),
+			buildMachineType("m5.2xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 8, 34359738368),
+		}
+	})
+
+	It("Sorts by memory comparing the values in bytes", func() {
+		inGiB, err := cmv1.NewMachineType().ID("x1.large").
+			Memory(cmv1.NewValue().Value(20).Unit("GiB")).Build()
+		Expect(err).NotTo(HaveOccurred())
+		machineTypes = append(machineTypes, &ocm.MachineType{MachineType: inGiB})
+		sortMachineTypes(machineTypes, "memory", false)
+		Expect(machineTypes.IDs()).To(Equal(
+			[]string{"c5.2xlarge", "m5.xlarge", "x1.large", "m5.2xlarge", "r5.xlarge"}))
+	})
+
+	DescribeTable("sortMachineTypes",
+		func(key string, reverse bool, expected []string) {
+			sortMachineTypes(machineTypes, key, reverse)
+			Expect(machineTypes.IDs()).To(Equal(expected))
+		},
+		Entry("by ID", "id", false,
+			[]string{"c5.2xlarge", "m5.2xlarge", "m5.xlarge", "r5.xlarge"}),
+		Entry("by ID reversed", "id", true,
+			[]string{"r5.xlarge", "m5.xlarge", "m5.2xlarge", "c5.2xlarge"}),
+		Entry("by CPU falling back to ID", "cpu", false,
+			[]string{"m5.xlarge", "r5.xlarge", "c5.2xlarge", "m5.2xlarge"}),
+		Entry("by memory falling back to ID", "memory", false,
+			[]string{"c5.2xlarge", "m5.xlarge", "m5.2xlarge", "r5.xlarge"}),
+		Entry("by memory reversed", "memory", true,
+			[]string{"r5.xlarge", "m5.2xlarge", "m5.xlarge", "c5.2xlarge"}),
+		Entry("by category falling back to ID", "category", false,
+			[]string{"c5.2xlarge", "m5.2xlarge", "m5.xlarge", "r5.xlarge"}),
+	)
+
+	DescribeTable("truncateResults",
+		func(limit int, expected []string) {
+			sortMachineTypes(machineTypes, "memory", true)
+			truncated := truncateResults(machineTypes, limit)
+			Expect(truncated.IDs()).To(Equal(expected))
+		},
+		Entry("unlimited", 0, []string{"r5.xlarge", "m5.2xlarge", "m5.xlarge", "c5.2xlarge"}),
+		Entry("top 2 by memory", 2, []string{"r5.xlarge", "m5.2xlarge"}),
+		Entry("more than available", 10, []string{"r5.xlarge", "m5.2xlarge", "m5.xlarge", "c5.2xlarge"}),
+	)
+})
+
+var _ = Describe("Byte formatting", func() {
+	DescribeTable("ByteCountIEC",
+		func(b int, expected string) {
+			Expect(ByteCountIEC(b, "B")).To(Equal(expected))
+		},
+		Entry("zero", 0, "0 B"),
+		Entry("just below a KiB", 1023, "1023 B"),
+		Entry("one KiB", 1024, "1.0 KiB"),
+		Entry("just above a KiB", 1025, "1.0 KiB"),
+		Entry("just below a MiB", 1048575, "1.0 MiB"),
+		Entry("one MiB", 1048576, "1.0 MiB"),
+		Entry("16 GiB", 17179869184, "16.0 GiB"),
+	)
+
+	DescribeTable("FormatBytes",
+		func(b int64, expected string) {
+			Expect(FormatBytes(b, DefaultNumberFormat)).To(Equal(expected))
+		},
+		Entry("zero", int64(0), "0 B"),
+		Entry("one byte", int64(1), "1 B"),
+		Entry("just below a KiB", int64(1023), "1023 B"),
+		Entry("one KiB", int64(1<<10), "1.0 KiB"),
+		Entry("just below a MiB", int64(1<<20-1), "1.0 MiB"),
+		Entry("one MiB", int64(1<<20), "1.0 MiB"),
+		Entry("just below a GiB", int64(1<<30-1), "1.0 GiB"),
+		Entry("one GiB", int64(1<<30), "1.0 GiB"),
+		Entry("one TiB", int64(1<<40), "1.0 TiB"),
+		Entry("one PiB", int64(1<<50), "1.0 PiB"),
+		Entry("one EiB", int64(1<<60), "1.0 EiB"),
+		Entry("largest value", int64(math.MaxInt64), "8.0 EiB"),
+		Entry("half way", int64(1536), "1.5 KiB"),
+		Entry("negative", int64(-2048), "-2.0 KiB"),
+	)
+
+	It("formats the bytes with the given number format", func() {
+		format := NumberFormat{Precision: 2, ThousandsSeparator: ".", DecimalSeparator: ","}
+		Expect(FormatBytes(1536, format)).To(Equal("1,50 KiB"))
+		Expect(FormatBytes(1000, format)).To(Equal("1.000 B"))
+	})
+
+	DescribeTable("ByteCountIEC with other units",
+		func(b int, unit string, expected string) {
+			Expect(ByteCountIEC(b, unit)).To(Equal(expected))
+		},
+		Entry("zero bytes", 0, "B", "0 B"),
+		Entry("zero GiB", 0, "GiB", "0 B"),
+		Entry("bytes", 17179869184, "B", "16.0 GiB"),
+		Entry("KiB", 16777216, "KiB", "16.0 GiB"),
+		Entry("MiB", 16384, "MiB", "16.0 GiB"),
+		Entry("GiB", 16, "GiB", "16.0 GiB"),
+		Entry("small MiB", 512, "MiB", "512.0 MiB"),
+		Entry("unknown unit", 16, "GB", "16 GB"),
+	)
+
+	DescribeTable("ByteCountSI",
+		func(b int, expected string) {
+			Expect(ByteCountSI(b, "B")).To(Equal(expected))
+		},
+		Entry("zero", 0, "0 B"),
+		Entry("just below a kB", 999, "999 B"),
+		Entry("one kB", 1000, "1.0 kB"),
+		Entry("one KiB", 1024, "1.0 kB"),
+		Entry("just below a MB", 999999, "1.0 MB"),
+		Entry("one MB", 1000000, "1.0 MB"),
+		Entry("16 GiB", 17179869184, "17.2 GB"),
+	)
+
+	It("adds the availability zones to the JSON representation", func() {
+		machineType := buildMachineType("m5.xlarge", "general_purpose", 4, 17179869184)
+		machineType.AvailabilityZones = []string{"us-east-1a", "us-east-1c"}
+
+		items, err := withExtraFields(ocm.MachineTypeList{machineType}, true, false, false, false, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(HaveLen(1))
+		Expect(items[0].Get("id")).To(Equal("m5.xlarge"))
+		Expect(items[0].Get("availability_zones")).To(Equal([]string{"us-east-1a", "us-east-1c"}))
+		Expect(items[0].Has("quota")).To(BeFalse())
+	})
+
+	It("adds the quota to the JSON representation", func() {
+		list := ocm.MachineTypeList{
+			buildGPUMachineType("g4dn.xlarge", "t4-gpu-4"),
+			buildMachineType("m5.xlarge", "general_purpose", 4, 17179869184),
+		}
+		list.UpdateAvailableQuota(buildQuotaCosts("t4-gpu-4", 10, 4))
+
+		items, err := withExtraFields(list, false, true, false, false, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].Get("quota")).To(Equal(6))
+		Expect(items[0].Has("availability_zones")).To(BeFalse())
+		Expect(items[1].Has("quota")).To(BeTrue())
+		Expect(items[1].Get("quota")).To(BeNil())
+	})
+
+	It("marks the deprecated machine types in the JSON representation", func() {
+		list := ocm.MachineTypeList{
+			buildMachineType("m4.xlarge", "general_purpose", 4, 17179869184),
+			buildMachineType("m5.xlarge", "general_purpose", 4, 17179869184),
+		}
+
+		items, err := withExtraFields(list, false, false, true, false, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items[0].Get("deprecated")).To(BeTrue())
+		Expect(items[1].Get("deprecated")).To(BeFalse())
+		Expect(items[0].Has("quota")).To(BeFalse())
+	})
+
+	It("adds the availability to the JSON representation", func() {
+		list := ocm.MachineTypeList{
+			buildGPUMachineType("g4dn.xlarge", "t4-gpu-4"),
+			buildMachineType("m5.xlarge", "general_purpose", 4, 17179869184),
+		}
+		list.UpdateAvailableQuota(buildQuotaCosts("t4-gpu-4", 10, 10))
+
+		items, err := withExtraFields(list, false, false, false, true, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items[0].Get("available")).To(BeFalse())
+		Expect(items[0].Get("unavailable_reason")).To(Equal(
+			"The account has quota for only 0 instances, a cluster needs more than 2"))
+		Expect(items[1].Get("available")).To(BeTrue())
+		Expect(items[1].Get("unavailable_reason")).To(Equal(""))
+	})
+
+	It("displays the unavailable machine types only without the quota filter", func() {
+		list := ocm.MachineTypeList{
+			buildGPUMachineType("g4dn.xlarge", "t4-gpu-4"),
+			buildMachineType("m5.xlarge", "general_purpose", 4, 17179869184),
+		}
+		displayed := displayedMachineTypes(list)
+		Expect(displayed.IDs()).To(Equal([]string{"m5.xlarge"}))
+		args.hasQuota = false
+		defer func() {
+			args.hasQuota = true
+		}()
+		displayed = displayedMachineTypes(list)
+		Expect(displayed.IDs()).To(Equal([]string{"g4dn.xlarge", "m5.xlarge"}))
+	})
+
+	DescribeTable("completeList",
+		func(toComplete string, expected []string) {
+			zones := []string{"us-east-1a", "us-east-1b", "us-east-1c"}
+			Expect(completeList(zones, toComplete)).To(Equal(expected))
+		},
+		Entry("first element", "us-ea", []string{"us-east-1a", "us-east-1b", "us-east-1c"}),
+		Entry("second element", "us-east-1b,",
+			[]string{"us-east-1b,us-east-1a", "us-east-1b,us-east-1c"}),
+		Entry("third element", "us-east-1a,us-east-1c,us",
+			[]string{"us-east-1a,us-east-1c,us-east-1b"}),
+	)
+})
+
+var _ = Describe("errorHint", func() {
+	DescribeTable("suggests what to do when the credentials are rejected",
+		func(err error, expected string) {
+			Expect(errorHint(err)).To(Equal(expected))
+		},
+		Entry("expired OCM token",
+			rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", &ocm.APIError{Status: 401})),
+			"The OCM token has expired or isn't valid, run 'rosa login' to log in again"),
+		Entry("OCM server error",
+			fmt.Errorf("Failed to fetch instance types: %w", &ocm.APIError{Status: 500}),
+			""),
+		Entry("expired AWS token",
+			fmt.Errorf("Failed to get the list of the availability zones: %w",
+				aws.WrapAuthError(awserr.New("ExpiredToken", "The security token is expired", nil))),
+			"The AWS credentials were rejected with 'ExpiredToken', check them with 'rosa whoami --check'"),
+		Entry("other error", errors.New("boom"), ""),
+	)
+})
+
+var _ = Describe("JSON output", func() {
+	var machineType *ocm.MachineType
+	var marshalled []byte
+
+	BeforeEach(func() {
+		machineType = buildGPUMachineType("g4dn.xlarge", "t4-gpu-4")
+		machineType.AvailabilityZones = []string{"us-east-1a", "us-east-1c"}
+		var b bytes.Buffer
+		Expect(cmv1.MarshalMachineTypeList([]*cmv1.MachineType{machineType.MachineType}, &b)).To(Succeed())
+		var compact bytes.Buffer
+		Expect(json.Compact(&compact, b.Bytes())).To(Succeed())
+		marshalled = compact.Bytes()
+		Expect(Cmd.Flags().Set("output", "json")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(Cmd.Flags().Set("output", "")).To(Succeed())
+	})
+
+	// indented returns the compact JSON indented like the output of '-o json':
+	indented := func(compact []byte) string {
+		var b bytes.Buffer
+		Expect(json.Indent(&b, compact, "", "  ")).To(Succeed())
+		return b.String() + "\n"
+	}
+
+	It("is the output of the SDK marshaller", func() {
+		resource, err := jsonResource(ocm.MachineTypeList{machineType}, false)
+		Expect(err).NotTo(HaveOccurred())
+		var b bytes.Buffer
+		Expect(output.Print(&b, resource)).To(Succeed())
+		Expect(b.String()).To(Equal(indented(marshalled)))
+	})
+
+	It("keeps the output of the SDK marshaller and adds the extra fields after it", func() {
+		resource, err := jsonResource(ocm.MachineTypeList{machineType}, true)
+		Expect(err).NotTo(HaveOccurred())
+		var b bytes.Buffer
+		Expect(output.Print(&b, resource)).To(Succeed())
+
+		Expect(marshalled).To(HaveSuffix("}]"))
+		expected := string(marshalled[:len(marshalled)-2]) + `,"availability_zones":["us-east-1a","us-east-1c"]}]`
+		Expect(b.String()).To(Equal(indented([]byte(expected))))
+	})
+})
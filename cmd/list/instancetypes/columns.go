@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+)
+
+// column describes one of the columns that can be displayed in the instance types table. The items
+// of the table are *ocm.MachineType values.
+type column = output.Column
+
+// machineTypeValue adapts a function that returns the value of a column for a machine type to the
+// item values of the output tables.
+func machineTypeValue(value func(machineType *ocm.MachineType) string) func(item interface{}) string {
+	return func(item interface{}) string {
+		return value(item.(*ocm.MachineType))
+	}
+}
+
+var columns = []column{
+	{
+		Name:   "id",
+		Header: "ID",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return machineType.MachineType.ID()
+		}),
+	},
+	{
+		Name:   "name",
+		Header: "NAME",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return machineType.MachineType.Name()
+		}),
+	},
+	{
+		Name:   "category",
+		Header: "CATEGORY",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return string(machineType.MachineType.Category())
+		}),
+	},
+	{
+		Name:   "size",
+		Header: "SIZE",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return string(machineType.MachineType.Size())
+		}),
+	},
+	{
+		Name:   "cpu",
+		Header: "CPU_CORES",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return formatInteger(int(machineType.MachineType.CPU().Value()))
+		}),
+	},
+	{
+		Name:   "memory",
+		Header: "MEMORY",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return formatMemory(machineType.MachineType)
+		}),
+		CSVHeader: "MEMORY_BYTES",
+		CSVValue: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return strconv.FormatFloat(instancetypes.MemoryBytes(machineType.MachineType), 'f', 0, 64)
+		}),
+	},
+	{
+		Name:   "architecture",
+		Header: "ARCHITECTURE",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return machineType.Architecture()
+		}),
+	},
+	{
+		Name:   zonesColumn,
+		Header: "AVAILABILITY_ZONES",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return strings.Join(machineType.AvailabilityZones, ",")
+		}),
+	},
+	{
+		Name:   availableColumn,
+		Header: "AVAILABLE",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return fmt.Sprintf("%v", machineType.Available && machineType.UnavailableReason() == "")
+		}),
+	},
+	{
+		Name:   reasonColumn,
+		Header: "REASON",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return machineType.UnavailableReason()
+		}),
+	},
+	{
+		Name:   "generic-name",
+		Header: "GENERIC_NAME",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return machineType.MachineType.GenericName()
+		}),
+	},
+	{
+		Name:   gpuColumn,
+		Header: "GPU",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return fmt.Sprintf("%v", instancetypes.HasGPU(machineType))
+		}),
+	},
+	{
+		Name:   "family",
+		Header: "FAMILY",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return machineType.Family()
+		}),
+	},
+	{
+		Name:   quotaColumn,
+		Header: "QUOTA",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			quota, ok := machineType.AvailableQuota()
+			if !ok {
+				return "-"
+			}
+			return strconv.Itoa(quota)
+		}),
+	},
+	{
+		Name:   deprecatedColumn,
+		Header: "DEPRECATED",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			if machineType.Deprecated() {
+				return "yes"
+			}
+			return "no"
+		}),
+	},
+	{
+		Name:   networkColumn,
+		Header: "NETWORK",
+		Value:  machineTypeValue(networkValue),
+	},
+	{
+		Name:   maxPodsColumn,
+		Header: "MAX_PODS",
+		Value:  machineTypeValue(maxPodsValue),
+	},
+}
+
+// zonesColumn is added to the selected columns when listing by availability zone.
+const zonesColumn = "availability-zones"
+
+// gpuColumn is added to the selected columns when listing only the instance types with GPUs.
+const gpuColumn = "gpu"
+
+// quotaColumn is added to the selected columns when '--show-quota' is given.
+const quotaColumn = "quota"
+
+// deprecatedColumn is added to the selected columns when '--show-deprecated' is given.
+const deprecatedColumn = "deprecated"
+
+// availableColumn and reasonColumn are added to the selected columns when the instance types without
+// enough quota are listed too.
+const (
+	availableColumn = "available"
+	reasonColumn    = "reason"
+)
+
+var defaultColumns = []string{"id", "category", "cpu", "memory", "architecture"}
+
+// wideColumns are the columns displayed with '--wide'.
+var wideColumns = []string{"id", "family", "category", "architecture", "cpu", "memory", gpuColumn, zonesColumn}
+
+func columnNames() []string {
+	return output.ColumnNames(columns)
+}
+
+// formatMemory formats the memory of the machine type with the prefixes selected with the
+// '--memory-unit' flag.
+func formatMemory(machineType *cmv1.MachineType) string {
+	memory := machineType.Memory()
+	if args.memoryUnit == "si" {
+		return ByteCountSI(int(memory.Value()), memory.Unit())
+	}
+	return ByteCountIEC(int(memory.Value()), memory.Unit())
+}
+
+// selectColumns returns the column definitions matching the given names, in the given order.
+func selectColumns(names []string) ([]column, error) {
+	return output.SelectColumns(columns, names)
+}
+
+// hasColumn returns true if the column with the given name is one of the selected ones.
+func hasColumn(selected []column, name string) bool {
+	for _, c := range selected {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newTable returns the table used to print the machine types with the selected columns, without a
+// header row when '--no-headers' was given.
+func newTable(selected []column) *output.Table {
+	return &output.Table{
+		Columns:   selected,
+		NoHeaders: args.noHeaders,
+	}
+}
@@ -0,0 +1,136 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Columns", func() {
+	It("Selects the default columns", func() {
+		selected, err := selectColumns(defaultColumns)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).HeaderRow()).To(Equal("ID\tCATEGORY\tCPU_CORES\tMEMORY\tARCHITECTURE\t\n"))
+
+		machineType := buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184)
+		Expect(newTable(selected).Row(machineType)).To(Equal("m5.xlarge\tgeneral_purpose\t4\t16.0 GiB\tx86_64\n"))
+	})
+
+	It("Keeps the requested order", func() {
+		selected, err := selectColumns([]string{"cpu", "ID"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).HeaderRow()).To(Equal("CPU_CORES\tID\t\n"))
+	})
+
+	It("Fails listing the valid columns for an unknown column", func() {
+		_, err := selectColumns([]string{"id", "price"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Invalid column 'price'. Valid columns are " +
+			"[id name category size cpu memory architecture availability-zones available reason generic-name gpu family " +
+			"quota deprecated network max-pods]"))
+	})
+
+	It("Explains why an instance type isn't available", func() {
+		selected, err := selectColumns([]string{"id", availableColumn, reasonColumn})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).HeaderRow()).To(Equal("ID\tAVAILABLE\tREASON\t\n"))
+
+		machineType := buildMachineType("p3.2xlarge", cmv1.MachineTypeCategoryAcceleratedComputing, 8, 65498251264)
+		machineType.Available = false
+		Expect(newTable(selected).Row(machineType)).To(Equal(
+			"p3.2xlarge\tfalse\tThe account has no quota for this instance type\n"))
+
+		machineType = buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184)
+		Expect(newTable(selected).Row(machineType)).To(Equal("m5.xlarge\ttrue\t\n"))
+	})
+
+	It("Shows the quota, or '-' when there is no quota information", func() {
+		selected, err := selectColumns([]string{"id", quotaColumn})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).HeaderRow()).To(Equal("ID\tQUOTA\t\n"))
+
+		list := ocm.MachineTypeList{
+			buildGPUMachineType("g4dn.xlarge", "t4-gpu-4"),
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		}
+		list.UpdateAvailableQuota(buildQuotaCosts("t4-gpu-4", 10, 4))
+		Expect(newTable(selected).Row(list[0])).To(Equal("g4dn.xlarge\t6\n"))
+		Expect(newTable(selected).Row(list[1])).To(Equal("m5.xlarge\t-\n"))
+	})
+
+	It("Keeps the wide columns aligned", func() {
+		selected, err := selectColumns(wideColumns)
+		Expect(err).NotTo(HaveOccurred())
+		gpu := buildGPUMachineType("g4dn.12xlarge", "t4-gpu-48")
+		gpu.AvailabilityZones = []string{"us-east-1a", "us-east-1b"}
+		machineTypes := ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			gpu,
+		}
+
+		var b bytes.Buffer
+		Expect(writeTable(&b, selected, machineTypes)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"ID             FAMILY  CATEGORY               ARCHITECTURE  CPU_CORES  MEMORY    GPU    " +
+			"AVAILABILITY_ZONES  \n" +
+			"m5.xlarge      m5      general_purpose        x86_64        4          16.0 GiB  false  \n" +
+			"g4dn.12xlarge  g4dn    accelerated_computing  x86_64        4          16.0 GiB  true   " +
+			"us-east-1a,us-east-1b\n"))
+	})
+
+	It("Keeps the rows aligned without the header row", func() {
+		selected, err := selectColumns([]string{"id", "cpu"})
+		Expect(err).NotTo(HaveOccurred())
+		machineTypes := ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m5.12xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 48, 206158430208),
+		}
+
+		var b bytes.Buffer
+		Expect(writeTable(&b, selected, machineTypes)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"ID           CPU_CORES  \n" +
+			"m5.xlarge    4\n" +
+			"m5.12xlarge  48\n"))
+
+		args.noHeaders = true
+		defer func() {
+			args.noHeaders = false
+		}()
+		b.Reset()
+		Expect(writeTable(&b, selected, machineTypes)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"m5.xlarge    4\n" +
+			"m5.12xlarge  48\n"))
+	})
+
+	It("Writes CSV with the memory in bytes", func() {
+		selected, err := selectColumns([]string{"id", "name", "memory"})
+		Expect(err).NotTo(HaveOccurred())
+		machineType, err := cmv1.NewMachineType().
+			ID("m5.xlarge").
+			Name("m5.xlarge - General Purpose, 4 vCPU").
+			Memory(cmv1.NewValue().Value(16).Unit("GiB")).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		machineTypes := ocm.MachineTypeList{{MachineType: machineType}}
+
+		var b bytes.Buffer
+		Expect(writeCSV(&b, selected, machineTypes)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"ID,NAME,MEMORY_BYTES\n" +
+			"m5.xlarge,\"m5.xlarge - General Purpose, 4 vCPU\",17179869184\n"))
+
+		args.noHeaders = true
+		defer func() {
+			args.noHeaders = false
+		}()
+		b.Reset()
+		Expect(writeCSV(&b, selected, machineTypes)).To(Succeed())
+		Expect(b.String()).To(Equal("m5.xlarge,\"m5.xlarge - General Purpose, 4 vCPU\",17179869184\n"))
+	})
+})
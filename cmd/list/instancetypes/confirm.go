@@ -0,0 +1,39 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"github.com/openshift/rosa/pkg/interactive"
+	"github.com/openshift/rosa/pkg/interactive/confirm"
+)
+
+// largeListing is the number of instance types above which listing all of them in interactive mode
+// needs to be confirmed.
+const largeListing = 100
+
+// confirmPrompt asks a yes or no question, it is a variable so that tests can answer it.
+var confirmPrompt = confirm.Prompt
+
+// confirmLargeListing returns true if the machine types listed with '--all' can be printed. In
+// interactive mode, when there are many of them, it asks first, unless '--yes' was given or the
+// output isn't a terminal, so that scripts are never blocked.
+func confirmLargeListing(isTerminal bool, count int) bool {
+	if !args.all || !interactive.Enabled() || !isTerminal || confirm.Yes() || count <= largeListing {
+		return true
+	}
+	return confirmPrompt(false, "This will list all the %d instance types from AWS, continue?", count)
+}
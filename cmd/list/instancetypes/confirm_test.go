@@ -0,0 +1,61 @@
+package instancetypes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/rosa/pkg/interactive"
+)
+
+var _ = Describe("Large listing confirmation", func() {
+	var question string
+	var answer bool
+
+	BeforeEach(func() {
+		args.all = true
+		interactive.Enable()
+		question = ""
+		answer = false
+		saved := confirmPrompt
+		confirmPrompt = func(_ bool, q string, v ...interface{}) bool {
+			question = q
+			return answer
+		}
+		DeferCleanup(func() {
+			confirmPrompt = saved
+			args.all = false
+			Expect(Cmd.Flags().Set("interactive", "false")).To(Succeed())
+			Expect(Cmd.Flags().Set("yes", "false")).To(Succeed())
+		})
+	})
+
+	It("asks before listing many instance types", func() {
+		Expect(confirmLargeListing(true, largeListing+1)).To(BeFalse())
+		Expect(question).To(ContainSubstring("This will list all the %d instance types from AWS"))
+
+		answer = true
+		Expect(confirmLargeListing(true, largeListing+1)).To(BeTrue())
+	})
+
+	It("doesn't ask for a few instance types", func() {
+		Expect(confirmLargeListing(true, largeListing)).To(BeTrue())
+		Expect(question).To(BeEmpty())
+	})
+
+	It("doesn't ask when the output isn't a terminal", func() {
+		Expect(confirmLargeListing(false, largeListing+1)).To(BeTrue())
+		Expect(question).To(BeEmpty())
+	})
+
+	It("doesn't ask when '--yes' is given", func() {
+		Expect(Cmd.Flags().Set("yes", "true")).To(Succeed())
+		Expect(confirmLargeListing(true, largeListing+1)).To(BeTrue())
+		Expect(question).To(BeEmpty())
+	})
+
+	It("doesn't ask outside interactive mode", func() {
+		Expect(Cmd.Flags().Set("interactive", "false")).To(Succeed())
+		Expect(confirmLargeListing(true, largeListing+1)).To(BeTrue())
+		Expect(question).To(BeEmpty())
+	})
+})
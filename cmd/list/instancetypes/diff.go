@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// diffRegionConflictingFlags are the flags that don't select a list of instance types of a single
+// region that can be compared with another one.
+var diffRegionConflictingFlags = []string{"all", "availability-zones", "stream", "jsonl", "page", "size",
+	"count"}
+
+func validateDiffRegionFlag(flags *pflag.FlagSet) error {
+	if args.diffRegion == "" {
+		return nil
+	}
+	if args.diffRegion == allRegionsValue {
+		return fmt.Errorf("The '--diff-region' flag needs a single region")
+	}
+	for _, name := range diffRegionConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--diff-region' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// regionDiff is the result of comparing the instance types of two regions. The instance types only
+// in the first region and the common ones keep the order of the first region, and the ones only in
+// the second region keep its order.
+type regionDiff struct {
+	onlyInFirst  ocm.MachineTypeList
+	onlyInSecond ocm.MachineTypeList
+	common       ocm.MachineTypeList
+}
+
+func diffMachineTypes(first, second ocm.MachineTypeList) regionDiff {
+	var diff regionDiff
+	for _, machineType := range first {
+		if second.Find(machineType.MachineType.ID()) != nil {
+			diff.common = append(diff.common, machineType)
+		} else {
+			diff.onlyInFirst = append(diff.onlyInFirst, machineType)
+		}
+	}
+	for _, machineType := range second {
+		if first.Find(machineType.MachineType.ID()) == nil {
+			diff.onlyInSecond = append(diff.onlyInSecond, machineType)
+		}
+	}
+	return diff
+}
+
+// runDiffRegion lists the instance types of both regions, with a column for each region telling if
+// the instance type is available in it.
+func runDiffRegion(r *rosa.Runtime, region string, other string, selectedColumns []column, minMemory uint64,
+	gpu *bool, csvOutput bool) error {
+	if other == region {
+		return rosa.UsageError(fmt.Errorf("The region given with '--diff-region' must be different from '%s'",
+			region))
+	}
+	lists := make([]ocm.MachineTypeList, 2)
+	for i, name := range []string{region, other} {
+		r.Reporter.Debugf("Fetching instance types in region '%s'", name)
+		ctx, cancel := r.OperationContext()
+		stopSpinner := startSpinner(r)
+		machineTypes, err := instancetypes.List(ctx, r.OCMClient, r.AWSClient, instancetypes.Filters{
+			Region:     name,
+			HasQuota:   args.hasQuota,
+			RoleARN:    args.roleARN,
+			ExternalID: args.externalID,
+		})
+		stopSpinner()
+		err = r.OperationError(ctx, err)
+		cancel()
+		if err != nil {
+			return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types in region '%s': %w", name, err))
+		}
+		lists[i], err = applyFilters(r, machineTypes, args.categories, minMemory, gpu)
+		if err != nil {
+			return err
+		}
+		sortMachineTypes(lists[i], args.sort, args.reverse)
+	}
+	diff := diffMachineTypes(lists[0], lists[1])
+
+	if output.HasFlag() && !csvOutput {
+		result := map[string]interface{}{
+			"regions": []string{region, other},
+		}
+		for key, machineTypes := range map[string]ocm.MachineTypeList{
+			"only_in_region":      diff.onlyInFirst,
+			"only_in_diff_region": diff.onlyInSecond,
+			"common":              diff.common,
+		} {
+			items := make([]*cmv1.MachineType, 0, len(machineTypes))
+			for _, machineType := range machineTypes {
+				items = append(items, machineType.MachineType)
+			}
+			var b bytes.Buffer
+			err := cmv1.MarshalMachineTypeList(items, &b)
+			if err != nil {
+				return err
+			}
+			result[key] = json.RawMessage(b.Bytes())
+		}
+		return output.Print(r.Writer, result)
+	}
+
+	selectedColumns = append(selectedColumns, presenceColumn(region, lists[0]), presenceColumn(other, lists[1]))
+	var rows ocm.MachineTypeList
+	rows = append(rows, diff.onlyInFirst...)
+	rows = append(rows, diff.onlyInSecond...)
+	rows = append(rows, diff.common...)
+	rows = limitResults(rows)
+	if csvOutput {
+		return writeCSV(r.Writer, selectedColumns, rows)
+	}
+	return writeTable(r.Writer, selectedColumns, rows)
+}
+
+// presenceColumn returns a column with the name of the region as header, telling if each instance
+// type is one of the given ones.
+func presenceColumn(region string, machineTypes ocm.MachineTypeList) column {
+	return column{
+		Name:   region,
+		Header: strings.ToUpper(region),
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			if machineTypes.Find(machineType.MachineType.ID()) != nil {
+				return "yes"
+			}
+			return "no"
+		}),
+	}
+}
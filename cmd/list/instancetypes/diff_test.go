@@ -0,0 +1,62 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Region diff", func() {
+	first := ocm.MachineTypeList{
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("m6g.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+	}
+	second := ocm.MachineTypeList{
+		buildMachineType("c5.xlarge", cmv1.MachineTypeCategoryComputeOptimized, 4, 8589934592),
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+	}
+
+	AfterEach(func() {
+		args.diffRegion = ""
+	})
+
+	It("splits the instance types by the regions they are available in", func() {
+		diff := diffMachineTypes(first, second)
+		Expect(diff.onlyInFirst.IDs()).To(Equal([]string{"m6g.xlarge"}))
+		Expect(diff.onlyInSecond.IDs()).To(Equal([]string{"c5.xlarge"}))
+		Expect(diff.common.IDs()).To(Equal([]string{"m5.xlarge", "r5.xlarge"}))
+	})
+
+	It("tells in which regions each instance type is available", func() {
+		selected, err := selectColumns([]string{"id"})
+		Expect(err).NotTo(HaveOccurred())
+		selected = append(selected, presenceColumn("us-east-1", first), presenceColumn("eu-west-1", second))
+		var b bytes.Buffer
+		Expect(writeTable(&b, selected, ocm.MachineTypeList{first[1], second[0], first[0]})).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"ID          US-EAST-1  EU-WEST-1  \n" +
+			"m6g.xlarge  yes        no\n" +
+			"c5.xlarge   no         yes\n" +
+			"m5.xlarge   yes        yes\n"))
+	})
+
+	It("rejects the flags that don't select the instance types of a single region", func() {
+		args.diffRegion = "eu-west-1"
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.Bool("all", false, "")
+		Expect(flags.Parse([]string{"--all"})).To(Succeed())
+		Expect(validateDiffRegionFlag(flags)).To(MatchError(
+			"The '--diff-region' flag can't be used together with '--all'"))
+
+		args.diffRegion = allRegionsValue
+		Expect(validateDiffRegionFlag(pflag.NewFlagSet("test", pflag.ContinueOnError))).To(MatchError(
+			"The '--diff-region' flag needs a single region"))
+	})
+})
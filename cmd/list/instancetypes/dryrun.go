@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/rosa/pkg/arguments"
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// plannedRequest is one of the requests to OCM or AWS that the command would make.
+type plannedRequest struct {
+	service  string
+	method   string
+	endpoint string
+	details  []string
+}
+
+// dryRun prints the requests that the command would make with the current flags, without making
+// any of them. The region is resolved only from the flags, the environment and the AWS profile.
+func dryRun(cmd *cobra.Command, w io.Writer) error {
+	region := ""
+	if !args.all {
+		err := arguments.ValidateProfile()
+		if err != nil {
+			return rosa.UsageError(err)
+		}
+		region, err = aws.GetRegion(arguments.GetRegion())
+		if err != nil {
+			return rosa.UsageError(fmt.Errorf("Error getting region: %w", err))
+		}
+		if region == allRegionsValue {
+			err = validateAllRegionsFlag(cmd.Flags())
+			if err != nil {
+				return err
+			}
+		}
+		if region == "" && args.regionPrefix == "" {
+			return rosa.UsageError(fmt.Errorf("Expected a valid AWS region. %s", aws.RegionSourcesHint))
+		}
+	}
+	return printPlan(w, planRequests(region, args.zones))
+}
+
+// planRequests returns the requests made to list the instance types of the given region and
+// availability zones, in the order they are made.
+func planRequests(region string, zones []string) []plannedRequest {
+	quota := []plannedRequest{
+		{"OCM", "GET", "/api/accounts_mgmt/v1/current_account", nil},
+		{"OCM", "GET", "/api/accounts_mgmt/v1/organizations/{organization_id}/quota_cost",
+			[]string{"search=quota_id~='gpu'"}},
+	}
+	machineTypes := plannedRequest{"OCM", "GET", "/api/clusters_mgmt/v1/machine_types", nil}
+	if args.stream || args.jsonl {
+		return append(quota, machineTypes)
+	}
+	if args.all {
+		return append([]plannedRequest{machineTypes}, quota...)
+	}
+
+	var credentials []string
+	plan := []plannedRequest{
+		{"OCM", "GET", "/api/clusters_mgmt/v1/cloud_providers/aws/regions", nil},
+	}
+	if args.roleARN != "" {
+		credentials = append(credentials, "role_arn="+args.roleARN)
+		if args.externalID != "" {
+			credentials = append(credentials, "external_id="+args.externalID)
+		}
+	} else {
+		plan = append(plan,
+			plannedRequest{"AWS", "IAM", "DeleteAccessKey", []string{"user=" + aws.AdminUserName}},
+			plannedRequest{"AWS", "IAM", "CreateAccessKey", []string{"user=" + aws.AdminUserName}},
+		)
+		credentials = append(credentials, "access_key_id=<created for "+aws.AdminUserName+">")
+	}
+	plan = append(plan, plannedRequest{"OCM", "POST", "/api/clusters_mgmt/v1/aws_inquiries/regions",
+		credentials})
+
+	inquiry := func(details ...string) plannedRequest {
+		return plannedRequest{"OCM", "POST", "/api/clusters_mgmt/v1/aws_inquiries/machine_types",
+			append(details, credentials...)}
+	}
+	switch {
+	case region == allRegionsValue:
+		plan = append(plan, inquiry("region=<each region returned by the regions inquiry>"))
+	case region == "":
+		plan = append(plan, inquiry(fmt.Sprintf("region=<the region starting with '%s'>", args.regionPrefix)))
+	case len(zones) > 0:
+		plan = append(plan, plannedRequest{"AWS", "EC2", "DescribeAvailabilityZones",
+			[]string{"region=" + region}})
+		for _, zone := range zones {
+			plan = append(plan, inquiry("region="+region, "availability_zone="+zone))
+		}
+	default:
+		plan = append(plan, inquiry("region="+region))
+	}
+	return append(plan, quota...)
+}
+
+// printPlan writes the planned requests to w, one per line. The padding left by the requests
+// without details is removed so that the output is easy to compare.
+func printPlan(w io.Writer, plan []plannedRequest) error {
+	var b bytes.Buffer
+	writer := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, request := range plan {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", request.service, request.method, request.endpoint,
+			strings.Join(request.details, " "))
+	}
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "Dry run, no data was fetched. These are the requests that would be made:")
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dry run", func() {
+	AfterEach(func() {
+		args.roleARN = ""
+		args.externalID = ""
+		args.all = false
+	})
+
+	It("prints the requests for the availability zones of a region with a role", func() {
+		args.roleARN = "arn:aws:iam::123456789012:role/Installer"
+		args.externalID = "8f4a2c1e"
+
+		var b bytes.Buffer
+		Expect(printPlan(&b, planRequests("us-east-1", []string{"us-east-1a", "us-east-1b"}))).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"Dry run, no data was fetched. These are the requests that would be made:\n" +
+			"OCM  GET   /api/clusters_mgmt/v1/cloud_providers/aws/regions\n" +
+			"OCM  POST  /api/clusters_mgmt/v1/aws_inquiries/regions                       " +
+			"role_arn=arn:aws:iam::123456789012:role/Installer external_id=8f4a2c1e\n" +
+			"AWS  EC2   DescribeAvailabilityZones                                         region=us-east-1\n" +
+			"OCM  POST  /api/clusters_mgmt/v1/aws_inquiries/machine_types                 " +
+			"region=us-east-1 availability_zone=us-east-1a role_arn=arn:aws:iam::123456789012:role/Installer " +
+			"external_id=8f4a2c1e\n" +
+			"OCM  POST  /api/clusters_mgmt/v1/aws_inquiries/machine_types                 " +
+			"region=us-east-1 availability_zone=us-east-1b role_arn=arn:aws:iam::123456789012:role/Installer " +
+			"external_id=8f4a2c1e\n" +
+			"OCM  GET   /api/accounts_mgmt/v1/current_account\n" +
+			"OCM  GET   /api/accounts_mgmt/v1/organizations/{organization_id}/quota_cost  search=quota_id~='gpu'\n"))
+	})
+
+	It("creates access keys for the admin user when there is no role", func() {
+		plan := planRequests("eu-west-1", nil)
+		Expect(plan).To(HaveLen(7))
+		Expect(plan[1]).To(Equal(plannedRequest{"AWS", "IAM", "DeleteAccessKey", []string{"user=osdCcsAdmin"}}))
+		Expect(plan[2]).To(Equal(plannedRequest{"AWS", "IAM", "CreateAccessKey", []string{"user=osdCcsAdmin"}}))
+		Expect(plan[4].endpoint).To(Equal("/api/clusters_mgmt/v1/aws_inquiries/machine_types"))
+		Expect(plan[4].details).To(Equal([]string{"region=eu-west-1",
+			"access_key_id=<created for osdCcsAdmin>"}))
+	})
+
+	It("lists all the instance types without any AWS request", func() {
+		args.all = true
+		plan := planRequests("", nil)
+		Expect(plan).To(HaveLen(3))
+		Expect(plan[0].endpoint).To(Equal("/api/clusters_mgmt/v1/machine_types"))
+		for _, request := range plan {
+			Expect(request.service).To(Equal("OCM"))
+		}
+	})
+})
@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/output"
+)
+
+// extraFields are the fields that some flags add to the JSON output of the instance types.
+var extraFields = []output.Field{
+	{Path: "availability_zones", Type: "array, with '--availability-zones'"},
+	{Path: "availability_zones[]", Type: "string, with '--availability-zones'"},
+	{Path: "deprecated", Type: "boolean, with '--show-deprecated'"},
+	{Path: "max_pods", Type: "number, with '--show-max-pods'"},
+	{Path: "quota", Type: "number or null, with '--show-quota'"},
+}
+
+// sampleMachineType returns a machine type with all the fields that OCM sets, so that the fields of
+// the JSON output can be described without fetching the instance types.
+func sampleMachineType() (*cmv1.MachineType, error) {
+	return cmv1.NewMachineType().
+		ID("m5.xlarge").
+		HREF("/api/clusters_mgmt/v1/machine_types/m5.xlarge").
+		Name("m5.xlarge - General Purpose").
+		GenericName("standard-4").
+		Category(cmv1.MachineTypeCategoryGeneralPurpose).
+		Size(cmv1.MachineTypeSizeSmall).
+		CCSOnly(false).
+		CPU(cmv1.NewValue().Value(4).Unit("vCPU")).
+		Memory(cmv1.NewValue().Value(17179869184).Unit("B")).
+		CloudProvider(cmv1.NewCloudProvider().
+			Link(true).
+			ID("aws").
+			HREF("/api/clusters_mgmt/v1/cloud_providers/aws")).
+		Build()
+}
+
+// explain writes to w the fields of the JSON output of each instance type and their types.
+func explain(w io.Writer) error {
+	sample, err := sampleMachineType()
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	err = cmv1.MarshalMachineType(sample, &b)
+	if err != nil {
+		return err
+	}
+	fields, err := output.Fields(b.Bytes())
+	if err != nil {
+		return err
+	}
+	fields = append(fields, extraFields...)
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return output.PrintFields(w, fields)
+}
@@ -0,0 +1,40 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Explain", func() {
+	It("describes the fields of the JSON output", func() {
+		var b bytes.Buffer
+		Expect(explain(&b)).To(Succeed())
+		Expect(b.String()).To(Equal(`FIELD                 TYPE
+availability_zones    array, with '--availability-zones'
+availability_zones[]  string, with '--availability-zones'
+category              string
+ccs_only              boolean
+cloud_provider        object
+cloud_provider.href   string
+cloud_provider.id     string
+cloud_provider.kind   string
+cpu                   object
+cpu.unit              string
+cpu.value             number
+deprecated            boolean, with '--show-deprecated'
+generic_name          string
+href                  string
+id                    string
+kind                  string
+max_pods              number, with '--show-max-pods'
+memory                object
+memory.unit           string
+memory.value          number
+name                  string
+quota                 number or null, with '--show-quota'
+size                  string
+`))
+	})
+})
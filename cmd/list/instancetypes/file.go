@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+)
+
+// fileFormatConflictingFlags are the flags that don't produce the list of instance types that the
+// '--format' flag writes to the output file.
+var fileFormatConflictingFlags = []string{"stream", "jsonl", "count", "group-by", "diff-region", "validate-quota",
+	"raw", "explain", "dry-run"}
+
+func validateFileFormatFlag(flags *pflag.FlagSet) error {
+	if output.FileFormat() == "" {
+		return nil
+	}
+	for _, name := range fileFormatConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--format' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// jsonResource returns what the JSON and YAML outputs print for the machine types: the machine types
+// of OCM, or their fields together with the extra ones requested in the command line.
+func jsonResource(machineTypes ocm.MachineTypeList, zones bool) (interface{}, error) {
+	if zones || args.showQuota || args.showDeprecated || args.includeUnavailable || args.showMaxPods {
+		return withExtraFields(machineTypes, zones, args.showQuota, args.showDeprecated, args.includeUnavailable,
+			args.showMaxPods)
+	}
+	var instanceTypes []*cmv1.MachineType
+	for _, machine := range machineTypes {
+		instanceTypes = append(instanceTypes, machine.MachineType)
+	}
+	return instanceTypes, nil
+}
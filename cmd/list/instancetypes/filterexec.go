@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// defaultFilterExecTimeout is how long the program given with the '--filter-exec' flag can run when
+// the '--filter-exec-timeout' flag isn't given.
+const defaultFilterExecTimeout = 30 * time.Second
+
+// filterExecConflictingFlags are the flags that don't produce a single list of instance types that
+// the program could filter.
+var filterExecConflictingFlags = []string{"stream", "jsonl", "diff-region", "validate-quota", "raw", "explain",
+	"dry-run"}
+
+func validateFilterExecFlags(flags *pflag.FlagSet) error {
+	if args.filterExec == "" {
+		if flags.Changed("filter-exec-timeout") {
+			return fmt.Errorf("The '--filter-exec-timeout' flag can only be used together with '--filter-exec'")
+		}
+		return nil
+	}
+	if args.filterExecTimeout <= 0 {
+		return fmt.Errorf("The value of the '--filter-exec-timeout' flag must be greater than zero")
+	}
+	for _, name := range filterExecConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--filter-exec' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// filterExecItem is the part of each instance type printed by the filter program that is used, the
+// rest of the fields are ignored.
+type filterExecItem struct {
+	ID string `json:"id"`
+}
+
+// applyFilterExec runs the program given with the '--filter-exec' flag, writing the JSON list of the
+// machine types to its standard input, and keeps the machine types of the JSON list that it writes
+// to its standard output. The program only selects machine types, so the ones it writes must be
+// some of the ones it was given.
+func applyFilterExec(r *rosa.Runtime, machineTypes ocm.MachineTypeList) (ocm.MachineTypeList, error) {
+	if args.filterExec == "" {
+		return machineTypes, nil
+	}
+	var input bytes.Buffer
+	list := make([]*cmv1.MachineType, len(machineTypes))
+	for i, machineType := range machineTypes {
+		list[i] = machineType.MachineType
+	}
+	err := cmv1.MarshalMachineTypeList(list, &input)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runFilterExec(args.filterExec, args.filterExecTimeout, input.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	var items []filterExecItem
+	err = json.Unmarshal(out, &items)
+	if err != nil {
+		return nil, fmt.Errorf("The filter '%s' didn't write a JSON list of instance types: %v", args.filterExec, err)
+	}
+	var filtered ocm.MachineTypeList
+	for _, item := range items {
+		machineType := machineTypes.Find(item.ID)
+		if machineType == nil {
+			return nil, fmt.Errorf("The filter '%s' wrote instance type '%s', which isn't one of the ones it was "+
+				"given", args.filterExec, item.ID)
+		}
+		filtered = append(filtered, machineType)
+	}
+	r.Reporter.Debugf("The filter '%s' kept %d of %d instance types", args.filterExec, len(filtered),
+		len(machineTypes))
+	return filtered, nil
+}
+
+// runFilterExec runs the program with the given input, and returns what it writes to its standard
+// output. The program is killed, with the processes that it started, if it doesn't finish before
+// the timeout.
+func runFilterExec(path string, timeout time.Duration, input []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.Command(path)
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	startFilterExecGroup(command)
+	err := command.Start()
+	if err != nil {
+		return nil, fmt.Errorf("The filter '%s' failed: %v", path, err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Wait()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err = <-done:
+	case <-timer.C:
+		// Waiting could still block if a process that wasn't killed keeps the output open, so the
+		// result of the wait is dropped:
+		_ = killFilterExec(command)
+		return nil, fmt.Errorf("The filter '%s' didn't finish in %s", path, timeout)
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message != "" {
+			return nil, fmt.Errorf("The filter '%s' failed: %v: %s", path, err, message)
+		}
+		return nil, fmt.Errorf("The filter '%s' failed: %v", path, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,145 @@
+package instancetypes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Filter program", func() {
+	var r *rosa.Runtime
+	var machineTypes ocm.MachineTypeList
+	var dir string
+
+	// script writes a shell script that runs the given commands and returns its path:
+	script := func(name string, commands string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte("#!/bin/sh\n"+commands+"\n"), 0700)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		r = &rosa.Runtime{}
+		r.Reporter, err = reporter.New().Stream(&bytes.Buffer{}).Build()
+		Expect(err).NotTo(HaveOccurred())
+		dir = GinkgoT().TempDir()
+		args.filterExecTimeout = defaultFilterExecTimeout
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+			buildMachineType("c5.xlarge", cmv1.MachineTypeCategoryComputeOptimized, 4, 8589934592),
+		}
+		machineTypes[1].AvailabilityZones = []string{"us-east-1a"}
+	})
+
+	AfterEach(func() {
+		args.filterExec = ""
+		args.filterExecTimeout = defaultFilterExecTimeout
+	})
+
+	It("doesn't filter without the flag", func() {
+		filtered, err := applyFilterExec(r, machineTypes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge", "r5.xlarge", "c5.xlarge"}))
+	})
+
+	It("keeps the instance types written by the program, in its order", func() {
+		input := filepath.Join(dir, "input.json")
+		args.filterExec = script("filter.sh", "cat > "+input+"\n"+
+			`echo '[{"id": "c5.xlarge"}, {"kind": "MachineType", "id": "r5.xlarge"}]'`)
+		filtered, err := applyFilterExec(r, machineTypes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered.IDs()).To(Equal([]string{"c5.xlarge", "r5.xlarge"}))
+		Expect(filtered[1].AvailabilityZones).To(Equal([]string{"us-east-1a"}))
+
+		// The program reads the list marshalled by the SDK:
+		content, err := os.ReadFile(input)
+		Expect(err).NotTo(HaveOccurred())
+		list, err := cmv1.UnmarshalMachineTypeList(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(3))
+		Expect(list[0].ID()).To(Equal("m5.xlarge"))
+	})
+
+	It("keeps nothing when the program writes an empty list", func() {
+		args.filterExec = script("filter.sh", "cat > /dev/null\necho '[]'")
+		filtered, err := applyFilterExec(r, machineTypes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered).To(BeEmpty())
+	})
+
+	It("rejects output that isn't a JSON list", func() {
+		args.filterExec = script("filter.sh", "cat > /dev/null\necho 'm5.xlarge'")
+		_, err := applyFilterExec(r, machineTypes)
+		Expect(err).To(MatchError(HavePrefix(
+			"The filter '" + args.filterExec + "' didn't write a JSON list of instance types: ")))
+	})
+
+	It("rejects instance types that it wasn't given", func() {
+		args.filterExec = script("filter.sh", `cat > /dev/null; echo '[{"id": "x1.32xlarge"}]'`)
+		_, err := applyFilterExec(r, machineTypes)
+		Expect(err).To(MatchError("The filter '" + args.filterExec + "' wrote instance type 'x1.32xlarge', " +
+			"which isn't one of the ones it was given"))
+	})
+
+	It("reports the errors of the program", func() {
+		args.filterExec = script("filter.sh", "cat > /dev/null\necho 'policy not found' >&2\nexit 3")
+		_, err := applyFilterExec(r, machineTypes)
+		Expect(err).To(MatchError("The filter '" + args.filterExec + "' failed: exit status 3: policy not found"))
+	})
+
+	It("stops the program after the timeout", func() {
+		args.filterExec = script("filter.sh", "exec sleep 10")
+		args.filterExecTimeout = 100 * time.Millisecond
+		start := time.Now()
+		_, err := applyFilterExec(r, machineTypes)
+		Expect(err).To(MatchError("The filter '" + args.filterExec + "' didn't finish in 100ms"))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("stops the processes started by the program after the timeout", func() {
+		args.filterExec = script("filter.sh", "sleep 5 | cat")
+		args.filterExecTimeout = 500 * time.Millisecond
+		start := time.Now()
+		_, err := applyFilterExec(r, machineTypes)
+		Expect(err).To(MatchError("The filter '" + args.filterExec + "' didn't finish in 500ms"))
+		Expect(time.Since(start)).To(BeNumerically("<", 3*time.Second))
+	})
+
+	DescribeTable("validateFilterExecFlags",
+		func(argv []string, expectedError string) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringVar(&args.filterExec, "filter-exec", "", "")
+			flags.DurationVar(&args.filterExecTimeout, "filter-exec-timeout", defaultFilterExecTimeout, "")
+			flags.Bool("stream", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+
+			err := validateFilterExecFlags(flags)
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedError))
+			}
+		},
+		Entry("without the flag", []string{}, ""),
+		Entry("with the flag", []string{"--filter-exec", "./filter.sh"}, ""),
+		Entry("with a timeout", []string{"--filter-exec", "./filter.sh", "--filter-exec-timeout", "5s"}, ""),
+		Entry("timeout without the flag", []string{"--filter-exec-timeout", "5s"},
+			"The '--filter-exec-timeout' flag can only be used together with '--filter-exec'"),
+		Entry("zero timeout", []string{"--filter-exec", "./filter.sh", "--filter-exec-timeout", "0s"},
+			"The value of the '--filter-exec-timeout' flag must be greater than zero"),
+		Entry("with streaming", []string{"--filter-exec", "./filter.sh", "--stream"},
+			"The '--filter-exec' flag can't be used together with '--stream'"),
+	)
+})
@@ -0,0 +1,33 @@
+//go:build windows
+
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"os/exec"
+)
+
+// startFilterExecGroup does nothing, as Windows doesn't have process groups that can be killed
+// together.
+func startFilterExecGroup(command *exec.Cmd) {
+}
+
+// killFilterExec kills the filter program. The processes that it started keep running.
+func killFilterExec(command *exec.Cmd) error {
+	return command.Process.Kill()
+}
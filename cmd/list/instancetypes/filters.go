@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// applyFilters keeps only the machine types that match the given categories, the '--min-cpu',
+// '--family', '--min-generation', '--architecture', '--exclude' and '--show-deprecated' flags, the
+// minimum memory and the GPU filter.
+func applyFilters(r *rosa.Runtime, machineTypes ocm.MachineTypeList, categories []string, minMemory uint64,
+	gpu *bool) (ocm.MachineTypeList, error) {
+	return instancetypes.Select(machineTypes, filterCriteria(categories, minMemory, gpu), r.Reporter.Debugf)
+}
+
+// filterCriteria returns the criteria given by the flags of the command, with the given categories,
+// minimum memory and GPU filter.
+func filterCriteria(categories []string, minMemory uint64, gpu *bool) instancetypes.Criteria {
+	return instancetypes.Criteria{
+		Categories:         categories,
+		MinCPU:             args.minCPU,
+		MinMemory:          minMemory,
+		Family:             args.family,
+		MinGeneration:      args.minGeneration,
+		IncludeUnparseable: args.includeUnparseable,
+		Architecture:       args.architecture,
+		GPU:                gpu,
+		Exclude:            args.exclude,
+		ShowDeprecated:     args.showDeprecated,
+	}
+}
+
+// gpuFilter returns the value of the '--gpu' flag, or nil if it wasn't given, in which case the
+// instance types aren't filtered by their accelerators.
+func gpuFilter(flags *pflag.FlagSet) *bool {
+	if !flags.Changed("gpu") {
+		return nil
+	}
+	gpu := args.gpu
+	return &gpu
+}
@@ -0,0 +1,64 @@
+package instancetypes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Filters", func() {
+	It("Marks the deprecated families in their column", func() {
+		machineTypes := ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m4.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		}
+		selected, err := selectColumns([]string{"id", deprecatedColumn})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).Row(machineTypes[0])).To(Equal("m5.xlarge\tno\n"))
+		Expect(newTable(selected).Row(machineTypes[1])).To(Equal("m4.xlarge\tyes\n"))
+	})
+
+	It("Takes the criteria from the flags", func() {
+		args.minCPU = 16
+		args.exclude = []string{"m5.*"}
+		defer func() {
+			args.minCPU = 0
+			args.exclude = nil
+		}()
+		Expect(filterCriteria([]string{"general_purpose"}, 1024, nil)).To(Equal(instancetypes.Criteria{
+			Categories: []string{"general_purpose"},
+			MinCPU:     16,
+			MinMemory:  1024,
+			Exclude:    []string{"m5.*"},
+		}))
+	})
+})
+
+var _ = Describe("GPU filter", func() {
+	machineTypes := ocm.MachineTypeList{
+		buildMachineType("g4dn.xlarge", cmv1.MachineTypeCategoryAcceleratedComputing, 4, 17179869184),
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+	}
+
+	DescribeTable("gpuFilter",
+		func(argv []string, expected []string) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.BoolVar(&args.gpu, "gpu", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			defer func() {
+				args.gpu = false
+			}()
+
+			filtered, err := instancetypes.Select(machineTypes, instancetypes.Criteria{GPU: gpuFilter(flags)}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filtered.IDs()).To(Equal(expected))
+		},
+		Entry("not given", nil, []string{"g4dn.xlarge", "m5.xlarge"}),
+		Entry("only GPUs", []string{"--gpu"}, []string{"g4dn.xlarge"}),
+		Entry("without GPUs", []string{"--gpu=false"}, []string{"m5.xlarge"}),
+	)
+})
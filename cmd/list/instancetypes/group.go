@@ -0,0 +1,210 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/helper"
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+)
+
+// groupKeys are the values accepted by the '--group-by' flag.
+var groupKeys = []string{"category", "family", "architecture"}
+
+// groupByConflictingFlags are the flags that only make sense when the individual instance types are
+// listed.
+var groupByConflictingFlags = []string{"columns", "wide", "stream", "jsonl", "count", "max-results", "head", "tail",
+	"diff-region", "validate-quota", "raw"}
+
+func validateGroupByFlag(flags *pflag.FlagSet) error {
+	if args.groupBy == "" {
+		return nil
+	}
+	if !helper.Contains(groupKeys, args.groupBy) {
+		return fmt.Errorf("Invalid group '%s'. Allowed values are %s", args.groupBy, groupKeys)
+	}
+	for _, name := range groupByConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--group-by' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// machineTypeGroup summarizes the machine types that have the same value of the grouping key.
+type machineTypeGroup struct {
+	name      string
+	count     int
+	minCPU    *ocm.MachineType
+	maxCPU    *ocm.MachineType
+	minMemory *ocm.MachineType
+	maxMemory *ocm.MachineType
+}
+
+// groupKey returns the value of the given grouping key for the machine type.
+func groupKey(machineType *ocm.MachineType, key string) string {
+	switch key {
+	case "family":
+		return machineType.Family()
+	case "architecture":
+		return machineType.Architecture()
+	default:
+		return string(machineType.MachineType.Category())
+	}
+}
+
+// groupMachineTypes returns the groups of the machine types by the given key, sorted by name.
+func groupMachineTypes(machineTypes ocm.MachineTypeList, key string) []*machineTypeGroup {
+	byName := map[string]*machineTypeGroup{}
+	var groups []*machineTypeGroup
+	for _, machineType := range machineTypes {
+		name := groupKey(machineType, key)
+		group, ok := byName[name]
+		if !ok {
+			group = &machineTypeGroup{
+				name:      name,
+				minCPU:    machineType,
+				maxCPU:    machineType,
+				minMemory: machineType,
+				maxMemory: machineType,
+			}
+			byName[name] = group
+			groups = append(groups, group)
+		}
+		group.count++
+		cpu := machineType.MachineType.CPU().Value()
+		if cpu < group.minCPU.MachineType.CPU().Value() {
+			group.minCPU = machineType
+		}
+		if cpu > group.maxCPU.MachineType.CPU().Value() {
+			group.maxCPU = machineType
+		}
+		memory := instancetypes.MemoryBytes(machineType.MachineType)
+		if memory < instancetypes.MemoryBytes(group.minMemory.MachineType) {
+			group.minMemory = machineType
+		}
+		if memory > instancetypes.MemoryBytes(group.maxMemory.MachineType) {
+			group.maxMemory = machineType
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].name < groups[j].name
+	})
+	return groups
+}
+
+// valueRange formats the minimum and maximum values, or a single value when both are the same.
+func valueRange(min string, max string) string {
+	if min == max {
+		return min
+	}
+	return min + " - " + max
+}
+
+func cpuCores(machineType *ocm.MachineType) string {
+	return formatInteger(int(machineType.MachineType.CPU().Value()))
+}
+
+func groupValue(value func(group *machineTypeGroup) string) func(item interface{}) string {
+	return func(item interface{}) string {
+		return value(item.(*machineTypeGroup))
+	}
+}
+
+// groupTable returns the table of the groups, with the grouping key as the header of the first
+// column.
+func groupTable(key string) *output.Table {
+	return &output.Table{
+		Columns: []output.Column{
+			{
+				Name:   key,
+				Header: strings.ToUpper(key),
+				Value: groupValue(func(group *machineTypeGroup) string {
+					return group.name
+				}),
+			},
+			{
+				Name:   "count",
+				Header: "COUNT",
+				Value: groupValue(func(group *machineTypeGroup) string {
+					return strconv.Itoa(group.count)
+				}),
+			},
+			{
+				Name:   "cpu",
+				Header: "CPU_CORES",
+				Value: groupValue(func(group *machineTypeGroup) string {
+					return valueRange(cpuCores(group.minCPU), cpuCores(group.maxCPU))
+				}),
+			},
+			{
+				Name:   "memory",
+				Header: "MEMORY",
+				Value: groupValue(func(group *machineTypeGroup) string {
+					return valueRange(formatMemory(group.minMemory.MachineType),
+						formatMemory(group.maxMemory.MachineType))
+				}),
+				CSVHeader: "MEMORY_BYTES",
+				CSVValue: groupValue(func(group *machineTypeGroup) string {
+					return valueRange(
+						strconv.FormatFloat(instancetypes.MemoryBytes(group.minMemory.MachineType), 'f', 0, 64),
+						strconv.FormatFloat(instancetypes.MemoryBytes(group.maxMemory.MachineType), 'f', 0, 64))
+				}),
+			},
+		},
+		NoHeaders: args.noHeaders,
+	}
+}
+
+// groupSummaries returns the JSON representation of the groups.
+func groupSummaries(groups []*machineTypeGroup, key string) []map[string]interface{} {
+	summaries := make([]map[string]interface{}, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, map[string]interface{}{
+			key:                group.name,
+			"count":            group.count,
+			"min_cpu_cores":    int(group.minCPU.MachineType.CPU().Value()),
+			"max_cpu_cores":    int(group.maxCPU.MachineType.CPU().Value()),
+			"min_memory_bytes": int64(instancetypes.MemoryBytes(group.minMemory.MachineType)),
+			"max_memory_bytes": int64(instancetypes.MemoryBytes(group.maxMemory.MachineType)),
+		})
+	}
+	return summaries
+}
+
+// printGroups prints the groups of the machine types instead of the machine types themselves.
+func printGroups(w io.Writer, machineTypes ocm.MachineTypeList, key string, csvOutput bool) error {
+	groups := groupMachineTypes(machineTypes, key)
+	if output.HasFlag() && !csvOutput {
+		return output.Print(w, groupSummaries(groups, key))
+	}
+	table := groupTable(key)
+	if csvOutput {
+		return table.WriteCSV(w, groups)
+	}
+	return table.Write(w, groups)
+}
@@ -0,0 +1,83 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Group by", func() {
+	var machineTypes ocm.MachineTypeList
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("r5.2xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 8, 68719476736),
+			buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+			buildMachineType("m5.2xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 8, 34359738368),
+		}
+	})
+
+	AfterEach(func() {
+		args.groupBy = ""
+	})
+
+	Describe("validateGroupByFlag", func() {
+		newFlags := func(argv ...string) *pflag.FlagSet {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("group-by", "", "")
+			flags.Bool("count", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			return flags
+		}
+
+		It("rejects an unknown group", func() {
+			args.groupBy = "size"
+			err := validateGroupByFlag(newFlags())
+			Expect(err).To(MatchError("Invalid group 'size'. Allowed values are [category family architecture]"))
+		})
+
+		It("rejects the flags that list the individual instance types", func() {
+			args.groupBy = "family"
+			err := validateGroupByFlag(newFlags("--count"))
+			Expect(err).To(MatchError("The '--group-by' flag can't be used together with '--count'"))
+		})
+	})
+
+	It("prints the number of instance types and the ranges of each group", func() {
+		var b bytes.Buffer
+		Expect(printGroups(&b, machineTypes, "category", false)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"CATEGORY          COUNT  CPU_CORES  MEMORY  \n" +
+			"general_purpose   3      4 - 16     16.0 GiB - 64.0 GiB\n" +
+			"memory_optimized  1      8          64.0 GiB\n"))
+	})
+
+	It("writes CSV with the memory in bytes", func() {
+		var b bytes.Buffer
+		Expect(printGroups(&b, machineTypes, "family", true)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"FAMILY,COUNT,CPU_CORES,MEMORY_BYTES\n" +
+			"m5,3,4 - 16,17179869184 - 68719476736\n" +
+			"r5,1,8,68719476736\n"))
+	})
+
+	It("summarizes the groups for the JSON output", func() {
+		Expect(groupSummaries(groupMachineTypes(machineTypes, "architecture"), "architecture")).To(Equal(
+			[]map[string]interface{}{
+				{
+					"architecture":     "x86_64",
+					"count":            4,
+					"min_cpu_cores":    4,
+					"max_cpu_cores":    16,
+					"min_memory_bytes": int64(17179869184),
+					"max_memory_bytes": int64(68719476736),
+				},
+			}))
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+// limitFlags are the flags that select how many of the sorted and filtered instance types are listed,
+// only one of them can be given.
+var limitFlags = []string{"max-results", "head", "tail"}
+
+func validateLimitFlags(flags *pflag.FlagSet) error {
+	if args.maxResults < 0 {
+		return fmt.Errorf("Invalid maximum number of results %d. It must be zero or greater", args.maxResults)
+	}
+	given := ""
+	for _, name := range limitFlags {
+		flag := flags.Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if given != "" {
+			return fmt.Errorf("The '--%s' flag can't be used together with '--%s'", name, given)
+		}
+		given = name
+	}
+	if flags.Changed("head") && args.head < 1 {
+		return fmt.Errorf("Invalid number of rows %d for '--head'. It must be greater than zero", args.head)
+	}
+	if flags.Changed("tail") && args.tail < 1 {
+		return fmt.Errorf("Invalid number of rows %d for '--tail'. It must be greater than zero", args.tail)
+	}
+	return nil
+}
+
+// limited returns true if only some of the instance types are listed, so the list can't be written
+// before all of them are known.
+func limited() bool {
+	return args.maxResults > 0 || args.head > 0 || args.tail > 0
+}
+
+// limitResults returns the machine types selected with the '--max-results', '--head' or '--tail'
+// flag, or all of them when none was given.
+func limitResults(machineTypes ocm.MachineTypeList) ocm.MachineTypeList {
+	if args.tail > 0 {
+		return tailResults(machineTypes, args.tail)
+	}
+	if args.head > 0 {
+		return truncateResults(machineTypes, args.head)
+	}
+	return truncateResults(machineTypes, args.maxResults)
+}
+
+// tailResults returns the last n machine types, or all of them when there aren't more.
+func tailResults(machineTypes ocm.MachineTypeList, n int) ocm.MachineTypeList {
+	if len(machineTypes) > n {
+		return machineTypes[len(machineTypes)-n:]
+	}
+	return machineTypes
+}
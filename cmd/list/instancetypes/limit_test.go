@@ -0,0 +1,117 @@
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Head and tail", func() {
+	// Sorted by CPU, as with '--sort cpu':
+	machineTypes := ocm.MachineTypeList{
+		buildMachineType("m5.large", cmv1.MachineTypeCategoryGeneralPurpose, 2, 8589934592),
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("m5.2xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 8, 34359738368),
+		buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+	}
+
+	AfterEach(func() {
+		args.head = 0
+		args.tail = 0
+		args.maxResults = 0
+	})
+
+	DescribeTable("limitResults",
+		func(head int, tail int, expected []string) {
+			args.head = head
+			args.tail = tail
+			limited := limitResults(machineTypes)
+			Expect(limited.IDs()).To(Equal(expected))
+		},
+		Entry("neither", 0, 0, []string{"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge"}),
+		Entry("first one", 1, 0, []string{"m5.large"}),
+		Entry("first all but one", 3, 0, []string{"m5.large", "m5.xlarge", "m5.2xlarge"}),
+		Entry("first as many as there are", 4, 0, []string{"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge"}),
+		Entry("first more than there are", 10, 0, []string{"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge"}),
+		Entry("last one", 0, 1, []string{"m5.4xlarge"}),
+		Entry("last all but one", 0, 3, []string{"m5.xlarge", "m5.2xlarge", "m5.4xlarge"}),
+		Entry("last as many as there are", 0, 4, []string{"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge"}),
+		Entry("last more than there are", 0, 10, []string{"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge"}),
+	)
+
+	It("returns nothing when there is nothing to list", func() {
+		args.tail = 3
+		limited := limitResults(ocm.MachineTypeList{})
+		Expect(limited).To(BeEmpty())
+	})
+
+	It("slices the JSON array", func() {
+		Expect(Cmd.Flags().Set("output", "json")).To(Succeed())
+		defer func() {
+			Expect(Cmd.Flags().Set("output", "")).To(Succeed())
+		}()
+		args.tail = 2
+		resource, err := jsonResource(limitResults(machineTypes), false)
+		Expect(err).NotTo(HaveOccurred())
+		var b bytes.Buffer
+		Expect(output.Print(&b, resource)).To(Succeed())
+
+		var items []map[string]interface{}
+		Expect(json.Unmarshal(b.Bytes(), &items)).To(Succeed())
+		Expect(items).To(HaveLen(2))
+		Expect(items[0]).To(HaveKeyWithValue("id", "m5.2xlarge"))
+		Expect(items[1]).To(HaveKeyWithValue("id", "m5.4xlarge"))
+	})
+
+	It("reports the note about '--max-results' through the reporter", func() {
+		args.maxResults = 2
+		var out, errOut bytes.Buffer
+		rep, err := reporter.New().Stream(&errOut).Build()
+		Expect(err).NotTo(HaveOccurred())
+		selected, err := selectColumns([]string{"id"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(printTable(&rosa.Runtime{Reporter: rep}, &out, selected, machineTypes, nil, nil, false)).To(Succeed())
+		Expect(out.String()).NotTo(ContainSubstring("Showing"))
+		Expect(errOut.String()).To(Equal(
+			"INFO: Showing 2 of 4 instance types, use '--max-results 0' to list all of them\n"))
+	})
+
+	DescribeTable("validateLimitFlags",
+		func(argv []string, expectedError string) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.IntVar(&args.maxResults, "max-results", 0, "")
+			flags.IntVar(&args.head, "head", 0, "")
+			flags.IntVar(&args.tail, "tail", 0, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+
+			err := validateLimitFlags(flags)
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedError))
+			}
+		},
+		Entry("none", []string{}, ""),
+		Entry("head", []string{"--head", "3"}, ""),
+		Entry("tail", []string{"--tail", "3"}, ""),
+		Entry("zero head", []string{"--head", "0"},
+			"Invalid number of rows 0 for '--head'. It must be greater than zero"),
+		Entry("negative tail", []string{"--tail", "-1"},
+			"Invalid number of rows -1 for '--tail'. It must be greater than zero"),
+		Entry("head and tail", []string{"--head", "3", "--tail", "3"},
+			"The '--tail' flag can't be used together with '--head'"),
+		Entry("maximum and head", []string{"--max-results", "5", "--head", "3"},
+			"The '--head' flag can't be used together with '--max-results'"),
+		Entry("negative maximum", []string{"--max-results", "-1"},
+			"Invalid maximum number of results -1. It must be zero or greater"),
+	)
+})
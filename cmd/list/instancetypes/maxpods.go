@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"strconv"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+// maxPodsColumn is added to the selected columns when '--show-max-pods' is given.
+const maxPodsColumn = "max-pods"
+
+// kubeletMaxPods is the default maximum number of pods that the kubelet of an OpenShift node runs,
+// whatever the size of the instance.
+const kubeletMaxPods = 250
+
+// maxPods returns the number of pods that a node of the machine type can run with OVN-Kubernetes,
+// the default CNI of ROSA. The pods get their addresses from the subnet of the cluster network
+// assigned to the node, not from the network interfaces of the instance, so the instance type
+// doesn't limit them. The default '/23' subnet of a node has room for 510 pods, so the bound is the
+// maximum number of pods of the kubelet. A cluster created with a host prefix longer than 23 may
+// run fewer.
+func maxPods(_ *ocm.MachineType) int {
+	return kubeletMaxPods
+}
+
+// maxPodsValue returns the value of the MAX_PODS column.
+func maxPodsValue(machineType *ocm.MachineType) string {
+	return strconv.Itoa(maxPods(machineType))
+}
@@ -0,0 +1,32 @@
+package instancetypes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Max pods", func() {
+	DescribeTable("maxPodsValue",
+		func(id string, expected string) {
+			machineType := buildMachineType(id, cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184)
+			Expect(maxPodsValue(machineType)).To(Equal(expected))
+		},
+		Entry("small instance", "t3.medium", "250"),
+		Entry("large instance", "m5.24xlarge", "250"),
+		Entry("any family", "x1.32xlarge", "250"),
+	)
+
+	It("adds the bound to the JSON representation", func() {
+		list := ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("x1.32xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 128, 2095944040448),
+		}
+		items, err := withExtraFields(list, false, false, false, false, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items[0].Get("max_pods")).To(Equal(250))
+		Expect(items[1].Get("max_pods")).To(Equal(250))
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// defaultMinAZCount is the number of availability zones that the '--ensure-multi-az' flag requires
+// when '--min-az-count' isn't given, the number of zones of a multi-AZ cluster.
+const defaultMinAZCount = 3
+
+// multiAZConflictingFlags are the flags that don't fetch the instance types of each availability zone.
+var multiAZConflictingFlags = []string{"all", "stream", "jsonl", "diff-region", "validate-quota", "raw"}
+
+func validateMultiAZFlags(flags *pflag.FlagSet) error {
+	if !args.ensureMultiAZ {
+		if flags.Changed("min-az-count") {
+			return fmt.Errorf("The '--min-az-count' flag can only be used together with '--ensure-multi-az'")
+		}
+		return nil
+	}
+	if args.minAZCount < 1 {
+		return fmt.Errorf("Invalid minimum number of availability zones %d. It must be greater than zero",
+			args.minAZCount)
+	}
+	for _, name := range multiAZConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--ensure-multi-az' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// multiAZZones returns the availability zones to request: the given ones or, when '--ensure-multi-az'
+// needs the zones of each instance type and none were given, every zone of the region.
+func multiAZZones(zones []string) []string {
+	if args.ensureMultiAZ && len(zones) == 0 {
+		return []string{allZonesValue}
+	}
+	return zones
+}
+
+// checkMultiAZZones returns an error if fewer availability zones were selected than the ones that the
+// '--ensure-multi-az' flag requires, as then no instance type could pass the filter.
+func checkMultiAZZones(region string, zones []string) error {
+	if !args.ensureMultiAZ || len(zones) >= args.minAZCount {
+		return nil
+	}
+	return rosa.UsageError(fmt.Errorf("The '--ensure-multi-az' flag requires at least %d availability zones, "+
+		"but only %s of region '%s' were selected", args.minAZCount, zones, region))
+}
+
+// filterMultiAZ keeps only the machine types offered in at least minCount availability zones. The
+// zones of each machine type must have been fetched.
+func filterMultiAZ(r *rosa.Runtime, machineTypes ocm.MachineTypeList, minCount int) ocm.MachineTypeList {
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		if len(machineType.AvailabilityZones) >= minCount {
+			return true
+		}
+		r.Reporter.Debugf("Excluding instance type '%s', as it is only offered in availability zones %s",
+			machineType.MachineType.ID(), machineType.AvailabilityZones)
+		return false
+	})
+}
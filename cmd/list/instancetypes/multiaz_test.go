@@ -0,0 +1,109 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/debug"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Multi-AZ filter", func() {
+	var r *rosa.Runtime
+	var errOut bytes.Buffer
+	var debugFlags *pflag.FlagSet
+
+	BeforeEach(func() {
+		errOut.Reset()
+		var err error
+		r = &rosa.Runtime{}
+		r.Reporter, err = reporter.New().Stream(&errOut).Build()
+		Expect(err).NotTo(HaveOccurred())
+		debugFlags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		debug.AddFlag(debugFlags)
+		Expect(debugFlags.Set("debug", "true")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(debugFlags.Set("debug", "false")).To(Succeed())
+		args.ensureMultiAZ = false
+		args.minAZCount = defaultMinAZCount
+	})
+
+	// The zones are the ones recorded from the responses of each availability zone, m5.xlarge is offered
+	// in all of them and r5.xlarge only in two:
+	inZones := func(id string, zones ...string) *ocm.MachineType {
+		machineType := buildMachineType(id, cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184)
+		machineType.AvailabilityZones = zones
+		return machineType
+	}
+	machineTypes := func() ocm.MachineTypeList {
+		return ocm.MachineTypeList{
+			inZones("m5.xlarge", "us-east-1a", "us-east-1b", "us-east-1c"),
+			inZones("r5.xlarge", "us-east-1a", "us-east-1c"),
+			inZones("c5.xlarge", "us-east-1a", "us-east-1b", "us-east-1c", "us-east-1d"),
+		}
+	}
+
+	It("excludes the instance types offered in only two zones", func() {
+		filtered := filterMultiAZ(r, machineTypes(), defaultMinAZCount)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge", "c5.xlarge"}))
+		Expect(errOut.String()).To(Equal("INFO: Excluding instance type 'r5.xlarge', as it is only offered in " +
+			"availability zones [us-east-1a us-east-1c]\n"))
+	})
+
+	It("uses the minimum number of zones given", func() {
+		filtered := filterMultiAZ(r, machineTypes(), 2)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge", "r5.xlarge", "c5.xlarge"}))
+		filtered = filterMultiAZ(r, machineTypes(), 4)
+		Expect(filtered.IDs()).To(Equal([]string{"c5.xlarge"}))
+	})
+
+	It("requests every zone of the region when none are given", func() {
+		Expect(multiAZZones(nil)).To(BeNil())
+		args.ensureMultiAZ = true
+		Expect(multiAZZones(nil)).To(Equal([]string{allZonesValue}))
+		Expect(multiAZZones([]string{"us-east-1a"})).To(Equal([]string{"us-east-1a"}))
+	})
+
+	It("rejects fewer zones than the minimum", func() {
+		args.ensureMultiAZ = true
+		Expect(checkMultiAZZones("us-east-1", []string{"us-east-1a", "us-east-1b", "us-east-1c"})).To(Succeed())
+		err := checkMultiAZZones("us-east-1", []string{"us-east-1a", "us-east-1b"})
+		Expect(err).To(MatchError("The '--ensure-multi-az' flag requires at least 3 availability zones, but " +
+			"only [us-east-1a us-east-1b] of region 'us-east-1' were selected"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+	})
+
+	DescribeTable("validateMultiAZFlags",
+		func(argv []string, expectedError string) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.BoolVar(&args.ensureMultiAZ, "ensure-multi-az", false, "")
+			flags.IntVar(&args.minAZCount, "min-az-count", defaultMinAZCount, "")
+			flags.Bool("stream", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+
+			err := validateMultiAZFlags(flags)
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedError))
+			}
+		},
+		Entry("without the flag", []string{}, ""),
+		Entry("with the flag", []string{"--ensure-multi-az"}, ""),
+		Entry("with a minimum", []string{"--ensure-multi-az", "--min-az-count", "2"}, ""),
+		Entry("minimum without the flag", []string{"--min-az-count", "2"},
+			"The '--min-az-count' flag can only be used together with '--ensure-multi-az'"),
+		Entry("minimum of zero", []string{"--ensure-multi-az", "--min-az-count", "0"},
+			"Invalid minimum number of availability zones 0. It must be greater than zero"),
+		Entry("with streaming", []string{"--ensure-multi-az", "--stream"},
+			"The '--ensure-multi-az' flag can't be used together with '--stream'"),
+	)
+})
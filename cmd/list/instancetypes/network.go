@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// networkColumn is the column that tells the network capabilities of each instance type.
+const networkColumn = "network"
+
+// machineTypeIPv6 returns whether a machine type supports IPv6 and whether it is known.
+var machineTypeIPv6 = func(machineType *ocm.MachineType) (bool, bool) {
+	return machineType.SupportsIPv6()
+}
+
+// networkValue returns the value of the network column: 'dual-stack' for the machine types that
+// support IPv6, 'ipv4' for the ones that don't, and '-' when it isn't known.
+func networkValue(machineType *ocm.MachineType) string {
+	ipv6, ok := machineTypeIPv6(machineType)
+	switch {
+	case !ok:
+		return "-"
+	case ipv6:
+		return "dual-stack"
+	default:
+		return "ipv4"
+	}
+}
+
+// hasNetworkInfo returns true if the network capabilities of any of the machine types are known.
+func hasNetworkInfo(machineTypes ocm.MachineTypeList) bool {
+	for _, machineType := range machineTypes {
+		if _, ok := machineTypeIPv6(machineType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByIPv6 keeps only the machine types known to support IPv6.
+func filterByIPv6(machineTypes ocm.MachineTypeList) ocm.MachineTypeList {
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		ipv6, ok := machineTypeIPv6(machineType)
+		return ok && ipv6
+	})
+}
+
+// warnIPv6Ignored warns that the '--supports-ipv6' flag was given but can't be applied.
+func warnIPv6Ignored(r *rosa.Runtime) {
+	r.Reporter.Warnf("The '--supports-ipv6' flag is ignored, as the network capabilities of the " +
+		"instance types aren't available")
+}
+
+// applyIPv6Filter keeps only the machine types that support IPv6 when the '--supports-ipv6' flag is
+// given. When the network capabilities of none of them are known the machine types are returned
+// unchanged, with a warning.
+func applyIPv6Filter(r *rosa.Runtime, machineTypes ocm.MachineTypeList) ocm.MachineTypeList {
+	if !args.supportsIPv6 {
+		return machineTypes
+	}
+	if !hasNetworkInfo(machineTypes) {
+		warnIPv6Ignored(r)
+		return machineTypes
+	}
+	return filterByIPv6(machineTypes)
+}
@@ -0,0 +1,98 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("IPv6 support", func() {
+	var machineTypes ocm.MachineTypeList
+	var r *rosa.Runtime
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m4.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+		}
+		r = &rosa.Runtime{
+			Reporter: reporter.CreateReporterOrExit(),
+			Logger:   logging.NewLogger(),
+		}
+		args.supportsIPv6 = true
+	})
+
+	AfterEach(func() {
+		args.supportsIPv6 = false
+	})
+
+	withIPv6 := func(ipv6 map[string]bool) {
+		saved := machineTypeIPv6
+		machineTypeIPv6 = func(machineType *ocm.MachineType) (bool, bool) {
+			supported, ok := ipv6[machineType.MachineType.ID()]
+			return supported, ok
+		}
+		DeferCleanup(func() {
+			machineTypeIPv6 = saved
+		})
+	}
+
+	It("keeps only the machine types known to support IPv6", func() {
+		withIPv6(map[string]bool{"m5.xlarge": true, "m4.xlarge": false})
+		filtered := applyIPv6Filter(r, machineTypes)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge"}))
+	})
+
+	It("warns and returns the machine types unchanged when the network capabilities aren't known", func() {
+		var messages bytes.Buffer
+		var err error
+		r.Reporter, err = reporter.New().Stream(&messages).Build()
+		Expect(err).NotTo(HaveOccurred())
+		filtered := applyIPv6Filter(r, machineTypes)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge", "m4.xlarge", "r5.xlarge"}))
+		Expect(messages.String()).To(Equal("WARN: The '--supports-ipv6' flag is ignored, as the network " +
+			"capabilities of the instance types aren't available\n"))
+	})
+
+	It("warns and streams all the machine types when the network capabilities aren't known", func() {
+		var messages bytes.Buffer
+		var err error
+		r.Reporter, err = reporter.New().Stream(&messages).Build()
+		Expect(err).NotTo(HaveOccurred())
+		selected, err := selectColumns([]string{"id"})
+		Expect(err).NotTo(HaveOccurred())
+		stream := func(fn func(page ocm.MachineTypeList) error) error {
+			return fn(ocm.MachineTypeList{machineTypes[0], machineTypes[2]})
+		}
+		var out bytes.Buffer
+		count, err := streamMachineTypes(r, &out, stream, selected, 0, nil, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		Expect(messages.String()).To(Equal("WARN: The '--supports-ipv6' flag is ignored, as the network " +
+			"capabilities of the instance types aren't available\n"))
+	})
+
+	It("renders '-' in the network column when the network capabilities aren't known", func() {
+		selected, err := selectColumns([]string{"id", networkColumn})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).Row(machineTypes[0])).To(Equal("m5.xlarge\t-\n"))
+	})
+
+	It("tells the network capabilities in their column", func() {
+		withIPv6(map[string]bool{"m5.xlarge": true, "m4.xlarge": false})
+		selected, err := selectColumns([]string{"id", networkColumn})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newTable(selected).HeaderRow()).To(Equal("ID\tNETWORK\t\n"))
+		Expect(newTable(selected).Row(machineTypes[0])).To(Equal("m5.xlarge\tdual-stack\n"))
+		Expect(newTable(selected).Row(machineTypes[1])).To(Equal("m4.xlarge\tipv4\n"))
+		Expect(newTable(selected).Row(machineTypes[2])).To(Equal("r5.xlarge\t-\n"))
+	})
+})
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPrecision is the maximum number of decimals accepted by the '--precision' flag.
+const maxPrecision = 6
+
+// NumberFormat is how the numbers are displayed: the number of decimals, and the separators of the
+// thousands and of the decimals. An empty thousands separator doesn't group the digits.
+type NumberFormat struct {
+	Precision          int
+	ThousandsSeparator string
+	DecimalSeparator   string
+}
+
+// DefaultNumberFormat is the format of the numbers when the '--precision', '--thousands-separator'
+// and '--decimal-separator' flags aren't given.
+var DefaultNumberFormat = NumberFormat{
+	Precision:        1,
+	DecimalSeparator: ".",
+}
+
+// numberFormat returns the format of the numbers given in the command line.
+func numberFormat() NumberFormat {
+	return NumberFormat{
+		Precision:          args.precision,
+		ThousandsSeparator: args.thousandsSeparator,
+		DecimalSeparator:   args.decimalSeparator,
+	}
+}
+
+// validateNumberFormat checks the '--precision', '--thousands-separator' and '--decimal-separator'
+// flags.
+func validateNumberFormat() error {
+	if args.precision < 0 || args.precision > maxPrecision {
+		return fmt.Errorf("Invalid precision %d. It must be between 0 and %d", args.precision, maxPrecision)
+	}
+	for _, separator := range []struct {
+		flag  string
+		value string
+		empty bool
+	}{
+		{"thousands-separator", args.thousandsSeparator, true},
+		{"decimal-separator", args.decimalSeparator, false},
+	} {
+		if separator.value == "" && separator.empty {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(separator.value)
+		if size == 0 || size != len(separator.value) || unicode.IsDigit(r) {
+			return fmt.Errorf("Invalid value '%s' for the '--%s' flag. It must be a single character that "+
+				"isn't a digit", separator.value, separator.flag)
+		}
+	}
+	if args.thousandsSeparator == args.decimalSeparator {
+		return fmt.Errorf("The thousands and decimal separators must be different")
+	}
+	return nil
+}
+
+// formatNumber formats the value with the given number of decimals, using the given separators for
+// the thousands and the decimals. An empty thousands separator doesn't group the digits.
+func formatNumber(value float64, precision int, thousands string, decimal string) string {
+	// Round half away from zero, like the selection of the unit of the memory, instead of to even:
+	scale := math.Pow10(precision)
+	text := strconv.FormatFloat(math.Round(value*scale)/scale, 'f', precision, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(text, ".")
+	if thousands != "" && len(integer) > 3 {
+		var grouped strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				grouped.WriteString(thousands)
+			}
+			grouped.WriteRune(digit)
+		}
+		integer = grouped.String()
+	}
+	if hasFraction {
+		return sign + integer + decimal + fraction
+	}
+	return sign + integer
+}
+
+// formatInteger formats a whole value, like a number of CPU cores, with the thousands separator
+// given in the command line.
+func formatInteger(value int) string {
+	return formatNumber(float64(value), 0, args.thousandsSeparator, args.decimalSeparator)
+}
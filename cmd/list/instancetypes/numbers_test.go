@@ -0,0 +1,76 @@
+package instancetypes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Number format", func() {
+	AfterEach(func() {
+		args.precision = 1
+		args.thousandsSeparator = ""
+		args.decimalSeparator = "."
+	})
+
+	DescribeTable("formatNumber",
+		func(value float64, precision int, thousands string, decimal string, expected string) {
+			Expect(formatNumber(value, precision, thousands, decimal)).To(Equal(expected))
+		},
+		Entry("default", 16.0, 1, "", ".", "16.0"),
+		Entry("rounds half away from zero", 1.25, 1, "", ".", "1.3"),
+		Entry("rounds to the precision", 15.99, 2, "", ".", "15.99"),
+		Entry("no decimals", 1536.6, 0, ",", ".", "1,537"),
+		Entry("three digits", 999.0, 0, ",", ".", "999"),
+		Entry("four digits", 1000.0, 0, ",", ".", "1,000"),
+		Entry("seven digits", 1234567.891, 2, ",", ".", "1,234,567.89"),
+		Entry("european", 1234567.891, 1, ".", ",", "1.234.567,9"),
+		Entry("negative", -1234.5, 1, " ", ".", "-1 234.5"),
+	)
+
+	It("keeps the default memory format", func() {
+		Expect(ByteCountIEC(17179869184, "B")).To(Equal("16.0 GiB"))
+		Expect(ByteCountIEC(512, "B")).To(Equal("512 B"))
+	})
+
+	It("formats the memory with the precision and separators", func() {
+		args.precision = 2
+		args.decimalSeparator = ","
+		Expect(ByteCountIEC(1610612736, "B")).To(Equal("1,50 GiB"))
+		Expect(ByteCountSI(17179869184, "B")).To(Equal("17,18 GB"))
+	})
+
+	It("groups the bytes of small values", func() {
+		args.thousandsSeparator = ","
+		Expect(ByteCountSI(999, "B")).To(Equal("999 B"))
+		Expect(ByteCountIEC(1000, "B")).To(Equal("1,000 B"))
+	})
+
+	It("moves to the next unit when the rounded value reaches the base", func() {
+		args.precision = 0
+		Expect(ByteCountIEC(1048500, "B")).To(Equal("1 MiB"))
+		args.precision = 3
+		Expect(ByteCountIEC(1048500, "B")).To(Equal("1023.926 KiB"))
+	})
+
+	DescribeTable("validateNumberFormat",
+		func(precision int, thousands string, decimal string, expected string) {
+			args.precision = precision
+			args.thousandsSeparator = thousands
+			args.decimalSeparator = decimal
+			err := validateNumberFormat()
+			if expected == "" {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("default", 1, "", ".", ""),
+		Entry("european", 2, ".", ",", ""),
+		Entry("negative precision", -1, "", ".", "Invalid precision -1. It must be between 0 and 6"),
+		Entry("digit separator", 1, "0", ".",
+			"Invalid value '0' for the '--thousands-separator' flag. It must be a single character that isn't a digit"),
+		Entry("empty decimal separator", 1, "", "",
+			"Invalid value '' for the '--decimal-separator' flag. It must be a single character that isn't a digit"),
+		Entry("same separators", 1, ",", ",", "The thousands and decimal separators must be different"),
+	)
+})
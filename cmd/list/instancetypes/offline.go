@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// offlineEnv is the environment variable with the cassette directory used when the '--offline' flag
+// isn't given.
+const offlineEnv = "ROSA_OFFLINE"
+
+// offlineDir returns the cassette directory to replay the responses from, or an empty string if the
+// requests must be sent to OCM.
+func offlineDir() string {
+	if args.offline != "" {
+		return args.offline
+	}
+	return os.Getenv(offlineEnv)
+}
+
+// validateOfflineFlags checks that the flags can be used without AWS credentials, as only the
+// requests to OCM are replayed.
+func validateOfflineFlags(flags *pflag.FlagSet, dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if args.saveCassette != "" {
+		return fmt.Errorf("The '--save-cassette' flag can't be used in offline mode, as the responses are " +
+			"already recorded")
+	}
+	zones := flags.Lookup("availability-zones")
+	if zones != nil && zones.Changed {
+		return fmt.Errorf("The '--availability-zones' flag can't be used in offline mode, as the " +
+			"availability zones are described by AWS")
+	}
+	if args.ensureMultiAZ {
+		return fmt.Errorf("The '--ensure-multi-az' flag can't be used in offline mode, as the " +
+			"availability zones are described by AWS")
+	}
+	if !args.all && !args.stream && !args.jsonl && args.roleARN == "" {
+		return fmt.Errorf("Offline mode requires the '--role-arn' or the '--all' flag, as the requests " +
+			"made with AWS credentials can't be replayed")
+	}
+	return nil
+}
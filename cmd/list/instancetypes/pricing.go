@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/openshift/rosa/pkg/helper"
+	"github.com/openshift/rosa/pkg/interactive"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// priceTier is a range of on-demand prices, in US dollars per hour. The minimum is included and the
+// maximum isn't.
+type priceTier struct {
+	name string
+	min  float64
+	max  float64
+}
+
+// priceTiers are the values of the '--price-tier' flag, from the cheapest to the most expensive.
+var priceTiers = []priceTier{
+	{name: "low", min: 0, max: 0.5},
+	{name: "medium", min: 0.5, max: 2},
+	{name: "high", min: 2, max: math.Inf(1)},
+}
+
+// anyPriceTier is the answer of the interactive prompt that keeps the instance types of every tier.
+const anyPriceTier = "any"
+
+// machineTypePrice returns the hourly price of a machine type and whether it is known.
+var machineTypePrice = func(machineType *ocm.MachineType) (float64, bool) {
+	return machineType.HourlyPrice()
+}
+
+func priceTierNames() []string {
+	var names []string
+	for _, tier := range priceTiers {
+		names = append(names, tier.name)
+	}
+	return names
+}
+
+// priceTierDescriptions describes the tiers with their ranges of prices, for example
+// "low (under $0.50/h)", so that the ranges are only written in priceTiers.
+func priceTierDescriptions() []string {
+	var descriptions []string
+	for _, tier := range priceTiers {
+		var prices string
+		switch {
+		case tier.min == 0:
+			prices = fmt.Sprintf("under $%.2f/h", tier.max)
+		case math.IsInf(tier.max, 1):
+			prices = fmt.Sprintf("$%.2f/h or more", tier.min)
+		default:
+			prices = fmt.Sprintf("$%.2f/h to $%.2f/h", tier.min, tier.max)
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", tier.name, prices))
+	}
+	return descriptions
+}
+
+func validatePriceTier(name string) error {
+	if name != "" && !helper.Contains(priceTierNames(), name) {
+		return fmt.Errorf("Invalid price tier '%s'. Allowed values are %s", name, priceTierNames())
+	}
+	return nil
+}
+
+// hasPrices returns true if the price of any of the machine types is known.
+func hasPrices(machineTypes ocm.MachineTypeList) bool {
+	for _, machineType := range machineTypes {
+		if _, ok := machineTypePrice(machineType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByPriceTier keeps only the machine types whose price is in the given tier. The machine types
+// without a known price are dropped.
+func filterByPriceTier(machineTypes ocm.MachineTypeList, name string) ocm.MachineTypeList {
+	for _, tier := range priceTiers {
+		if tier.name != name {
+			continue
+		}
+		return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+			price, ok := machineTypePrice(machineType)
+			return ok && price >= tier.min && price < tier.max
+		})
+	}
+	return machineTypes
+}
+
+// warnPriceTierIgnored warns that the '--price-tier' flag was given but can't be applied.
+func warnPriceTierIgnored(r *rosa.Runtime) {
+	r.Reporter.Warnf("The '--price-tier' flag is ignored, as the prices of the instance types " +
+		"aren't available")
+}
+
+// applyPriceTier filters the machine types by the tier given with the '--price-tier' flag, or
+// selected interactively when the flag isn't given. When none of the prices are known the machine
+// types are returned unchanged, with a warning if a tier was requested.
+func applyPriceTier(r *rosa.Runtime, machineTypes ocm.MachineTypeList) (ocm.MachineTypeList, error) {
+	tier := args.priceTier
+	if !hasPrices(machineTypes) {
+		if tier != "" {
+			warnPriceTierIgnored(r)
+		}
+		return machineTypes, nil
+	}
+	if tier == "" && interactive.Enabled() {
+		var err error
+		tier, err = interactive.GetOption(interactive.Input{
+			Question: "Price tier",
+			Help: fmt.Sprintf("Hourly on-demand price of the instance types: %s.",
+				strings.Join(priceTierDescriptions(), ", ")),
+			Options: append([]string{anyPriceTier}, priceTierNames()...),
+			Default: anyPriceTier,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Expected a valid price tier: %s", err)
+		}
+	}
+	if tier == "" || tier == anyPriceTier {
+		return machineTypes, nil
+	}
+	return filterByPriceTier(machineTypes, tier), nil
+}
@@ -0,0 +1,123 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Price tiers", func() {
+	var machineTypes ocm.MachineTypeList
+	var r *rosa.Runtime
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+			buildMachineType("p3.8xlarge", cmv1.MachineTypeCategoryAcceleratedComputing, 32, 261993005056),
+			buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+		}
+		r = &rosa.Runtime{
+			Reporter: reporter.CreateReporterOrExit(),
+			Logger:   logging.NewLogger(),
+		}
+	})
+
+	AfterEach(func() {
+		args.priceTier = ""
+	})
+
+	withPrices := func(prices map[string]float64) {
+		saved := machineTypePrice
+		machineTypePrice = func(machineType *ocm.MachineType) (float64, bool) {
+			price, ok := prices[machineType.MachineType.ID()]
+			return price, ok
+		}
+		DeferCleanup(func() {
+			machineTypePrice = saved
+		})
+	}
+
+	It("rejects an unknown tier", func() {
+		Expect(validatePriceTier("cheap")).To(MatchError(
+			"Invalid price tier 'cheap'. Allowed values are [low medium high]"))
+		Expect(validatePriceTier("")).To(Succeed())
+	})
+
+	It("keeps the machine types whose price is in the tier, including its minimum", func() {
+		withPrices(map[string]float64{"m5.xlarge": 0.192, "m5.4xlarge": 0.768, "p3.8xlarge": 12.24,
+			"r5.xlarge": 0.5})
+		low := filterByPriceTier(machineTypes, "low")
+		Expect(low.IDs()).To(Equal([]string{"m5.xlarge"}))
+		medium := filterByPriceTier(machineTypes, "medium")
+		Expect(medium.IDs()).To(Equal([]string{"m5.4xlarge", "r5.xlarge"}))
+		high := filterByPriceTier(machineTypes, "high")
+		Expect(high.IDs()).To(Equal([]string{"p3.8xlarge"}))
+	})
+
+	It("drops the machine types without a price when some prices are known", func() {
+		withPrices(map[string]float64{"m5.xlarge": 0.192})
+		args.priceTier = "low"
+		filtered, err := applyPriceTier(r, machineTypes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge"}))
+	})
+
+	It("warns and keeps all the machine types when no price is known", func() {
+		var messages bytes.Buffer
+		var err error
+		r.Reporter, err = reporter.New().Stream(&messages).Build()
+		Expect(err).NotTo(HaveOccurred())
+		args.priceTier = "high"
+		filtered, err := applyPriceTier(r, machineTypes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered).To(Equal(machineTypes))
+		Expect(messages.String()).To(Equal("WARN: The '--price-tier' flag is ignored, as the prices of " +
+			"the instance types aren't available\n"))
+	})
+
+	It("doesn't warn when no tier is requested", func() {
+		var messages bytes.Buffer
+		var err error
+		r.Reporter, err = reporter.New().Stream(&messages).Build()
+		Expect(err).NotTo(HaveOccurred())
+		filtered, err := applyPriceTier(r, machineTypes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered).To(Equal(machineTypes))
+		Expect(messages.String()).To(BeEmpty())
+	})
+
+	It("warns and streams all the machine types when no price is known", func() {
+		var messages bytes.Buffer
+		var err error
+		r.Reporter, err = reporter.New().Stream(&messages).Build()
+		Expect(err).NotTo(HaveOccurred())
+		selected, err := selectColumns([]string{"id"})
+		Expect(err).NotTo(HaveOccurred())
+		stream := func(fn func(page ocm.MachineTypeList) error) error {
+			return fn(machineTypes)
+		}
+		args.priceTier = "low"
+		var out bytes.Buffer
+		count, err := streamMachineTypes(r, &out, stream, selected, 0, nil, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(4))
+		Expect(messages.String()).To(Equal("WARN: The '--price-tier' flag is ignored, as the prices of " +
+			"the instance types aren't available\n"))
+	})
+
+	It("describes the tiers with their prices", func() {
+		Expect(priceTierDescriptions()).To(Equal([]string{
+			"low (under $0.50/h)",
+			"medium ($0.50/h to $2.00/h)",
+			"high ($2.00/h or more)",
+		}))
+	})
+})
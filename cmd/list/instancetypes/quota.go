@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// validateQuotaConflictingFlags are the flags that don't select the instance types of a single
+// region, or that change what is printed.
+var validateQuotaConflictingFlags = []string{"all", "diff-region", "stream", "jsonl", "page", "size", "count"}
+
+// quotaRequest is the value of the '--validate-quota' flag, an instance type and the number of
+// instances of it that should fit in the quota of the account.
+type quotaRequest struct {
+	id    string
+	count int
+}
+
+// parseQuotaRequest parses the value of the '--validate-quota' flag, for example 'g4dn.xlarge:4'.
+func parseQuotaRequest(value string) (quotaRequest, error) {
+	id, count, found := strings.Cut(value, ":")
+	if !found || id == "" {
+		return quotaRequest{}, fmt.Errorf("Invalid value '%s' for '--validate-quota'. It must be an "+
+			"instance type and a number of instances, for example 'g4dn.xlarge:4'", value)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 {
+		return quotaRequest{}, fmt.Errorf("Invalid number of instances '%s' for '--validate-quota'. It "+
+			"must be greater than zero", count)
+	}
+	return quotaRequest{id: id, count: n}, nil
+}
+
+func validateQuotaFlags(flags *pflag.FlagSet) error {
+	if args.validateQuota == "" {
+		return nil
+	}
+	for _, name := range validateQuotaConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--validate-quota' flag can't be used together with '--%s'", name)
+		}
+	}
+	_, err := parseQuotaRequest(args.validateQuota)
+	return err
+}
+
+// quotaCheck is the result of checking if the quota of the account is enough for a number of
+// instances. Available is nil for the instance types that aren't limited by quota.
+type quotaCheck struct {
+	InstanceType string
+	Region       string
+	Count        int
+	Available    *int
+	Shortfall    int
+	Sufficient   bool
+}
+
+// object returns the JSON representation of the check. The available quota is null for the instance
+// types that aren't limited by quota.
+func (c quotaCheck) object() map[string]interface{} {
+	object := map[string]interface{}{
+		"instance_type": c.InstanceType,
+		"region":        c.Region,
+		"count":         c.Count,
+		"available":     nil,
+		"shortfall":     c.Shortfall,
+		"sufficient":    c.Sufficient,
+	}
+	if c.Available != nil {
+		object["available"] = *c.Available
+	}
+	return object
+}
+
+// checkQuota checks if the quota of the account is enough for the requested number of instances of
+// one of the given machine types.
+func checkQuota(machineTypes ocm.MachineTypeList, region string, request quotaRequest) (quotaCheck, error) {
+	machineType := machineTypes.Find(request.id)
+	if machineType == nil {
+		return quotaCheck{}, rosa.UsageError(fmt.Errorf("Instance type '%s' isn't available in region '%s'",
+			request.id, region))
+	}
+	check := quotaCheck{
+		InstanceType: request.id,
+		Region:       region,
+		Count:        request.count,
+		Shortfall:    machineType.QuotaShortfall(request.count),
+	}
+	check.Sufficient = check.Shortfall == 0
+	if machineType.MachineType.Category() == ocm.AcceleratedComputing {
+		available, _ := machineType.AvailableQuota()
+		check.Available = &available
+	}
+	return check, nil
+}
+
+// err returns the error that makes the command fail when the quota isn't enough, with the shortfall.
+func (c quotaCheck) err() error {
+	if c.Sufficient {
+		return nil
+	}
+	return rosa.QuotaError(fmt.Errorf("The account has quota for only %d instances of '%s' in region "+
+		"'%s', %d short of the %d requested", *c.Available, c.InstanceType, c.Region, c.Shortfall, c.Count))
+}
+
+// writeQuotaCheck writes the result of a successful quota check. Failures are reported by the error
+// of the check instead.
+func writeQuotaCheck(w io.Writer, check quotaCheck) {
+	if check.Available == nil {
+		fmt.Fprintf(w, "Instance type '%s' isn't limited by quota, %d instances can run in region '%s'\n",
+			check.InstanceType, check.Count, check.Region)
+		return
+	}
+	fmt.Fprintf(w, "The account has quota for %d instances of '%s' in region '%s', enough for the %d "+
+		"requested\n", *check.Available, check.InstanceType, check.Region, check.Count)
+}
+
+// runValidateQuota checks if the quota of the account is enough for the instances given with the
+// '--validate-quota' flag, failing with ExitQuota when it isn't.
+func runValidateQuota(r *rosa.Runtime, region string) error {
+	request, err := parseQuotaRequest(args.validateQuota)
+	if err != nil {
+		return rosa.UsageError(err)
+	}
+	r.Reporter.Debugf("Fetching instance types in region '%s'", region)
+	ctx, cancel := r.OperationContext()
+	stopSpinner := startSpinner(r)
+	// Keep the instance types without enough quota, as those are the ones the check is about:
+	machineTypes, err := instancetypes.List(ctx, r.OCMClient, r.AWSClient, instancetypes.Filters{
+		Region:     region,
+		RoleARN:    args.roleARN,
+		ExternalID: args.externalID,
+	})
+	stopSpinner()
+	err = r.OperationError(ctx, err)
+	cancel()
+	if err != nil {
+		return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", err))
+	}
+	check, err := checkQuota(machineTypes, region, request)
+	if err != nil {
+		return err
+	}
+	if output.HasFlag() && output.Output() != output.CSV {
+		err = output.Print(r.Writer, check.object())
+		if err != nil {
+			return err
+		}
+		return check.err()
+	}
+	if check.Sufficient {
+		writeQuotaCheck(r.Writer, check)
+	}
+	return check.err()
+}
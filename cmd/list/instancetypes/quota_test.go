@@ -0,0 +1,85 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Quota validation", func() {
+	var machineTypes ocm.MachineTypeList
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildGPUMachineType("g4dn.xlarge", "t4-gpu-4"),
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		}
+		machineTypes.UpdateAvailableQuota(buildQuotaCosts("t4-gpu-4", 10, 4))
+	})
+
+	DescribeTable("parses the instance type and the number of instances",
+		func(value string, expected quotaRequest, expectedError string) {
+			request, err := parseQuotaRequest(value)
+			if expectedError != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedError)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request).To(Equal(expected))
+		},
+		Entry("valid", "g4dn.xlarge:4", quotaRequest{id: "g4dn.xlarge", count: 4}, ""),
+		Entry("without count", "g4dn.xlarge", quotaRequest{}, "Invalid value 'g4dn.xlarge'"),
+		Entry("without instance type", ":4", quotaRequest{}, "Invalid value ':4'"),
+		Entry("zero instances", "g4dn.xlarge:0", quotaRequest{}, "Invalid number of instances '0'"),
+		Entry("not a number", "g4dn.xlarge:many", quotaRequest{}, "Invalid number of instances 'many'"),
+	)
+
+	It("succeeds when the quota is enough", func() {
+		check, err := checkQuota(machineTypes, "us-east-1", quotaRequest{id: "g4dn.xlarge", count: 6})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(check.err()).NotTo(HaveOccurred())
+		var b bytes.Buffer
+		writeQuotaCheck(&b, check)
+		Expect(b.String()).To(Equal("The account has quota for 6 instances of 'g4dn.xlarge' in region " +
+			"'us-east-1', enough for the 6 requested\n"))
+	})
+
+	It("fails with the shortfall when the quota isn't enough", func() {
+		check, err := checkQuota(machineTypes, "us-east-1", quotaRequest{id: "g4dn.xlarge", count: 9})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(check.object()).To(Equal(map[string]interface{}{
+			"instance_type": "g4dn.xlarge",
+			"region":        "us-east-1",
+			"count":         9,
+			"available":     6,
+			"shortfall":     3,
+			"sufficient":    false,
+		}))
+		err = check.err()
+		Expect(err).To(MatchError("The account has quota for only 6 instances of 'g4dn.xlarge' in region " +
+			"'us-east-1', 3 short of the 9 requested"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitQuota))
+	})
+
+	It("succeeds for the instance types that aren't limited by quota", func() {
+		check, err := checkQuota(machineTypes, "us-east-1", quotaRequest{id: "m5.xlarge", count: 100})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(check.err()).NotTo(HaveOccurred())
+		Expect(check.object()).To(HaveKeyWithValue("available", BeNil()))
+		var b bytes.Buffer
+		writeQuotaCheck(&b, check)
+		Expect(b.String()).To(Equal("Instance type 'm5.xlarge' isn't limited by quota, 100 instances can " +
+			"run in region 'us-east-1'\n"))
+	})
+
+	It("rejects an instance type that isn't available in the region", func() {
+		_, err := checkQuota(machineTypes, "us-east-1", quotaRequest{id: "p4d.24xlarge", count: 1})
+		Expect(err).To(MatchError("Instance type 'p4d.24xlarge' isn't available in region 'us-east-1'"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+	})
+})
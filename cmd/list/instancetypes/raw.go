@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+)
+
+// rawConflictingFlags are the flags that format the instance types or select them in a way that
+// the raw response doesn't reflect.
+var rawConflictingFlags = []string{"output", "template", "template-file", "columns", "wide", "stream", "jsonl",
+	"page", "size", "count", "availability-zones", "diff-region", "validate-quota"}
+
+func validateRawFlag(flags *pflag.FlagSet) error {
+	if !args.raw {
+		return nil
+	}
+	for _, name := range rawConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--raw' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// writeRaw writes the bodies of the responses of OCM to w, one after the other, each ending with a
+// line break.
+func writeRaw(w io.Writer, pages [][]byte) error {
+	for _, page := range pages {
+		_, err := w.Write(page)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(page, []byte("\n")) {
+			_, err = io.WriteString(w, "\n")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
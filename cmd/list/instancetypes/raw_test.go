@@ -0,0 +1,37 @@
+package instancetypes
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("Raw output", func() {
+	AfterEach(func() {
+		args.raw = false
+	})
+
+	It("writes each body on its own line", func() {
+		var b bytes.Buffer
+		Expect(writeRaw(&b, [][]byte{[]byte(`{"page":1}`), []byte("{\"page\":2}\n")})).To(Succeed())
+		Expect(b.String()).To(Equal("{\"page\":1}\n{\"page\":2}\n"))
+	})
+
+	It("rejects the flags that format the instance types", func() {
+		args.raw = true
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.StringSlice("columns", nil, "")
+		Expect(flags.Parse([]string{"--columns", "id"})).To(Succeed())
+		Expect(validateRawFlag(flags)).To(MatchError("The '--raw' flag can't be used together with '--columns'"))
+	})
+
+	It("accepts the flags that select the query", func() {
+		args.raw = true
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("role-arn", "", "")
+		Expect(flags.Parse([]string{"--role-arn", "arn:aws:iam::123456789012:role/Installer"})).To(Succeed())
+		Expect(validateRawFlag(flags)).To(Succeed())
+	})
+})
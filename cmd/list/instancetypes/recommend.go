@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+// recommendConflictingFlags are the flags that don't make sense when only the best fitting instance
+// types are displayed.
+var recommendConflictingFlags = []string{"stream", "jsonl", "count", "group-by", "diff-region", "validate-quota",
+	"raw", "max-results", "head", "tail", "sort", "reverse"}
+
+// maxClosestMisses is the number of instance types that don't satisfy the requirements listed when
+// none of them does.
+const maxClosestMisses = 3
+
+func validateRecommendFlags(flags *pflag.FlagSet) error {
+	if !args.recommend {
+		for _, name := range []string{"cpu", "memory", "top"} {
+			if flags.Changed(name) {
+				return fmt.Errorf("The '--%s' flag can only be used together with '--recommend'", name)
+			}
+		}
+		return nil
+	}
+	if args.cpu <= 0 && args.memory == "" {
+		return fmt.Errorf("The '--recommend' flag requires '--cpu', '--memory' or both")
+	}
+	if args.cpu < 0 {
+		return fmt.Errorf("Invalid number of CPU cores %d. It must be greater than zero", args.cpu)
+	}
+	if args.top < 1 {
+		return fmt.Errorf("Invalid number of recommendations %d. It must be greater than zero", args.top)
+	}
+	for _, name := range recommendConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--recommend' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// overProvisioning returns how much the machine type exceeds the requirements, as the sum of the
+// relative excess of each of them, so that one extra core weighs more for a small requirement than
+// for a large one. Requirements that are zero are ignored.
+func overProvisioning(machineType *ocm.MachineType, cpu int, memory uint64) float64 {
+	var result float64
+	if cpu > 0 {
+		result += machineType.MachineType.CPU().Value()/float64(cpu) - 1
+	}
+	if memory > 0 {
+		result += instancetypes.MemoryBytes(machineType.MachineType)/float64(memory) - 1
+	}
+	return result
+}
+
+// shortfall returns how far the machine type is from satisfying the requirements, as the sum of the
+// relative lack of each of them, zero if it satisfies them.
+func shortfall(machineType *ocm.MachineType, cpu int, memory uint64) float64 {
+	var result float64
+	if cpu > 0 {
+		if lack := 1 - machineType.MachineType.CPU().Value()/float64(cpu); lack > 0 {
+			result += lack
+		}
+	}
+	if memory > 0 {
+		if lack := 1 - instancetypes.MemoryBytes(machineType.MachineType)/float64(memory); lack > 0 {
+			result += lack
+		}
+	}
+	return result
+}
+
+// rankMachineTypes sorts the machine types by the given score, using the number of CPU cores, the
+// memory and the ID to break ties so that the result is stable.
+func rankMachineTypes(machineTypes ocm.MachineTypeList, score func(*ocm.MachineType) float64) {
+	sort.SliceStable(machineTypes, func(i, j int) bool {
+		a, b := machineTypes[i], machineTypes[j]
+		if scoreA, scoreB := score(a), score(b); scoreA != scoreB {
+			return scoreA < scoreB
+		}
+		if cpuA, cpuB := a.MachineType.CPU().Value(), b.MachineType.CPU().Value(); cpuA != cpuB {
+			return cpuA < cpuB
+		}
+		memoryA, memoryB := instancetypes.MemoryBytes(a.MachineType), instancetypes.MemoryBytes(b.MachineType)
+		if memoryA != memoryB {
+			return memoryA < memoryB
+		}
+		return a.MachineType.ID() < b.MachineType.ID()
+	})
+}
+
+// recommendMachineTypes returns at most top of the available machine types that have at least the
+// given CPU cores and bytes of memory, the ones with the smallest over-provisioning first. If none
+// satisfies the requirements the error lists the closest ones.
+func recommendMachineTypes(machineTypes ocm.MachineTypeList, cpu int, memory uint64,
+	top int) (ocm.MachineTypeList, error) {
+	available := machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return machineType.Available
+	})
+	fits := available.Filter(func(machineType *ocm.MachineType) bool {
+		return shortfall(machineType, cpu, memory) == 0
+	})
+	if len(fits) > 0 {
+		rankMachineTypes(fits, func(machineType *ocm.MachineType) float64 {
+			return overProvisioning(machineType, cpu, memory)
+		})
+		return truncateResults(fits, top), nil
+	}
+
+	message := fmt.Sprintf("No available instance type has at least %s", describeRequirements(cpu, memory))
+	if len(available) == 0 {
+		return nil, fmt.Errorf("%s", message)
+	}
+	rankMachineTypes(available, func(machineType *ocm.MachineType) float64 {
+		return shortfall(machineType, cpu, memory)
+	})
+	var misses []string
+	for _, machineType := range truncateResults(available, maxClosestMisses) {
+		misses = append(misses, fmt.Sprintf("'%s' (%d CPU cores, %s)", machineType.MachineType.ID(),
+			int(machineType.MachineType.CPU().Value()), formatMemory(machineType.MachineType)))
+	}
+	return nil, fmt.Errorf("%s. The closest ones are %s", message, strings.Join(misses, ", "))
+}
+
+// describeRequirements returns the text that describes the requirements in error messages, for
+// example '8 CPU cores and 32.0 GiB of memory'.
+func describeRequirements(cpu int, memory uint64) string {
+	var requirements []string
+	if cpu > 0 {
+		requirements = append(requirements, fmt.Sprintf("%d CPU cores", cpu))
+	}
+	if memory > 0 {
+		formatted := FormatBytes(int64(memory), numberFormat())
+		if args.memoryUnit == "si" {
+			formatted = ByteCountSI(int(memory), "B")
+		}
+		requirements = append(requirements, formatted+" of memory")
+	}
+	return strings.Join(requirements, " and ")
+}
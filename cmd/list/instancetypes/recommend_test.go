@@ -0,0 +1,88 @@
+package instancetypes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Recommend", func() {
+	var machineTypes ocm.MachineTypeList
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+			buildMachineType("r5.2xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 8, 68719476736),
+			buildMachineType("c5.2xlarge", cmv1.MachineTypeCategoryComputeOptimized, 8, 17179869184),
+			buildMachineType("m5.2xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 8, 34359738368),
+		}
+	})
+
+	AfterEach(func() {
+		args.recommend = false
+		args.cpu = 0
+		args.memory = ""
+		args.top = 1
+	})
+
+	ids := func(machineTypes ocm.MachineTypeList) []string {
+		var result []string
+		for _, machineType := range machineTypes {
+			result = append(result, machineType.MachineType.ID())
+		}
+		return result
+	}
+
+	Describe("validateRecommendFlags", func() {
+		newFlags := func(argv ...string) *pflag.FlagSet {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.Int("cpu", 0, "")
+			flags.Int("top", 1, "")
+			flags.Bool("count", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			return flags
+		}
+
+		It("requires '--recommend' for the requirements", func() {
+			err := validateRecommendFlags(newFlags("--cpu", "8"))
+			Expect(err).To(MatchError("The '--cpu' flag can only be used together with '--recommend'"))
+		})
+
+		It("requires at least one requirement", func() {
+			args.recommend = true
+			err := validateRecommendFlags(newFlags())
+			Expect(err).To(MatchError("The '--recommend' flag requires '--cpu', '--memory' or both"))
+		})
+
+		It("rejects the flags that list the instance types", func() {
+			args.recommend = true
+			args.cpu = 8
+			err := validateRecommendFlags(newFlags("--count"))
+			Expect(err).To(MatchError("The '--recommend' flag can't be used together with '--count'"))
+		})
+	})
+
+	It("returns the instance type with the smallest over-provisioning", func() {
+		recommended, err := recommendMachineTypes(machineTypes, 8, 34359738368, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(recommended)).To(Equal([]string{"m5.2xlarge"}))
+	})
+
+	It("ranks the best fits and skips the instance types without quota", func() {
+		machineTypes[4].Available = false
+		recommended, err := recommendMachineTypes(machineTypes, 8, 0, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(recommended)).To(Equal([]string{"c5.2xlarge", "r5.2xlarge", "m5.4xlarge"}))
+	})
+
+	It("lists the closest misses when nothing satisfies the requirements", func() {
+		_, err := recommendMachineTypes(machineTypes, 32, 137438953472, 1)
+		Expect(err).To(MatchError("No available instance type has at least 32 CPU cores and 128.0 GiB of " +
+			"memory. The closest ones are 'm5.4xlarge' (16 CPU cores, 64.0 GiB), 'r5.2xlarge' (8 CPU cores, " +
+			"64.0 GiB), 'm5.2xlarge' (8 CPU cores, 32.0 GiB)"))
+	})
+})
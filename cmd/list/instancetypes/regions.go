@@ -0,0 +1,339 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/instancetypes"
+	"github.com/openshift/rosa/pkg/interactive"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// allRegionsValue is the value of the '--region' flag that lists the instance types of every region.
+const allRegionsValue = "all"
+
+// defaultConcurrency is the number of regions whose instance types are fetched at the same time when
+// the '--concurrency' flag isn't given, so that OCM doesn't receive a burst of requests when there
+// are many regions.
+const defaultConcurrency = 4
+
+// allRegionsConflictingFlags are the flags that select something inside a single region.
+var allRegionsConflictingFlags = []string{"availability-zones", "region-prefix", "diff-region", "cluster", "group-by",
+	"validate-quota", "raw", "recommend", "summary", "ensure-multi-az", "filter-exec"}
+
+func validateAllRegionsFlag(flags *pflag.FlagSet) error {
+	for _, name := range allRegionsConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--region %s' flag can't be used together with '--%s'", allRegionsValue, name)
+		}
+	}
+	return nil
+}
+
+// regionLister returns the machine types available in a region.
+type regionLister func(region string) (ocm.MachineTypeList, error)
+
+// regionResult is the outcome of fetching the machine types of one region.
+type regionResult struct {
+	region       string
+	machineTypes ocm.MachineTypeList
+	err          error
+}
+
+// fetchRegions calls list for each region from a pool of concurrency workers, and sends the result
+// of each region to the returned channel as soon as it is available, so in the order in which they
+// complete. The channel is closed after the last region, and the caller must read it until then.
+func fetchRegions(regions []string, concurrency int, list regionLister) <-chan regionResult {
+	jobs := make(chan string)
+	results := make(chan regionResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(regions); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for region := range jobs {
+				machineTypes, err := list(region)
+				results <- regionResult{
+					region:       region,
+					machineTypes: machineTypes,
+					err:          err,
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, region := range regions {
+			jobs <- region
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// fetchAllRegions is like fetchRegions, but it waits for all the regions. It returns the machine
+// types of the regions where the call succeeded and the errors of the rest.
+func fetchAllRegions(regions []string, concurrency int,
+	list regionLister) (map[string]ocm.MachineTypeList, map[string]error) {
+	byRegion := map[string]ocm.MachineTypeList{}
+	failures := map[string]error{}
+	for result := range fetchRegions(regions, concurrency, list) {
+		if result.err != nil {
+			failures[result.region] = result.err
+		} else {
+			byRegion[result.region] = result.machineTypes
+		}
+	}
+	return byRegion, failures
+}
+
+// renderRegionsProgressively returns true if the table of each region can be written as soon as
+// the region is fetched. That isn't possible when the result depends on the instance types of all
+// the regions, like the count or the maximum number of results, or when a filter may ask a
+// question or warn about the whole list, or when the list is also written to a file in another format.
+func renderRegionsProgressively() bool {
+	return !output.HasFlag() && output.FileFormat() == "" && !args.count && !limited() &&
+		!interactive.Enabled() && len(args.categories) == 0 && args.priceTier == "" && !args.supportsIPv6
+}
+
+// reportRegionFailures warns about the regions that failed, in the order of the list of regions,
+// and returns an error if all of them failed.
+func reportRegionFailures(r *rosa.Runtime, regions []string, failures map[string]error) error {
+	for _, region := range regions {
+		if err, failed := failures[region]; failed {
+			r.Reporter.Warnf("Failed to fetch instance types in region '%s': %v", region, err)
+		}
+	}
+	if len(regions) > 0 && len(failures) == len(regions) {
+		return rosa.UpstreamError(fmt.Errorf("Failed to fetch instance types in any region"))
+	}
+	return nil
+}
+
+// regionColumn returns the REGION column displayed in front of the selected ones.
+func regionColumn(regionOf map[*ocm.MachineType]string) column {
+	return column{
+		Name:   "region",
+		Header: "REGION",
+		Value: machineTypeValue(func(machineType *ocm.MachineType) string {
+			return regionOf[machineType]
+		}),
+	}
+}
+
+// listRegion returns the lister that fetches the machine types of a region with the flags given in
+// the command line.
+func listRegion(r *rosa.Runtime, awsClient aws.Client) regionLister {
+	return func(region string) (ocm.MachineTypeList, error) {
+		ctx, cancel := r.OperationContext()
+		defer cancel()
+		machineTypes, err := instancetypes.List(ctx, r.OCMClient, awsClient, instancetypes.Filters{
+			Region:     region,
+			HasQuota:   args.hasQuota,
+			RoleARN:    args.roleARN,
+			ExternalID: args.externalID,
+		})
+		return machineTypes, r.OperationError(ctx, err)
+	}
+}
+
+// streamAllRegions writes a table with the instance types of each region as soon as the region is
+// fetched, separated by empty lines. The regions that fail are reported at the end, without losing
+// the tables already written.
+func streamAllRegions(r *rosa.Runtime, w io.Writer, results <-chan regionResult, regions []string,
+	selectedColumns []column, minMemory uint64, gpu *bool) error {
+	failures := map[string]error{}
+	var err error
+	written := 0
+	for result := range results {
+		if result.err != nil {
+			failures[result.region] = result.err
+			continue
+		}
+		// Keep reading the results after a failure to write, so that the workers can finish:
+		if err != nil {
+			continue
+		}
+		var wrote bool
+		wrote, err = writeRegionTable(r, w, result, selectedColumns, minMemory, gpu, written > 0)
+		if wrote {
+			written++
+		}
+	}
+	if err != nil {
+		return err
+	}
+	err = reportRegionFailures(r, regions, failures)
+	if err != nil {
+		return err
+	}
+	if written == 0 && !args.quiet {
+		return errNoMachineTypes
+	}
+	return nil
+}
+
+// writeRegionTable writes the table of the instance types of one region, preceded by an empty line
+// if it isn't the first one. Nothing is written if none of the instance types pass the filters, and
+// the returned flag is false.
+func writeRegionTable(r *rosa.Runtime, w io.Writer, result regionResult, selectedColumns []column,
+	minMemory uint64, gpu *bool, separate bool) (bool, error) {
+	machineTypes, err := applyFilters(r, result.machineTypes, nil, minMemory, gpu)
+	if err != nil {
+		return false, err
+	}
+	if len(displayedMachineTypes(machineTypes)) == 0 {
+		return false, nil
+	}
+	sortMachineTypes(machineTypes, args.sort, args.reverse)
+	regionOf := map[*ocm.MachineType]string{}
+	for _, machineType := range machineTypes {
+		regionOf[machineType] = result.region
+	}
+	if separate {
+		fmt.Fprintln(w)
+	}
+	err = printTable(r, w, append([]column{regionColumn(regionOf)}, selectedColumns...), machineTypes, nil, gpu,
+		false)
+	return err == nil, err
+}
+
+// runAllRegions lists the instance types of every region, with a REGION column in front of the
+// selected ones. A region that fails is reported without stopping the rest.
+func runAllRegions(r *rosa.Runtime, regions []string, selectedColumns []column, minMemory uint64,
+	gpu *bool, timer *phaseTimer) error {
+	// Without a role the access keys were already resolved by newAccessKeysClient, so the workers
+	// share them:
+	awsClient := r.AWSClient
+	r.Reporter.Debugf("Fetching instance types in regions %s with %d concurrent requests", regions,
+		args.concurrency)
+	stop := timer.start("machine types")
+	if renderRegionsProgressively() {
+		defer stop()
+		return streamAllRegions(r, r.Writer, fetchRegions(regions, args.concurrency, listRegion(r, awsClient)),
+			regions, selectedColumns, minMemory, gpu)
+	}
+	stopSpinner := startSpinner(r)
+	byRegion, failures := fetchAllRegions(regions, args.concurrency, listRegion(r, awsClient))
+	stopSpinner()
+	stop()
+	err := reportRegionFailures(r, regions, failures)
+	if err != nil {
+		return err
+	}
+	var fetched []string
+	for _, region := range regions {
+		if _, failed := failures[region]; !failed {
+			fetched = append(fetched, region)
+		}
+	}
+
+	// Filter and sort the instance types of all the regions together, remembering the region of
+	// each one:
+	var machineTypes ocm.MachineTypeList
+	regionOf := map[*ocm.MachineType]string{}
+	for _, region := range fetched {
+		for _, machineType := range byRegion[region] {
+			regionOf[machineType] = region
+			machineTypes = append(machineTypes, machineType)
+		}
+	}
+	if len(machineTypes) == 0 && !args.count && !args.quiet {
+		return errNoMachineTypes
+	}
+	if len(machineTypes) > 0 {
+		machineTypes, err = applyFilters(r, machineTypes, args.categories, minMemory, gpu)
+		if err != nil {
+			return err
+		}
+		machineTypes, err = applyPriceTier(r, machineTypes)
+		if err != nil {
+			return err
+		}
+		machineTypes = applyIPv6Filter(r, machineTypes)
+	}
+	if args.count {
+		return printCount(r.Writer, len(machineTypes))
+	}
+	sortMachineTypes(machineTypes, args.sort, args.reverse)
+	sortByRegion(machineTypes, fetched, regionOf)
+
+	csvOutput := output.Output() == output.CSV
+	if (output.HasFlag() && !csvOutput) || output.FileFormat() != "" {
+		result, err := groupByRegion(limitResults(machineTypes), fetched, regionOf)
+		if err != nil {
+			return err
+		}
+		err = output.WriteFile(result)
+		if err != nil {
+			return err
+		}
+		if output.HasFlag() && !csvOutput {
+			return output.Print(r.Writer, result)
+		}
+	}
+
+	return printTable(r, r.Writer, append([]column{regionColumn(regionOf)}, selectedColumns...), machineTypes, nil,
+		gpu, csvOutput)
+}
+
+// sortByRegion sorts the machine types in the order of their regions, keeping the order of the
+// machine types of the same region.
+func sortByRegion(machineTypes ocm.MachineTypeList, regions []string, regionOf map[*ocm.MachineType]string) {
+	index := map[string]int{}
+	for i, region := range regions {
+		index[region] = i
+	}
+	sort.SliceStable(machineTypes, func(i, j int) bool {
+		return index[regionOf[machineTypes[i]]] < index[regionOf[machineTypes[j]]]
+	})
+}
+
+// groupByRegion returns the JSON representation of the machine types of each region, including the
+// regions without any.
+func groupByRegion(machineTypes ocm.MachineTypeList, regions []string,
+	regionOf map[*ocm.MachineType]string) (map[string]interface{}, error) {
+	grouped := map[string][]*cmv1.MachineType{}
+	for _, machineType := range machineTypes {
+		region := regionOf[machineType]
+		grouped[region] = append(grouped[region], machineType.MachineType)
+	}
+	result := map[string]interface{}{}
+	for _, region := range regions {
+		var b bytes.Buffer
+		err := cmv1.MarshalMachineTypeList(grouped[region], &b)
+		if err != nil {
+			return nil, err
+		}
+		result[region] = json.RawMessage(b.Bytes())
+	}
+	return result, nil
+}
@@ -0,0 +1,209 @@
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// countingKeysClient is an AWS client that counts how many times its access keys are requested.
+type countingKeysClient struct {
+	aws.Client
+	lock  sync.Mutex
+	calls int
+	err   error
+}
+
+func (c *countingKeysClient) GetAWSAccessKeys() (*aws.AccessKey, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &aws.AccessKey{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"}, nil
+}
+
+var _ = Describe("All regions", func() {
+	regions := []string{"eu-west-1", "us-east-1", "us-east-2", "us-west-2", "ap-south-1"}
+
+	It("resolves the access keys once for all the workers", func() {
+		client := &countingKeysClient{}
+		awsClient, err := withAccessKeys(client)
+		Expect(err).NotTo(HaveOccurred())
+		_, failures := fetchAllRegions(regions, 3, func(region string) (ocm.MachineTypeList, error) {
+			accessKeys, err := awsClient.GetAWSAccessKeys()
+			if err != nil {
+				return nil, err
+			}
+			Expect(accessKeys.AccessKeyID).To(Equal("AKIAEXAMPLE"))
+			return ocm.MachineTypeList{}, nil
+		})
+		Expect(failures).To(BeEmpty())
+		Expect(client.calls).To(Equal(1))
+	})
+
+	It("streams the regions from workers that share the access keys", func() {
+		client := &countingKeysClient{}
+		awsClient, err := withAccessKeys(client)
+		Expect(err).NotTo(HaveOccurred())
+		results := fetchRegions(regions, defaultConcurrency, func(region string) (ocm.MachineTypeList, error) {
+			_, err := awsClient.GetAWSAccessKeys()
+			return ocm.MachineTypeList{}, err
+		})
+		var fetched []string
+		for result := range results {
+			Expect(result.err).NotTo(HaveOccurred())
+			fetched = append(fetched, result.region)
+		}
+		Expect(fetched).To(ConsistOf(regions))
+		Expect(client.calls).To(Equal(1))
+	})
+
+	It("reports the access keys that can't be resolved", func() {
+		client := &countingKeysClient{err: fmt.Errorf("AccessDenied")}
+		_, err := withAccessKeys(client)
+		Expect(err).To(MatchError("Failed to get the AWS access keys: AccessDenied"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitAuth))
+	})
+
+	It("fetches every region without exceeding the concurrency limit", func() {
+		var lock sync.Mutex
+		running, maxRunning := 0, 0
+		byRegion, failures := fetchAllRegions(regions, 2, func(region string) (ocm.MachineTypeList, error) {
+			lock.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			lock.Unlock()
+			defer func() {
+				lock.Lock()
+				running--
+				lock.Unlock()
+			}()
+			if region == "us-east-2" {
+				return nil, fmt.Errorf("throttled")
+			}
+			return ocm.MachineTypeList{
+				buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			}, nil
+		})
+		Expect(maxRunning).To(BeNumerically("<=", 2))
+		Expect(byRegion).To(HaveLen(4))
+		Expect(byRegion).NotTo(HaveKey("us-east-2"))
+		Expect(failures).To(HaveLen(1))
+		Expect(failures["us-east-2"]).To(MatchError("throttled"))
+	})
+
+	It("sends each region as soon as it completes", func() {
+		release := make(chan struct{})
+		results := fetchRegions([]string{"eu-west-1", "us-east-1"}, 2, func(region string) (ocm.MachineTypeList, error) {
+			if region == "eu-west-1" {
+				<-release
+			}
+			return ocm.MachineTypeList{}, nil
+		})
+		Expect((<-results).region).To(Equal("us-east-1"))
+		close(release)
+		Expect((<-results).region).To(Equal("eu-west-1"))
+		Eventually(results).Should(BeClosed())
+	})
+
+	Describe("progressive rendering", func() {
+		var r *rosa.Runtime
+		var out, messages bytes.Buffer
+
+		BeforeEach(func() {
+			out.Reset()
+			messages.Reset()
+			var err error
+			r = &rosa.Runtime{}
+			r.Reporter, err = reporter.New().Stream(&messages).Build()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		send := func(results ...regionResult) <-chan regionResult {
+			channel := make(chan regionResult, len(results))
+			for _, result := range results {
+				channel <- result
+			}
+			close(channel)
+			return channel
+		}
+
+		It("writes a table per region and reports the failures at the end", func() {
+			selectedColumns, err := selectColumns([]string{"id"})
+			Expect(err).NotTo(HaveOccurred())
+			m5 := buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184)
+			r5 := buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368)
+			results := send(
+				regionResult{region: "us-east-1", machineTypes: ocm.MachineTypeList{m5}},
+				regionResult{region: "us-east-2", err: fmt.Errorf("throttled")},
+				regionResult{region: "eu-west-1", machineTypes: ocm.MachineTypeList{r5}},
+			)
+			err = streamAllRegions(r, &out, results, []string{"eu-west-1", "us-east-1", "us-east-2"},
+				selectedColumns, 0, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(Equal("" +
+				"REGION     ID  \n" +
+				"us-east-1  m5.xlarge\n" +
+				"\n" +
+				"REGION     ID  \n" +
+				"eu-west-1  r5.xlarge\n"))
+			Expect(messages.String()).To(Equal("WARN: Failed to fetch instance types in region 'us-east-2': throttled\n"))
+		})
+
+		It("fails if every region fails", func() {
+			results := send(regionResult{region: "us-east-1", err: fmt.Errorf("throttled")})
+			err := streamAllRegions(r, &out, results, []string{"us-east-1"}, nil, 0, nil)
+			Expect(err).To(MatchError("Failed to fetch instance types in any region"))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUpstream))
+			Expect(out.Len()).To(BeZero())
+		})
+	})
+
+	It("sorts and groups the instance types by region", func() {
+		m5 := buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184)
+		r5 := buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368)
+		c5 := buildMachineType("c5.xlarge", cmv1.MachineTypeCategoryComputeOptimized, 4, 8589934592)
+		regionOf := map[*ocm.MachineType]string{m5: "us-east-1", r5: "eu-west-1", c5: "us-east-1"}
+		machineTypes := ocm.MachineTypeList{m5, r5, c5}
+
+		sortByRegion(machineTypes, []string{"eu-west-1", "us-east-1", "us-west-2"}, regionOf)
+		Expect(machineTypes.IDs()).To(Equal([]string{"r5.xlarge", "m5.xlarge", "c5.xlarge"}))
+
+		result, err := groupByRegion(machineTypes, []string{"eu-west-1", "us-east-1", "us-west-2"}, regionOf)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := json.Marshal(result)
+		Expect(err).NotTo(HaveOccurred())
+		var grouped map[string][]map[string]interface{}
+		Expect(json.Unmarshal(data, &grouped)).To(Succeed())
+		Expect(grouped).To(HaveLen(3))
+		Expect(grouped["eu-west-1"]).To(HaveLen(1))
+		Expect(grouped["eu-west-1"][0]).To(HaveKeyWithValue("id", "r5.xlarge"))
+		Expect(grouped["us-east-1"]).To(HaveLen(2))
+		Expect(grouped["us-west-2"]).To(BeEmpty())
+	})
+
+	It("can't be used with flags that select something inside a region", func() {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.StringSlice("availability-zones", nil, "")
+		flags.String("region-prefix", "", "")
+		Expect(validateAllRegionsFlag(flags)).To(Succeed())
+		Expect(flags.Parse([]string{"--region-prefix", "eu"})).To(Succeed())
+		Expect(validateAllRegionsFlag(flags)).To(MatchError(
+			"The '--region all' flag can't be used together with '--region-prefix'"))
+	})
+})
@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+
+	"github.com/openshift/rosa/pkg/helper"
+)
+
+// workerRole is the node role of the machine pools, the only one whose instance type is chosen by
+// the user. The machine types returned by OCM are the ones valid for it.
+const workerRole = "worker"
+
+// nodeRoles are the values of the '--role' flag.
+var nodeRoles = []string{workerRole, "control-plane", "infra"}
+
+// validateRole checks that the instance types of the given node role can be listed. OCM selects the
+// instance types of the control plane and infra nodes from the size of the cluster, and doesn't
+// report which ones it can use, so only the worker role is supported.
+func validateRole(role string) error {
+	if !helper.Contains(nodeRoles, role) {
+		return fmt.Errorf("Invalid node role '%s'. Allowed values are %s", role, nodeRoles)
+	}
+	if role != workerRole {
+		return fmt.Errorf("The instance types of the '%s' nodes can't be listed, as OCM only returns "+
+			"the instance types of the worker nodes and selects the ones of the '%s' nodes from the size "+
+			"of the cluster. Only the '%s' role is supported", role, role, workerRole)
+	}
+	return nil
+}
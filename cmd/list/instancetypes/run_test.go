@@ -0,0 +1,956 @@
+package instancetypes
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/aws/mocks"
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/interactive"
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Run", func() {
+	var r *rosa.Runtime
+	var out bytes.Buffer
+	var saved struct {
+		sort, minMemory, memoryUnit, architecture string
+		columns                                   []string
+	}
+
+	BeforeEach(func() {
+		out.Reset()
+		r = &rosa.Runtime{
+			Reporter: reporter.CreateReporterOrExit(),
+			Logger:   logging.NewLogger(),
+			Writer:   &out,
+		}
+		saved.sort = args.sort
+		saved.minMemory = args.minMemory
+		saved.memoryUnit = args.memoryUnit
+		saved.architecture = args.architecture
+		saved.columns = args.columns
+	})
+
+	AfterEach(func() {
+		args.sort = saved.sort
+		args.minMemory = saved.minMemory
+		args.memoryUnit = saved.memoryUnit
+		args.architecture = saved.architecture
+		args.columns = saved.columns
+	})
+
+	It("rejects an invalid sort key", func() {
+		args.sort = "price"
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError(ContainSubstring("Invalid sort key 'price'")))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+	})
+
+	DescribeTable("node roles",
+		func(role string, expectedError string) {
+			err := validateRole(role)
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("worker", "worker", ""),
+		Entry("control plane", "control-plane",
+			"The instance types of the 'control-plane' nodes can't be listed, as OCM only returns the "+
+				"instance types of the worker nodes"),
+		Entry("infra", "infra", "The instance types of the 'infra' nodes can't be listed"),
+		Entry("unknown", "master", "Invalid node role 'master'. Allowed values are [worker control-plane infra]"),
+	)
+
+	It("rejects an invalid column", func() {
+		args.columns = []string{"id", "price"}
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError(ContainSubstring("Invalid column 'price'")))
+	})
+
+	It("rejects an invalid minimum memory", func() {
+		args.minMemory = "lots"
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError(ContainSubstring("Invalid memory size 'lots'")))
+	})
+
+	It("rejects --wide together with --columns", func() {
+		Expect(Cmd.Flags().Set("columns", "id")).To(Succeed())
+		args.wide = true
+		defer func() {
+			args.wide = false
+			Cmd.Flags().Lookup("columns").Changed = false
+		}()
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError("The '--wide' flag can't be used together with '--columns'"))
+	})
+
+	It("rejects an invalid memory unit", func() {
+		args.memoryUnit = "octets"
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError(ContainSubstring("Invalid memory unit 'octets'")))
+	})
+
+	It("rejects an external ID without a role ARN", func() {
+		args.externalID = "8f4a2c1e"
+		defer func() {
+			args.externalID = ""
+		}()
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError("The '--external-id' flag can only be used together with '--role-arn'"))
+	})
+
+	It("rejects a malformed role ARN before making any request", func() {
+		args.roleARN = "arn:aws:iam::123456789012:ManagedOpenShift-Installer-Role"
+		defer func() {
+			args.roleARN = ""
+		}()
+		err := preRunE(Cmd, nil)
+		Expect(err).To(MatchError(ContainSubstring("Invalid role ARN")))
+	})
+
+	It("rejects a negative maximum number of results", func() {
+		args.maxResults = -1
+		defer func() {
+			args.maxResults = 0
+		}()
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError("Invalid maximum number of results -1. It must be zero or greater"))
+	})
+
+	It("rejects --include-unavailable together with --has-quota", func() {
+		Expect(Cmd.Flags().Set("has-quota", "true")).To(Succeed())
+		args.includeUnavailable = true
+		defer func() {
+			args.includeUnavailable = false
+			Cmd.Flags().Lookup("has-quota").Changed = false
+		}()
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError("The '--include-unavailable' flag can't be used together with '--has-quota'"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+	})
+
+	It("rejects a concurrency lower than one", func() {
+		args.concurrency = 0
+		defer func() {
+			args.concurrency = defaultConcurrency
+		}()
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError("Invalid concurrency 0. It must be greater than zero"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+	})
+
+	It("rejects a negative cache TTL", func() {
+		args.cacheTTL = -time.Minute
+		defer func() {
+			args.cacheTTL = ocm.DefaultRegionCacheTTL
+		}()
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError("The value of the '--cache-ttl' flag can't be negative"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+	})
+
+	Describe("output file", func() {
+		AfterEach(func() {
+			args.explain = false
+			Expect(Cmd.Flags().Set("output-file", "")).To(Succeed())
+		})
+
+		It("writes the results to the runtime writer without the flag", func() {
+			args.explain = true
+			Expect(runE(Cmd, nil, r)).To(Succeed())
+
+			var b bytes.Buffer
+			Expect(explain(&b)).To(Succeed())
+			Expect(out.String()).To(Equal(b.String()))
+		})
+
+		It("writes the results to the file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "explain.txt")
+			Expect(Cmd.Flags().Set("output-file", path)).To(Succeed())
+			args.explain = true
+			Expect(runE(Cmd, nil, r)).To(Succeed())
+
+			var b bytes.Buffer
+			Expect(explain(&b)).To(Succeed())
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(b.String()))
+			Expect(out.Len()).To(BeZero())
+		})
+
+		It("fails if the file can't be created", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "missing", "explain.txt")
+			Expect(Cmd.Flags().Set("output-file", path)).To(Succeed())
+			args.explain = true
+			err := runE(Cmd, nil, r)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Failed to create output file: "))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+		})
+	})
+
+	Describe("messages", func() {
+		var apiServer *ghttp.Server
+		var errOut bytes.Buffer
+
+		BeforeEach(func() {
+			errOut.Reset()
+			var err error
+			r.Reporter, err = reporter.New().Stream(&errOut).Build()
+			Expect(err).NotTo(HaveOccurred())
+			apiServer = MakeTCPServer()
+			r.OCMClient, err = ocm.NewClient().
+				Logger(logging.NewLogger()).
+				Config(&config.Config{
+					URL:         apiServer.URL(),
+					AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				}).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			args.all = false
+			args.noHeaders = false
+			Expect(Cmd.Flags().Set("output", "")).To(Succeed())
+			Expect(r.OCMClient.Close()).To(Succeed())
+			apiServer.Close()
+		})
+
+		It("writes the warnings to the reporter and only the result to the output", func() {
+			apiServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/machine_types"),
+					RespondWithJSON(http.StatusOK, `{
+					  "kind": "MachineTypeList",
+					  "page": 1,
+					  "size": 1,
+					  "total": 1,
+					  "items": [{
+					    "kind": "MachineType",
+					    "id": "m5.xlarge",
+					    "category": "general_purpose",
+					    "cpu": {"value": 4, "unit": "vCPU"},
+					    "memory": {"value": 17179869184, "unit": "B"}
+					  }]
+					}`),
+				),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account", "organization": {"id": "123"}}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "QuotaCostList", "page": 1, "size": 0, "total": 0}`),
+			)
+			args.all = true
+			args.noHeaders = true
+			Expect(Cmd.Flags().Set("output", "json")).To(Succeed())
+			Expect(runE(Cmd, nil, r)).To(Succeed())
+			Expect(errOut.String()).To(Equal("WARN: The '--no-headers' flag is ignored when the output isn't a table\n"))
+			Expect(out.String()).To(HavePrefix("["))
+			Expect(out.String()).To(ContainSubstring(`"id": "m5.xlarge"`))
+			Expect(out.String()).NotTo(ContainSubstring("WARN"))
+		})
+
+		It("exits with the no-results code when there are no instance types to list", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{"kind": "MachineTypeList", "page": 1, "size": 0, "total": 0}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account", "organization": {"id": "123"}}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "QuotaCostList", "page": 1, "size": 0, "total": 0}`),
+			)
+			args.all = true
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError(ContainSubstring("There are no machine types supported for your account")))
+			Expect(rosa.ExitCode(err)).To(Equal(6))
+			Expect(Cmd.Long).To(ContainSubstring("  6  There were no instance types to list."))
+		})
+
+		It("prints the table and writes the file in the format given with --format", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "MachineTypeList",
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [{
+				    "kind": "MachineType",
+				    "id": "m5.xlarge",
+				    "category": "general_purpose",
+				    "cpu": {"value": 4, "unit": "vCPU"},
+				    "memory": {"value": 17179869184, "unit": "B"}
+				  }]
+				}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account", "organization": {"id": "123"}}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "QuotaCostList", "page": 1, "size": 0, "total": 0}`),
+			)
+			path := filepath.Join(GinkgoT().TempDir(), "instance-types.json")
+			Expect(Cmd.Flags().Set("output-file", path)).To(Succeed())
+			Expect(Cmd.Flags().Set("format", "json")).To(Succeed())
+			defer func() {
+				Expect(Cmd.Flags().Set("output-file", "")).To(Succeed())
+				Expect(Cmd.Flags().Set("format", "")).To(Succeed())
+				Cmd.Flags().Lookup("format").Changed = false
+			}()
+			args.all = true
+			Expect(runE(Cmd, nil, r)).To(Succeed())
+			Expect(out.String()).To(HavePrefix("ID"))
+			Expect(out.String()).To(ContainSubstring("m5.xlarge"))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HavePrefix("["))
+			Expect(string(content)).To(ContainSubstring(`"id": "m5.xlarge"`))
+		})
+	})
+
+	Describe("node role", func() {
+		var apiServer *ghttp.Server
+
+		BeforeEach(func() {
+			apiServer = MakeTCPServer()
+			var err error
+			r.OCMClient, err = ocm.NewClient().
+				Logger(logging.NewLogger()).
+				Config(&config.Config{
+					URL:         apiServer.URL(),
+					AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				}).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			args.all = true
+		})
+
+		AfterEach(func() {
+			args.all = false
+			args.role = workerRole
+			Expect(r.OCMClient.Close()).To(Succeed())
+			apiServer.Close()
+		})
+
+		It("lists the instance types of the worker nodes by default", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "MachineTypeList",
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [{
+				    "kind": "MachineType",
+				    "id": "m5.xlarge",
+				    "category": "general_purpose",
+				    "cpu": {"value": 4, "unit": "vCPU"},
+				    "memory": {"value": 17179869184, "unit": "B"}
+				  }]
+				}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account", "organization": {"id": "123"}}`),
+				RespondWithJSON(http.StatusOK, `{"kind": "QuotaCostList", "page": 1, "size": 0, "total": 0}`),
+			)
+			Expect(Cmd.Flags().Lookup("role").DefValue).To(Equal(workerRole))
+			Expect(runE(Cmd, nil, r)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("m5.xlarge"))
+		})
+
+		DescribeTable("rejects the roles whose instance types OCM doesn't return",
+			func(role string) {
+				args.role = role
+				err := runE(Cmd, nil, r)
+				Expect(err).To(MatchError(ContainSubstring(
+					"The instance types of the '" + role + "' nodes can't be listed, as OCM only returns " +
+						"the instance types of the worker nodes")))
+				Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+				Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+			},
+			Entry("control plane", "control-plane"),
+			Entry("infra", "infra"),
+		)
+	})
+
+	Describe("file format", func() {
+		AfterEach(func() {
+			Expect(Cmd.Flags().Set("output-file", "")).To(Succeed())
+			Expect(Cmd.Flags().Set("format", "")).To(Succeed())
+			Cmd.Flags().Lookup("format").Changed = false
+			Cmd.Flags().Lookup("count").Changed = false
+			args.count = false
+		})
+
+		It("requires an output file", func() {
+			Expect(Cmd.Flags().Set("format", "yaml")).To(Succeed())
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError("The '--format' flag can only be used together with '--output-file'"))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+		})
+
+		It("can't be combined with the count", func() {
+			Expect(Cmd.Flags().Set("output-file", filepath.Join(GinkgoT().TempDir(), "count.yaml"))).To(Succeed())
+			Expect(Cmd.Flags().Set("format", "yaml")).To(Succeed())
+			Expect(Cmd.Flags().Set("count", "true")).To(Succeed())
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError("The '--format' flag can't be used together with '--count'"))
+		})
+	})
+
+	It("rejects an invalid architecture", func() {
+		args.architecture = "ppc64le"
+		err := runE(Cmd, nil, r)
+		Expect(err).To(MatchError(ContainSubstring("Invalid architecture 'ppc64le'")))
+	})
+
+	DescribeTable("validateAllFlag",
+		func(argv []string, expectedError string) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.Bool("all", false, "")
+			flags.StringSlice("availability-zones", nil, "")
+			flags.Bool("has-quota", true, "")
+			flags.String("region", "", "")
+			flags.String("role-arn", "", "")
+			Expect(flags.Parse(argv)).To(Succeed())
+
+			err := validateAllFlag(flags)
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedError))
+			}
+		},
+		Entry("--all alone", []string{"--all"}, ""),
+		Entry("filters without --all", []string{"--region", "us-east-1", "--has-quota=false"}, ""),
+		Entry("--all=false with filters", []string{"--all=false", "--role-arn", "arn"}, ""),
+		Entry("--all with --availability-zones", []string{"--all", "--availability-zones", "us-east-1a"},
+			"The '--all' flag can't be used together with '--availability-zones'"),
+		Entry("--all with --has-quota", []string{"--all", "--has-quota=false"},
+			"The '--all' flag can't be used together with '--has-quota'"),
+		Entry("--all with --region", []string{"--all", "--region", "us-east-1"},
+			"The '--all' flag can't be used together with '--region'"),
+		Entry("--all with --role-arn", []string{"--all", "--role-arn", "arn"},
+			"The '--all' flag can't be used together with '--role-arn'"),
+	)
+
+	Describe("availability zones", func() {
+		regionZones := []string{"us-east-1a", "us-east-1b", "us-east-1c"}
+
+		AfterEach(func() {
+			selectAvailabilityZones = interactive.GetMultipleOptions
+			Expect(Cmd.Flags().Set("interactive", "false")).To(Succeed())
+		})
+
+		It("drops the zones that don't belong to the region", func() {
+			zones, err := selectZones(r, Cmd, "us-east-1", regionZones,
+				[]string{"us-east-1a", "us-west-2a", "us-east-1c", "eu-west-1b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zones).To(Equal([]string{"us-east-1a", "us-east-1c"}))
+		})
+
+		It("fails if none of the zones belong to the region", func() {
+			_, err := selectZones(r, Cmd, "us-east-1", regionZones, []string{"us-west-2a"})
+			Expect(err).To(MatchError(ContainSubstring(
+				"None of the availability zones belong to region 'us-east-1'")))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+		})
+
+		It("rejects zones that don't belong to the region with --strict", func() {
+			args.strict = true
+			defer func() {
+				args.strict = false
+			}()
+			_, err := selectZones(r, Cmd, "us-east-1", regionZones, []string{"us-east-1a", "us-west-2a"})
+			Expect(err).To(MatchError(ContainSubstring(
+				"Availability zone 'us-west-2a' doesn't belong to region 'us-east-1'")))
+		})
+
+		It("expands 'all' to every zone of the region", func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			defer mockCtrl.Finish()
+			mockEC2API := mocks.NewMockEC2API(mockCtrl)
+			mockEC2API.EXPECT().DescribeAvailabilityZonesWithContext(gomock.Any(), gomock.Any()).Return(
+				&ec2.DescribeAvailabilityZonesOutput{
+					AvailabilityZones: []*ec2.AvailabilityZone{
+						{ZoneName: awssdk.String("us-east-1a")},
+						{ZoneName: awssdk.String("us-east-1b")},
+						{ZoneName: awssdk.String("us-east-1c")},
+					},
+				}, nil)
+			r.AWSClient = aws.New(
+				logrus.New(),
+				mocks.NewMockIAMAPI(mockCtrl),
+				mockEC2API,
+				mocks.NewMockOrganizationsAPI(mockCtrl),
+				mocks.NewMockS3API(mockCtrl),
+				mocks.NewMockSecretsManagerAPI(mockCtrl),
+				mocks.NewMockSTSAPI(mockCtrl),
+				mocks.NewMockCloudFormationAPI(mockCtrl),
+				mocks.NewMockServiceQuotasAPI(mockCtrl),
+				&session.Session{Config: &awssdk.Config{Region: awssdk.String("us-east-1")}},
+				&aws.AccessKey{},
+			)
+
+			zones, err := resolveAvailabilityZones(r, Cmd, "us-east-1", []string{"all"}, newPhaseTimer())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zones).To(Equal(regionZones))
+		})
+
+		It("rejects 'all' together with other zones", func() {
+			Expect(validateZonesFlag([]string{"all"})).To(Succeed())
+			Expect(validateZonesFlag([]string{"us-east-1a", "all"})).To(MatchError(
+				"The 'all' value of the '--availability-zones' flag can't be combined with other zones"))
+		})
+
+		It("uses the zones given with the flag", func() {
+			zones, err := selectZones(r, Cmd, "us-east-1", regionZones, []string{"us-east-1b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zones).To(Equal([]string{"us-east-1b"}))
+		})
+
+		It("uses the zones selected interactively instead of the flag", func() {
+			interactive.Enable()
+			var input interactive.Input
+			selectAvailabilityZones = func(i interactive.Input) ([]string, error) {
+				input = i
+				return []string{"us-east-1c"}, nil
+			}
+
+			zones, err := selectZones(r, Cmd, "us-east-1", regionZones, []string{"us-east-1a", "us-east-1b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(input.Options).To(Equal(regionZones))
+			Expect(input.Default).To(Equal([]string{"us-east-1a", "us-east-1b"}))
+			Expect(zones).To(Equal([]string{"us-east-1c"}))
+
+		})
+	})
+
+	Describe("interactive columns", func() {
+		AfterEach(func() {
+			selectOutputColumns = interactive.GetMultipleOptions
+		})
+
+		It("offers all the columns with the default ones selected", func() {
+			var input interactive.Input
+			selectOutputColumns = func(i interactive.Input) ([]string, error) {
+				input = i
+				return []string{"id", "gpu"}, nil
+			}
+
+			selected, err := selectColumnsInteractively(Cmd)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(input.Options).To(Equal(columnNames()))
+			Expect(input.Default).To(Equal(defaultColumns))
+			Expect(newTable(selected).HeaderRow()).To(Equal("ID\tGPU\t\n"))
+			Expect(hasColumn(selected, gpuColumn)).To(BeTrue())
+			Expect(hasColumn(selected, zonesColumn)).To(BeFalse())
+		})
+	})
+
+	Describe("paging", func() {
+		newFlags := func(argv ...string) *pflag.FlagSet {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.IntVar(&args.page, "page", 1, "")
+			flags.IntVar(&args.size, "size", 100, "")
+			flags.StringSlice("availability-zones", nil, "")
+			flags.Bool("stream", false, "")
+			flags.Bool("jsonl", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			return flags
+		}
+
+		AfterEach(func() {
+			args.page = 1
+			args.size = 100
+		})
+
+		It("fetches everything when neither flag is given", func() {
+			paged, err := validatePageFlags(newFlags())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paged).To(BeFalse())
+		})
+
+		It("fetches a page when only the size is given", func() {
+			paged, err := validatePageFlags(newFlags("--size", "20"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paged).To(BeTrue())
+			Expect(args.page).To(Equal(1))
+		})
+
+		It("rejects a page lower than one", func() {
+			_, err := validatePageFlags(newFlags("--page", "0"))
+			Expect(err).To(MatchError("Invalid page 0. It must be greater than zero"))
+		})
+
+		It("rejects paging the availability zones", func() {
+			_, err := validatePageFlags(newFlags("--page", "2", "--availability-zones", "us-east-1a"))
+			Expect(err).To(MatchError(
+				"The '--page' and '--size' flags can't be used together with '--availability-zones'"))
+		})
+
+		It("prints the page and the total", func() {
+			args.page = 2
+			args.size = 20
+			var b bytes.Buffer
+			rep, err := reporter.New().Stream(&b).Build()
+			Expect(err).NotTo(HaveOccurred())
+			printPageInfo(&rosa.Runtime{Reporter: rep}, 45, false)
+			Expect(b.String()).To(Equal("INFO: Page 2 of 3, 45 instance types in total\n"))
+		})
+	})
+
+	Describe("offline mode", func() {
+		newFlags := func(argv ...string) *pflag.FlagSet {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringSlice("availability-zones", nil, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			return flags
+		}
+
+		AfterEach(func() {
+			args.roleARN = ""
+			args.offline = ""
+		})
+
+		It("requires a role, as AWS credentials can't be replayed", func() {
+			err := validateOfflineFlags(newFlags(), "cassettes")
+			Expect(err).To(MatchError(ContainSubstring("Offline mode requires the '--role-arn' or the '--all' flag")))
+		})
+
+		It("can't record the responses that it replays", func() {
+			args.saveCassette = "cassette.json"
+			defer func() {
+				args.saveCassette = ""
+			}()
+			err := validateOfflineFlags(newFlags(), "cassettes")
+			Expect(err).To(MatchError(ContainSubstring(
+				"The '--save-cassette' flag can't be used in offline mode")))
+		})
+
+		It("rejects the availability zones", func() {
+			args.roleARN = "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+			err := validateOfflineFlags(newFlags("--availability-zones", "us-east-1a"), "cassettes")
+			Expect(err).To(MatchError(ContainSubstring(
+				"The '--availability-zones' flag can't be used in offline mode")))
+		})
+
+		It("fails clearly when the cassette directory is empty", func() {
+			args.roleARN = "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+			args.offline = GinkgoT().TempDir()
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError(ContainSubstring("No cassettes found in directory")))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+		})
+	})
+
+	Describe("supported regions", func() {
+		var apiServer *ghttp.Server
+
+		BeforeEach(func() {
+			apiServer = MakeTCPServer()
+			client, err := ocm.NewClient().
+				Logger(logging.NewLogger()).
+				Config(&config.Config{
+					URL:         apiServer.URL(),
+					AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				}).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			r.OCMClient = client
+		})
+
+		AfterEach(func() {
+			Expect(r.OCMClient.Close()).To(Succeed())
+			apiServer.Close()
+		})
+
+		It("stops with a clear error when they can't be retrieved", func() {
+			Expect(os.Setenv("AWS_REGION", "us-east-1")).To(Succeed())
+			defer os.Unsetenv("AWS_REGION")
+			apiServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/cloud_providers/aws/regions"),
+				RespondWithJSON(http.StatusBadRequest, `{
+				  "kind": "Error",
+				  "id": "400",
+				  "reason": "Invalid search"
+				}`),
+			))
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError(ContainSubstring("Failed to retrieve the regions supported by ROSA")))
+			Expect(err).To(MatchError(ContainSubstring("Invalid search")))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUpstream))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		Describe("region cache", func() {
+			const regionsResponse = `{
+			  "kind": "CloudRegionList",
+			  "page": 1,
+			  "size": 1,
+			  "total": 1,
+			  "items": [{"kind": "CloudRegion", "id": "us-east-1", "enabled": true}]
+			}`
+			const failure = `{"kind": "Error", "id": "400", "reason": "Invalid search"}`
+
+			BeforeEach(func() {
+				Expect(os.Setenv("AWS_REGION", "us-east-1")).To(Succeed())
+				r.OCMClient.EnableRegionCache()
+			})
+
+			AfterEach(func() {
+				Expect(os.Unsetenv("AWS_REGION")).To(Succeed())
+				args.refreshCache = false
+				args.cacheTTL = ocm.DefaultRegionCacheTTL
+			})
+
+			// populate fetches the supported regions, as a previous poll of '--watch' would:
+			populate := func() {
+				_, err := r.OCMClient.GetDatabaseRegionList()
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			It("fetches the cached region lists again in each poll with --refresh-cache", func() {
+				apiServer.AppendHandlers(
+					RespondWithJSON(http.StatusOK, regionsResponse),
+					RespondWithJSON(http.StatusBadRequest, failure),
+					RespondWithJSON(http.StatusOK, regionsResponse),
+					RespondWithJSON(http.StatusBadRequest, failure),
+				)
+				args.refreshCache = true
+				populate()
+				err := runE(Cmd, nil, r)
+				Expect(err).To(MatchError(ContainSubstring("Failed to retrieve the regions supported by ROSA")))
+				Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+				populate()
+				err = runE(Cmd, nil, r)
+				Expect(err).To(MatchError(ContainSubstring("Failed to retrieve the regions supported by ROSA")))
+				Expect(apiServer.ReceivedRequests()).To(HaveLen(4))
+			})
+
+			It("fetches the cached region lists again once they are older than --cache-ttl", func() {
+				apiServer.AppendHandlers(
+					RespondWithJSON(http.StatusOK, regionsResponse),
+					RespondWithJSON(http.StatusBadRequest, failure),
+				)
+				args.cacheTTL = time.Nanosecond
+				populate()
+				time.Sleep(time.Millisecond)
+				err := runE(Cmd, nil, r)
+				Expect(err).To(MatchError(ContainSubstring("Failed to retrieve the regions supported by ROSA")))
+				Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		It("keeps the AWS client of the previous poll", func() {
+			Expect(os.Setenv("AWS_REGION", "us-east-1")).To(Succeed())
+			defer os.Unsetenv("AWS_REGION")
+			mockCtrl := gomock.NewController(GinkgoT())
+			defer mockCtrl.Finish()
+			awsClient := aws.New(
+				logrus.New(),
+				mocks.NewMockIAMAPI(mockCtrl),
+				mocks.NewMockEC2API(mockCtrl),
+				mocks.NewMockOrganizationsAPI(mockCtrl),
+				mocks.NewMockS3API(mockCtrl),
+				mocks.NewMockSecretsManagerAPI(mockCtrl),
+				mocks.NewMockSTSAPI(mockCtrl),
+				mocks.NewMockCloudFormationAPI(mockCtrl),
+				mocks.NewMockServiceQuotasAPI(mockCtrl),
+				&session.Session{Config: &awssdk.Config{Region: awssdk.String("us-east-1")}},
+				&aws.AccessKey{AccessKeyID: "AKIA", SecretAccessKey: "secret"},
+			)
+			r.AWSClient = awsClient
+			apiServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/aws_inquiries/regions"),
+				RespondWithJSON(http.StatusBadRequest, `{
+				  "kind": "Error",
+				  "id": "400",
+				  "reason": "Invalid credentials"
+				}`),
+			))
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError(ContainSubstring("Invalid credentials")))
+			Expect(r.AWSClient).To(BeIdenticalTo(awsClient))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Describe("missing region", func() {
+		var apiServer *ghttp.Server
+
+		BeforeEach(func() {
+			apiServer = MakeTCPServer()
+			client, err := ocm.NewClient().
+				Logger(logging.NewLogger()).
+				Config(&config.Config{
+					URL:         apiServer.URL(),
+					AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				}).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			r.OCMClient = client
+			Expect(os.Setenv("AWS_CONFIG_FILE", filepath.Join(GinkgoT().TempDir(), "config"))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("AWS_CONFIG_FILE")).To(Succeed())
+			Expect(r.OCMClient.Close()).To(Succeed())
+			apiServer.Close()
+		})
+
+		It("fails with a usage error when the AWS configuration can't be read", func() {
+			configFile := filepath.Join(GinkgoT().TempDir(), "config")
+			Expect(os.WriteFile(configFile, []byte("[default\nregion = us-east-1\n"), 0600)).To(Succeed())
+			Expect(os.Setenv("AWS_CONFIG_FILE", configFile)).To(Succeed())
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError(HavePrefix("Error getting region: ")))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+			// The error of the AWS configuration is wrapped, not only formatted into the message:
+			Expect(errors.Unwrap(errors.Unwrap(err))).NotTo(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("fails with a usage error before creating the AWS client", func() {
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError(HavePrefix("Expected a valid AWS region. Set it with the '--region' flag")))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+			Expect(r.AWSClient).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("credentials file", func() {
+		var apiServer *ghttp.Server
+
+		BeforeEach(func() {
+			apiServer = MakeTCPServer()
+			client, err := ocm.NewClient().
+				Logger(logging.NewLogger()).
+				Config(&config.Config{
+					URL:         apiServer.URL(),
+					AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				}).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			r.OCMClient = client
+			Expect(os.Setenv("AWS_REGION", "us-east-1")).To(Succeed())
+			file := filepath.Join(GinkgoT().TempDir(), "credentials")
+			Expect(os.WriteFile(file, []byte("[default]\n"+
+				"aws_access_key_id = ASIAEXAMPLE\n"+
+				"aws_secret_access_key = secret\n"+
+				"aws_session_token = token\n"), 0600)).To(Succeed())
+			Expect(Cmd.Flags().Set("credentials-file", file)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(Cmd.Flags().Set("credentials-file", "")).To(Succeed())
+			Expect(os.Unsetenv("AWS_REGION")).To(Succeed())
+			Expect(r.OCMClient.Close()).To(Succeed())
+			apiServer.Close()
+		})
+
+		It("is rejected without a role, before sending any request", func() {
+			err := runE(Cmd, nil, r)
+			Expect(err).To(MatchError("The '--credentials-file' flag can only be used together with " +
+				"'--role-arn', as OCM can't use temporary credentials without their session token"))
+			Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUsage))
+			Expect(r.AWSClient).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	Describe("region prefix", func() {
+		regions := []string{"eu-central-1", "eu-west-1", "eu-west-2", "us-east-1"}
+
+		It("selects the only matching region", func() {
+			region, err := uniqueRegion(filterRegionsByPrefix(regions, "eu-c"), "eu-c")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(region).To(Equal("eu-central-1"))
+		})
+
+		It("rejects a prefix matching several regions", func() {
+			_, err := uniqueRegion(filterRegionsByPrefix(regions, "eu-west"), "eu-west")
+			Expect(err).To(MatchError(
+				"Region prefix 'eu-west' is ambiguous, it matches [eu-west-1 eu-west-2]"))
+		})
+
+		It("rejects a prefix matching no region", func() {
+			_, err := uniqueRegion(filterRegionsByPrefix(regions, "ap-"), "ap-")
+			Expect(err).To(MatchError("No region starts with 'ap-'"))
+		})
+	})
+
+	Describe("region options", func() {
+		displayNames := map[string]string{"us-east-1": "US East, N. Virginia"}
+
+		It("shows the display names after the IDs when there are any", func() {
+			Expect(regionOptionLabels([]string{"us-east-1", "eu-west-1"}, displayNames)).To(Equal(
+				[]string{"us-east-1 (US East, N. Virginia)", "eu-west-1"}))
+			Expect(regionOptionLabels([]string{"us-east-1"}, nil)).To(Equal([]string{"us-east-1"}))
+			Expect(regionOptionLabel("", displayNames)).To(BeEmpty())
+		})
+
+		It("resolves the selected option to the ID", func() {
+			Expect(regionFromOption("us-east-1 (US East, N. Virginia)")).To(Equal("us-east-1"))
+			Expect(regionFromOption("eu-west-1")).To(Equal("eu-west-1"))
+		})
+	})
+
+	Describe("count", func() {
+		It("prints only the number of instance types", func() {
+			var b bytes.Buffer
+			Expect(printCount(&b, 42)).To(Succeed())
+			Expect(b.String()).To(Equal("42\n"))
+		})
+
+		It("prints zero without failing", func() {
+			var b bytes.Buffer
+			Expect(printCount(&b, 0)).To(Succeed())
+			Expect(b.String()).To(Equal("0\n"))
+		})
+
+		It("can't be combined with streaming", func() {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.Bool("stream", false, "")
+			flags.Bool("count", false, "")
+			Expect(flags.Parse([]string{"--stream", "--count"})).To(Succeed())
+			args.all = true
+			defer func() {
+				args.all = false
+			}()
+			Expect(validateStreamFlags(flags)).To(MatchError(
+				"The '--stream' flag can't be used together with '--count'"))
+		})
+	})
+
+	Describe("last region", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv("ROSA_STATE", filepath.Join(GinkgoT().TempDir(), "state.json"))
+		})
+
+		It("returns nothing before a region was selected", func() {
+			Expect(lastRegion(r, []string{"us-east-1"})).To(BeEmpty())
+		})
+
+		It("returns the region selected last time", func() {
+			rememberRegion(r, "eu-west-1")
+			Expect(lastRegion(r, []string{"eu-west-1", "us-east-1"})).To(Equal("eu-west-1"))
+		})
+
+		It("ignores a region that isn't available anymore", func() {
+			rememberRegion(r, "eu-west-1")
+			Expect(lastRegion(r, []string{"us-east-1"})).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,28 @@
+package instancetypes
+
+import (
+	"time"
+
+	"github.com/briandowns/spinner"
+
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// spinnerEnabled tells if the spinner can be shown while the instance types are fetched. It is
+// never shown when the output is meant to be parsed, so that it can't end up mixed with it.
+func spinnerEnabled(isTerminal bool) bool {
+	return isTerminal && !output.HasFlag() && !args.quiet && !args.count
+}
+
+// startSpinner shows a spinner until the returned function is called, which also clears it. When
+// the spinner isn't enabled the returned function does nothing.
+func startSpinner(r *rosa.Runtime) func() {
+	if !spinnerEnabled(r.Reporter.IsTerminal()) {
+		return func() {}
+	}
+	spin := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
+	r.Reporter.Infof("Fetching instance types")
+	spin.Start()
+	return spin.Stop
+}
@@ -0,0 +1,37 @@
+package instancetypes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Spinner", func() {
+	AfterEach(func() {
+		args.quiet = false
+		args.count = false
+		Expect(Cmd.Flags().Set("output", "")).To(Succeed())
+	})
+
+	It("is shown in a terminal", func() {
+		Expect(spinnerEnabled(true)).To(BeTrue())
+	})
+
+	It("isn't shown when the output isn't a terminal", func() {
+		Expect(spinnerEnabled(false)).To(BeFalse())
+	})
+
+	It("isn't shown with an output format", func() {
+		Expect(Cmd.Flags().Set("output", "json")).To(Succeed())
+		Expect(spinnerEnabled(true)).To(BeFalse())
+	})
+
+	It("isn't shown with --quiet", func() {
+		args.quiet = true
+		Expect(spinnerEnabled(true)).To(BeFalse())
+	})
+
+	It("isn't shown with --count", func() {
+		args.count = true
+		Expect(spinnerEnabled(true)).To(BeFalse())
+	})
+})
@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/helper"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// pageStreamer calls the given function with each page of machine types as it is fetched.
+type pageStreamer func(fn func(page ocm.MachineTypeList) error) error
+
+// streamMachineTypes writes the machine types to w page by page, as they are fetched, instead of
+// waiting for the complete list. Rows are written as a table, or as one JSON object per line when
+// jsonl is set. It returns the number of machine types written.
+func streamMachineTypes(r *rosa.Runtime, w io.Writer, stream pageStreamer, selected []column, minMemory uint64,
+	gpu *bool, jsonl bool) (int, error) {
+	categories := make([]string, len(args.categories))
+	for i, category := range args.categories {
+		categories[i] = strings.ToLower(strings.TrimSpace(category))
+	}
+
+	count := 0
+	table := newTable(selected)
+	writer := output.NewTableWriter(w)
+	if !jsonl && !table.NoHeaders {
+		fmt.Fprint(writer, table.HeaderRow())
+	}
+	// The prices and the network capabilities are only known when a page has them, so the warnings
+	// about the ignored '--price-tier' and '--supports-ipv6' flags wait for the end of the stream:
+	hasPricedPage := false
+	hasNetworkPage := false
+	err := stream(func(page ocm.MachineTypeList) error {
+		page = page.Filter(func(machineType *ocm.MachineType) bool {
+			return machineType.Available && (len(categories) == 0 ||
+				helper.Contains(categories, string(machineType.MachineType.Category())))
+		})
+		page, err := applyFilters(r, page, nil, minMemory, gpu)
+		if err != nil {
+			return err
+		}
+		if args.priceTier != "" && hasPrices(page) {
+			hasPricedPage = true
+			page = filterByPriceTier(page, args.priceTier)
+		}
+		if args.supportsIPv6 && hasNetworkInfo(page) {
+			hasNetworkPage = true
+			page = filterByIPv6(page)
+		}
+		for _, machineType := range page {
+			if jsonl {
+				err := writeJSONLine(w, machineType.MachineType)
+				if err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprint(writer, table.Row(machineType))
+			}
+			count++
+		}
+		// Flushing realigns the columns of each page independently, which is the price of not
+		// waiting for the complete list:
+		return writer.Flush()
+	})
+	if err == nil && args.priceTier != "" && !hasPricedPage {
+		warnPriceTierIgnored(r)
+	}
+	if err == nil && args.supportsIPv6 && !hasNetworkPage {
+		warnIPv6Ignored(r)
+	}
+	return count, err
+}
+
+// writeJSONLine writes the machine type to w as a single line of JSON.
+func writeJSONLine(w io.Writer, machineType *cmv1.MachineType) error {
+	var b bytes.Buffer
+	err := cmv1.MarshalMachineType(machineType, &b)
+	if err != nil {
+		return err
+	}
+	var line bytes.Buffer
+	err = json.Compact(&line, b.Bytes())
+	if err != nil {
+		return err
+	}
+	line.WriteByte('\n')
+	_, err = w.Write(line.Bytes())
+	return err
+}
@@ -0,0 +1,77 @@
+package instancetypes
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Stream", func() {
+	var stream pageStreamer
+	var selected []column
+	var r *rosa.Runtime
+
+	BeforeEach(func() {
+		r = &rosa.Runtime{
+			Reporter: reporter.CreateReporterOrExit(),
+			Logger:   logging.NewLogger(),
+		}
+		pages := []ocm.MachineTypeList{
+			{
+				buildMachineType("m5.xlarge", "general_purpose", 4, 17179869184),
+				buildMachineType("r5.xlarge", "memory_optimized", 4, 34359738368),
+			},
+			{
+				buildMachineType("c5.xlarge", "compute_optimized", 4, 8589934592),
+			},
+		}
+		stream = func(fn func(page ocm.MachineTypeList) error) error {
+			for _, page := range pages {
+				err := fn(page)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var err error
+		selected, err = selectColumns([]string{"id", "category"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("writes the rows of every page in order", func() {
+		var out bytes.Buffer
+		count, err := streamMachineTypes(r, &out, stream, selected, 0, nil, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(3))
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			lines = append(lines, strings.Join(strings.Fields(line), " "))
+		}
+		Expect(lines).To(Equal([]string{
+			"ID CATEGORY",
+			"m5.xlarge general_purpose",
+			"r5.xlarge memory_optimized",
+			"c5.xlarge compute_optimized",
+		}))
+	})
+
+	It("writes one JSON object per line", func() {
+		var out bytes.Buffer
+		count, err := streamMachineTypes(r, &out, stream, selected, 16*1024*1024*1024, nil, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		Expect(string(lines[0])).To(HavePrefix("{"))
+		Expect(string(lines[0])).To(ContainSubstring(`"id":"m5.xlarge"`))
+		Expect(string(lines[1])).To(ContainSubstring(`"id":"r5.xlarge"`))
+	})
+})
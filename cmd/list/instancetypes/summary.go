@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// summaryConflictingFlags are the flags that don't produce a single listing that can be summarized.
+var summaryConflictingFlags = []string{"stream", "jsonl", "count", "group-by", "diff-region", "validate-quota",
+	"raw", "explain", "dry-run"}
+
+func validateSummaryFlag(flags *pflag.FlagSet) error {
+	if !args.summary {
+		return nil
+	}
+	for _, name := range summaryConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--summary' flag can't be used together with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// listingSummary collects what the '--summary' flag reports at the end of the listing. Nothing of
+// it is sent anywhere, it is only written to the terminal.
+type listingSummary struct {
+	region  string
+	fetched int
+	shown   int
+	started time.Time
+	now     func() time.Time
+}
+
+func newListingSummary() *listingSummary {
+	return &listingSummary{
+		started: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// write writes the summary as a single line with a stable format, for example:
+//
+//	SUMMARY: region=us-east-1 shown=12 filtered=30 elapsed=1.25s
+//
+// The region is 'all' when the instance types weren't fetched for a region, and the number of
+// filtered instance types includes those dropped by '--max-results', '--head' or '--tail'.
+func (s *listingSummary) write(w io.Writer) {
+	region := s.region
+	if region == "" {
+		region = "all"
+	}
+	fmt.Fprintf(w, "SUMMARY: region=%s shown=%d filtered=%d elapsed=%s\n", region, s.shown, s.fetched-s.shown,
+		s.now().Sub(s.started).Round(time.Millisecond))
+}
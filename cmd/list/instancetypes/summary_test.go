@@ -0,0 +1,49 @@
+package instancetypes
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("Summary", func() {
+	started := time.Date(2023, 5, 4, 10, 30, 0, 0, time.UTC)
+	newSummary := func(region string, fetched int, shown int) *listingSummary {
+		return &listingSummary{
+			region:  region,
+			fetched: fetched,
+			shown:   shown,
+			started: started,
+			now: func() time.Time {
+				return started.Add(1249700 * time.Microsecond)
+			},
+		}
+	}
+
+	It("writes a single line with a stable format", func() {
+		var b bytes.Buffer
+		newSummary("us-east-1", 42, 12).write(&b)
+		Expect(b.String()).To(Equal("SUMMARY: region=us-east-1 shown=12 filtered=30 elapsed=1.25s\n"))
+	})
+
+	It("uses 'all' as the region when listing all the instance types", func() {
+		var b bytes.Buffer
+		newSummary("", 3, 3).write(&b)
+		Expect(b.String()).To(Equal("SUMMARY: region=all shown=3 filtered=0 elapsed=1.25s\n"))
+	})
+
+	It("can't be used together with the flags that don't list the instance types", func() {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.Bool("count", false, "")
+		Expect(flags.Parse([]string{"--count"})).To(Succeed())
+		Expect(validateSummaryFlag(flags)).To(Succeed())
+		args.summary = true
+		defer func() {
+			args.summary = false
+		}()
+		Expect(validateSummaryFlag(flags)).To(MatchError("The '--summary' flag can't be used together with '--count'"))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"time"
+
+	"github.com/openshift/rosa/pkg/reporter"
+)
+
+// phaseTimer records how long each phase of the command takes, to find out whether OCM or AWS is
+// the bottleneck.
+type phaseTimer struct {
+	phases    []string
+	durations []time.Duration
+	now       func() time.Time
+}
+
+func newPhaseTimer() *phaseTimer {
+	return &phaseTimer{now: time.Now}
+}
+
+// start starts timing the given phase. Calling the returned function stops it; calling it again has
+// no effect, so that it can be called both before a prompt and at the end of the phase.
+func (t *phaseTimer) start(phase string) func() {
+	started := t.now()
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		t.phases = append(t.phases, phase)
+		t.durations = append(t.durations, t.now().Sub(started))
+	}
+}
+
+// report writes the duration of each phase as an informative message of the reporter, so that it
+// follows the '--log-format' flag like the rest of the messages.
+func (t *phaseTimer) report(rep *reporter.Object) {
+	for i, phase := range t.phases {
+		rep.Infof("Phase '%s' took %s", phase, t.durations[i].Round(time.Millisecond))
+	}
+}
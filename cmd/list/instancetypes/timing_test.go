@@ -0,0 +1,55 @@
+package instancetypes
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/rosa/pkg/reporter"
+)
+
+var _ = Describe("Timing", func() {
+	var now time.Time
+	var timer *phaseTimer
+
+	BeforeEach(func() {
+		now = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		timer = newPhaseTimer()
+		timer.now = func() time.Time {
+			return now
+		}
+	})
+
+	report := func() string {
+		var out bytes.Buffer
+		rep, err := reporter.New().Stream(&out).Build()
+		Expect(err).NotTo(HaveOccurred())
+		timer.report(rep)
+		return out.String()
+	}
+
+	It("reports the duration of each phase through the reporter", func() {
+		stop := timer.start("region resolution")
+		now = now.Add(1500 * time.Millisecond)
+		stop()
+		stop = timer.start("machine types")
+		now = now.Add(250 * time.Millisecond)
+		stop()
+
+		Expect(report()).To(Equal(
+			"INFO: Phase 'region resolution' took 1.5s\n" +
+				"INFO: Phase 'machine types' took 250ms\n"))
+	})
+
+	It("ignores the time after the first stop, like the one spent in a prompt", func() {
+		stop := timer.start("region resolution")
+		now = now.Add(time.Second)
+		stop()
+		now = now.Add(time.Minute)
+		stop()
+
+		Expect(report()).To(Equal("INFO: Phase 'region resolution' took 1s\n"))
+	})
+})
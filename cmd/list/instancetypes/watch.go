@@ -0,0 +1,318 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/arguments"
+	"github.com/openshift/rosa/pkg/color"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/output"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// defaultWatchInterval is the time between two polls of '--watch' when the '--interval' flag isn't
+// given.
+const defaultWatchInterval = time.Minute
+
+// minWatchInterval is the shortest interval accepted by the '--interval' flag, so that OCM isn't
+// polled more often than needed to notice a change of quota.
+const minWatchInterval = 10 * time.Second
+
+// maxWatchBackoff is the longest time between two polls after consecutive failures, unless the
+// interval itself is longer.
+const maxWatchBackoff = 10 * time.Minute
+
+// watchHighlight surrounds the rows that changed since the last poll when colors are enabled.
+const watchHighlight = "\033[1;32m%s\033[m"
+
+// watchConflictingFlags are the flags that don't produce a table that can be compared between polls,
+// or that would ask or do something in every poll.
+var watchConflictingFlags = []string{"jsonl", "stream", "interactive", "explain", "dry-run", "raw",
+	"validate-quota", "summary", "output-file"}
+
+func validateWatchFlags(flags *pflag.FlagSet) error {
+	if !args.watch {
+		if flags.Changed("interval") {
+			return fmt.Errorf("The '--interval' flag can only be used together with '--watch'")
+		}
+		return nil
+	}
+	for _, name := range watchConflictingFlags {
+		flag := flags.Lookup(name)
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("The '--watch' flag can't be used together with '--%s'", name)
+		}
+	}
+	if output.HasFlag() {
+		return fmt.Errorf("The '--watch' flag can't be used together with '--output'")
+	}
+	if args.interval < minWatchInterval {
+		return fmt.Errorf("Invalid interval %s. It must be at least %s", args.interval, minWatchInterval)
+	}
+	return nil
+}
+
+// runWatch lists the instance types again every '--interval' until the user presses Ctrl-C,
+// highlighting the rows that changed since the previous poll. Each poll is a complete run of the
+// command written to a buffer, so the first one also validates the flags. The OCM and AWS clients
+// created by the first poll are kept by the runtime, so the later ones send the same credentials.
+// The next poll starts only after the previous one finishes, including the retries of its requests,
+// so they never overlap.
+func runWatch(cmd *cobra.Command, argv []string, r *rosa.Runtime) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Listing all the regions clears the '--region' flag, so it is set again before each poll:
+	allRegions := arguments.GetRegion() == allRegionsValue
+	poll := func(w io.Writer) error {
+		if allRegions {
+			err := cmd.Flags().Set("region", allRegionsValue)
+			if err != nil {
+				return err
+			}
+		}
+		writer := r.Writer
+		r.Writer = w
+		defer func() {
+			r.Writer = writer
+		}()
+		return runE(cmd, argv, r)
+	}
+	return watch(ctx, r, r.Writer, poll, args.interval, color.UseColor())
+}
+
+// watchTable is the writer given to each poll. Besides the text, it keeps the machine types of the
+// rows of the table, so that the polls are compared by their data and not by the aligned text,
+// which changes in every row when a column gets wider.
+type watchTable struct {
+	bytes.Buffer
+	rows []watchRow
+}
+
+// watchRow is a row of the output of a poll. The rows of a table of instance types are named by
+// their region, if there is a REGION column, and their ID, and their value is the machine type. The
+// rest of the outputs, like the one of '--group-by', are compared line by line, named by their
+// first column.
+type watchRow struct {
+	name  string
+	line  int
+	value interface{}
+}
+
+// recordWatchRows keeps the machine types of the rows of the table just written to w, if w is the
+// writer of a poll. With '--region all' the tables of the regions may be written one after the
+// other, so the rows are added to the ones of the previous tables.
+func recordWatchRows(w io.Writer, selected []column, machineTypes ocm.MachineTypeList) {
+	table, ok := w.(*watchTable)
+	if !ok {
+		return
+	}
+	var regionValue func(item interface{}) string
+	for _, c := range selected {
+		if c.Name == "region" {
+			regionValue = c.Value
+		}
+	}
+	// The rows are the last lines written:
+	first := strings.Count(table.String(), "\n") - len(machineTypes)
+	for i, machineType := range machineTypes {
+		name := machineType.MachineType.ID()
+		if regionValue != nil {
+			name = regionValue(machineType) + " " + name
+		}
+		table.rows = append(table.rows, watchRow{
+			name:  name,
+			line:  first + i,
+			value: *machineType,
+		})
+	}
+}
+
+// watchPoll is what a poll wrote, split in lines, with its rows.
+type watchPoll struct {
+	lines []string
+	rows  []watchRow
+}
+
+func newWatchPoll(table *watchTable) *watchPoll {
+	poll := &watchPoll{
+		lines: watchLines(table.String()),
+		rows:  table.rows,
+	}
+	if poll.rows == nil {
+		for i, line := range poll.lines {
+			poll.rows = append(poll.rows, watchRow{
+				name:  watchRowName(line),
+				line:  i,
+				value: line,
+			})
+		}
+	}
+	return poll
+}
+
+// watch calls poll every interval until ctx is done, writing to w what each poll wrote, with the
+// rows that changed since the previous poll highlighted. The first poll must succeed. When a later
+// one fails because of OCM or AWS, the failure is reported and the time till the next poll doubles,
+// up to maxWatchBackoff, until a poll succeeds again.
+func watch(ctx context.Context, r *rosa.Runtime, w io.Writer, poll func(io.Writer) error,
+	interval time.Duration, highlight bool) error {
+	var previous *watchPoll
+	wait := interval
+	for {
+		var table watchTable
+		err := poll(&table)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil || errors.Is(err, errNoMachineTypes) {
+			current := newWatchPoll(&table)
+			if previous != nil {
+				fmt.Fprintln(w)
+			}
+			writeWatchPoll(w, previous, current, highlight)
+			if previous != nil {
+				r.Reporter.Infof("%s", describeWatchChanges(previous, current))
+			}
+			r.Reporter.Infof("Polled at %s, the next poll is in %s, press Ctrl-C to stop",
+				time.Now().Format("15:04:05"), interval)
+			previous = current
+			wait = interval
+		} else if previous == nil || rosa.ExitCode(err) != rosa.ExitUpstream {
+			return err
+		} else {
+			wait = nextWatchWait(wait, interval)
+			r.Reporter.Warnf("%s, polling again in %s", err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// nextWatchWait returns the time to wait after a failed poll, doubling the previous one, but not
+// over maxWatchBackoff unless the interval is longer than that.
+func nextWatchWait(wait time.Duration, interval time.Duration) time.Duration {
+	limit := maxWatchBackoff
+	if interval > limit {
+		limit = interval
+	}
+	wait *= 2
+	if wait > limit {
+		wait = limit
+	}
+	return wait
+}
+
+// watchLines splits the output of a poll into lines, without the final empty one.
+func watchLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// changedWatchRows returns the rows of the current poll that weren't in the previous one, or whose
+// value is different.
+func changedWatchRows(previous *watchPoll, current *watchPoll) []watchRow {
+	values := map[string]interface{}{}
+	for _, row := range previous.rows {
+		values[row.name] = row.value
+	}
+	var changed []watchRow
+	for _, row := range current.rows {
+		value, ok := values[row.name]
+		if !ok || !reflect.DeepEqual(value, row.value) {
+			changed = append(changed, row)
+		}
+	}
+	return changed
+}
+
+// writeWatchPoll writes the lines of the current poll, highlighting the rows that changed since the
+// previous poll if highlight is set. Nothing is highlighted in the first poll, when previous is nil.
+func writeWatchPoll(w io.Writer, previous *watchPoll, current *watchPoll, highlight bool) {
+	highlighted := map[int]bool{}
+	if highlight && previous != nil {
+		for _, row := range changedWatchRows(previous, current) {
+			highlighted[row.line] = true
+		}
+	}
+	for i, line := range current.lines {
+		if highlighted[i] {
+			line = fmt.Sprintf(watchHighlight, line)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// describeWatchChanges returns the message that tells which rows changed since the previous poll,
+// for example 'Since the last poll: changed g4dn.xlarge; removed c5.large'.
+func describeWatchChanges(previous *watchPoll, current *watchPoll) string {
+	var changed, removed []string
+	for _, row := range changedWatchRows(previous, current) {
+		changed = append(changed, row.name)
+	}
+	names := map[string]bool{}
+	for _, row := range current.rows {
+		names[row.name] = true
+	}
+	for _, row := range previous.rows {
+		if !names[row.name] {
+			removed = append(removed, row.name)
+		}
+	}
+	if len(changed) == 0 && len(removed) == 0 {
+		return "No changes since the last poll"
+	}
+	var changes []string
+	if len(changed) > 0 {
+		changes = append(changes, "changed "+strings.Join(changed, ", "))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, "removed "+strings.Join(removed, ", "))
+	}
+	return "Since the last poll: " + strings.Join(changes, "; ")
+}
+
+// watchRowName returns the first column of a line of the output.
+func watchRowName(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return line
+	}
+	return fields[0]
+}
@@ -0,0 +1,179 @@
+package instancetypes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Watch", func() {
+	AfterEach(func() {
+		args.watch = false
+		args.interval = defaultWatchInterval
+	})
+
+	Describe("validateWatchFlags", func() {
+		newFlags := func(argv ...string) *pflag.FlagSet {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.Bool("watch", false, "")
+			flags.Duration("interval", defaultWatchInterval, "")
+			flags.Bool("jsonl", false, "")
+			Expect(flags.Parse(argv)).To(Succeed())
+			return flags
+		}
+
+		It("requires '--watch' for '--interval'", func() {
+			Expect(validateWatchFlags(newFlags("--interval", "30s"))).To(MatchError(
+				"The '--interval' flag can only be used together with '--watch'"))
+		})
+
+		It("rejects the flags that don't print a table", func() {
+			args.watch = true
+			Expect(validateWatchFlags(newFlags("--watch", "--jsonl"))).To(MatchError(
+				"The '--watch' flag can't be used together with '--jsonl'"))
+		})
+
+		It("rejects short intervals", func() {
+			args.watch = true
+			args.interval = time.Second
+			Expect(validateWatchFlags(newFlags("--watch"))).To(MatchError(
+				"Invalid interval 1s. It must be at least 10s"))
+		})
+	})
+
+	DescribeTable("nextWatchWait",
+		func(wait time.Duration, interval time.Duration, expected time.Duration) {
+			Expect(nextWatchWait(wait, interval)).To(Equal(expected))
+		},
+		Entry("doubles the wait", time.Minute, time.Minute, 2*time.Minute),
+		Entry("stops at the maximum backoff", 8*time.Minute, time.Minute, maxWatchBackoff),
+		Entry("keeps a longer interval", time.Hour, time.Hour, time.Hour),
+	)
+
+	linesPoll := func(text string) *watchPoll {
+		table := &watchTable{}
+		table.WriteString(text)
+		return newWatchPoll(table)
+	}
+
+	It("describes the changes between two polls", func() {
+		previous := linesPoll("ID           AVAILABLE  \nc5.large     true\ng4dn.xlarge  false\nm5.xlarge    true\n")
+		current := linesPoll("ID           AVAILABLE  \ng4dn.xlarge  true\nm5.xlarge    true\n")
+		Expect(describeWatchChanges(previous, current)).To(Equal(
+			"Since the last poll: changed g4dn.xlarge; removed c5.large"))
+		Expect(describeWatchChanges(current, current)).To(Equal("No changes since the last poll"))
+	})
+
+	It("highlights the rows that changed", func() {
+		var b bytes.Buffer
+		writeWatchPoll(&b, linesPoll("ID\nm5.xlarge\n"), linesPoll("ID\nm5.xlarge\ng4dn.xlarge\n"), true)
+		Expect(b.String()).To(Equal("ID\nm5.xlarge\n\033[1;32mg4dn.xlarge\033[m\n"))
+	})
+
+	It("compares the machine types of the tables instead of their text", func() {
+		selected, err := selectColumns([]string{"memory", "id"})
+		Expect(err).NotTo(HaveOccurred())
+		tablePoll := func(machineTypes ...*ocm.MachineType) *watchPoll {
+			table := &watchTable{}
+			r := &rosa.Runtime{Reporter: reporter.CreateReporterOrExit()}
+			Expect(printTable(r, table, selected, machineTypes, nil, nil, false)).To(Succeed())
+			return newWatchPoll(table)
+		}
+		previous := tablePoll(
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("r5.2xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 8, 68719476736),
+		)
+		// The wider memory column realigns every line, but only one machine type changed:
+		current := tablePoll(
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("r5.2xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 8, 687194767360),
+		)
+		Expect(previous.lines[1]).NotTo(Equal(current.lines[1]))
+		Expect(describeWatchChanges(previous, current)).To(Equal("Since the last poll: changed r5.2xlarge"))
+
+		var b bytes.Buffer
+		writeWatchPoll(&b, previous, current, true)
+		lines := watchLines(b.String())
+		Expect(lines[1]).To(Equal(current.lines[1]))
+		Expect(lines[2]).To(Equal(fmt.Sprintf(watchHighlight, current.lines[2])))
+	})
+
+	Describe("polling", func() {
+		var r *rosa.Runtime
+		var errOut bytes.Buffer
+
+		BeforeEach(func() {
+			errOut.Reset()
+			rep, err := reporter.New().Stream(&errOut).Build()
+			Expect(err).NotTo(HaveOccurred())
+			r = &rosa.Runtime{Reporter: rep}
+		})
+
+		It("reprints the table after a failed poll until it is stopped", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			polls := []func(io.Writer) error{
+				func(w io.Writer) error {
+					fmt.Fprint(w, "ID\nm5.xlarge\n")
+					return nil
+				},
+				func(w io.Writer) error {
+					return rosa.UpstreamError(errors.New("Failed to fetch instance types: timeout"))
+				},
+				func(w io.Writer) error {
+					fmt.Fprint(w, "ID\nm5.xlarge\ng4dn.xlarge\n")
+					cancel()
+					return nil
+				},
+			}
+			poll := func(w io.Writer) error {
+				next := polls[0]
+				polls = polls[1:]
+				return next(w)
+			}
+			var out bytes.Buffer
+			Expect(watch(ctx, r, &out, poll, time.Millisecond, false)).To(Succeed())
+			Expect(polls).To(BeEmpty())
+			Expect(out.String()).To(Equal("ID\nm5.xlarge\n"))
+			Expect(errOut.String()).To(ContainSubstring(
+				"Failed to fetch instance types: timeout, polling again in 2ms"))
+		})
+
+		It("fails when the first poll fails", func() {
+			poll := func(w io.Writer) error {
+				return rosa.UpstreamError(errors.New("Failed to fetch instance types: timeout"))
+			}
+			var out bytes.Buffer
+			err := watch(context.Background(), r, &out, poll, time.Millisecond, false)
+			Expect(err).To(MatchError("Failed to fetch instance types: timeout"))
+		})
+
+		It("stops on authentication errors", func() {
+			calls := 0
+			poll := func(w io.Writer) error {
+				calls++
+				if calls == 2 {
+					return rosa.AuthError(errors.New("Not logged in"))
+				}
+				fmt.Fprint(w, "ID\nm5.xlarge\n")
+				return nil
+			}
+			var out bytes.Buffer
+			err := watch(context.Background(), r, &out, poll, time.Millisecond, false)
+			Expect(err).To(MatchError("Not logged in"))
+			Expect(calls).To(Equal(2))
+		})
+	})
+})
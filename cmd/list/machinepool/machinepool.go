@@ -44,7 +44,7 @@ func listMachinePools(r *rosa.Runtime, clusterKey string, cluster *cmv1.Cluster)
 	machinePools = append([]*cmv1.MachinePool{defaultMachinePool}, machinePools...)
 
 	if output.HasFlag() {
-		err = output.Print(machinePools)
+		err = output.Print(os.Stdout, machinePools)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -74,7 +74,7 @@ func run(_ *cobra.Command, _ []string) {
 		os.Exit(0)
 	}
 	if output.HasFlag() {
-		err = output.Print(ocmRoles)
+		err = output.Print(os.Stdout, ocmRoles)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
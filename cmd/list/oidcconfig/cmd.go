@@ -54,7 +54,7 @@ func run(_ *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(oidcConfigs)
+		err = output.Print(os.Stdout, oidcConfigs)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
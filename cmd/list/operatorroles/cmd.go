@@ -125,7 +125,7 @@ func run(cmd *cobra.Command, _ []string) {
 			}
 			resource = operatorsMap[args.prefix]
 		}
-		err = output.Print(resource)
+		err = output.Print(os.Stdout, resource)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
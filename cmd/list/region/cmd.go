@@ -41,7 +41,13 @@ var Cmd = &cobra.Command{
 	Short:   "List available regions",
 	Long:    "List regions that are available for the current AWS account.",
 	Example: `  # List all available regions
-  rosa list regions`,
+  rosa list regions
+
+  # List the regions available to an STS account using the installer role
+  rosa list regions --role-arn arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role
+
+  # List the regions with support for multiple availability zones as JSON
+  rosa list regions --multi-az -o json`,
 	Run: run,
 }
 
@@ -123,7 +129,7 @@ func run(cmd *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(availableRegions)
+		err = output.Print(os.Stdout, availableRegions)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -71,7 +71,7 @@ func run(_ *cobra.Command, _ []string) {
 		os.Exit(0)
 	}
 	if output.HasFlag() {
-		err = output.Print(userRoles)
+		err = output.Print(os.Stdout, userRoles)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -89,7 +89,7 @@ func run(cmd *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(availableVersions)
+		err = output.Print(os.Stdout, availableVersions)
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
@@ -49,6 +49,11 @@ import (
 	"github.com/openshift/rosa/cmd/whoami"
 	"github.com/openshift/rosa/pkg/arguments"
 	"github.com/openshift/rosa/pkg/color"
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+	"github.com/openshift/rosa/pkg/transport"
 )
 
 var root = &cobra.Command{
@@ -57,13 +62,48 @@ var root = &cobra.Command{
 	Long: "Command line tool for Red Hat OpenShift Service on AWS.\n" +
 		"For further documentation visit " +
 		"https://access.redhat.com/documentation/en-us/red_hat_openshift_service_on_aws\n",
+	PersistentPreRunE: preRun,
+}
+
+// preRun configures the reporter and the defaults before running any command.
+func preRun(cmd *cobra.Command, argv []string) error {
+	err := reporter.ConfigureLogFormat(cmd.CommandPath())
+	if err != nil {
+		return err
+	}
+	if transport.InsecureSkipTLSVerify() {
+		reporter.CreateReporterOrExit().Warnf("TLS certificates aren't verified because of the " +
+			"'--insecure-skip-tls-verify' flag, don't use it outside of test environments")
+	}
+	return applyDefaults(cmd, argv)
+}
+
+// flagError marks the errors parsing the flags of any command as usage errors.
+func flagError(_ *cobra.Command, err error) error {
+	return rosa.UsageError(err)
+}
+
+// applyDefaults sets the flags that weren't given in the command line from the defaults file.
+func applyDefaults(cmd *cobra.Command, _ []string) error {
+	defaults, err := config.LoadDefaults()
+	if err != nil {
+		return err
+	}
+	return arguments.ApplyDefaults(cmd, defaults)
 }
 
 func init() {
 	// Add the command line flags:
+	root.SetFlagErrorFunc(flagError)
 	fs := root.PersistentFlags()
 	color.AddFlag(root)
+	reporter.AddLogFormatFlag(root)
 	arguments.AddDebugFlag(fs)
+	arguments.AddTimeoutFlag(fs)
+	arguments.AddSkipTokenCheckFlag(fs)
+	ocm.AddMaxRetriesFlag(fs)
+	ocm.AddFailFastFlag(fs)
+	transport.AddFlags(fs)
 
 	// Register the subcommands:
 	root.AddCommand(completion.Cmd)
@@ -100,6 +140,11 @@ func main() {
 		if !strings.Contains(err.Error(), "Did you mean this?") {
 			fmt.Fprintf(os.Stderr, "Failed to execute root command: %s\n", err)
 		}
-		os.Exit(1)
+		// The commands that return errors keep exiting with 1, only invalid flags have their own
+		// exit code:
+		if rosa.ExitCode(err) == rosa.ExitUsage {
+			os.Exit(rosa.ExitUsage)
+		}
+		os.Exit(rosa.ExitUnexpected)
 	}
 }
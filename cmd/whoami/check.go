@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whoami
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+// credentialsError returns the error reported when checking the given credentials failed: an
+// authentication error if they were rejected, or an upstream one if the request failed for a
+// different reason, like a timeout or an internal error.
+func credentialsError(kind string, err error) error {
+	if rosa.ExitCode(err) == rosa.ExitAuth {
+		return rosa.AuthError(fmt.Errorf("%s credentials aren't valid: %w", kind, err))
+	}
+	return rosa.UpstreamError(fmt.Errorf("Failed to check the %s credentials: %w", kind, err))
+}
+
+// checkCredentials verifies that the AWS and OCM credentials are valid with the minimal requests
+// that need them, and writes 'OK' to w unless quiet is set. The error tells which credentials
+// failed.
+func checkCredentials(r *rosa.Runtime, w io.Writer, quiet bool) error {
+	if r.AWSClient == nil {
+		awsClient, err := aws.NewClient().
+			Logger(r.Logger).
+			Build()
+		if err != nil {
+			return credentialsError("AWS", err)
+		}
+		r.AWSClient = awsClient
+	}
+	_, err := r.AWSClient.GetCreator()
+	if err != nil {
+		return credentialsError("AWS", err)
+	}
+
+	if r.OCMClient == nil {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("Failed to load config file: %v", err)
+		}
+		loggedIn := false
+		if cfg != nil {
+			loggedIn, err = cfg.Armed()
+			if err != nil {
+				return rosa.AuthError(fmt.Errorf("OCM credentials aren't valid: %v", err))
+			}
+		}
+		if !loggedIn {
+			return rosa.AuthError(fmt.Errorf("OCM credentials aren't valid: user is not logged in to OCM"))
+		}
+		r.OCMClient, err = ocm.NewClient().
+			Config(cfg).
+			Logger(r.Logger).
+			Build()
+		if err != nil {
+			return credentialsError("OCM", err)
+		}
+	}
+	_, err = r.OCMClient.GetCurrentAccount()
+	if err != nil {
+		return credentialsError("OCM", err)
+	}
+
+	if !quiet {
+		fmt.Fprintln(w, "OK")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package whoami
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/aws/mocks"
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/rosa"
+)
+
+var _ = Describe("Check", func() {
+	var mockCtrl *gomock.Controller
+	var mockSTSAPI *mocks.MockSTSAPI
+	var apiServer *ghttp.Server
+	var r *rosa.Runtime
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockSTSAPI = mocks.NewMockSTSAPI(mockCtrl)
+		apiServer = MakeTCPServer()
+		ocmClient, err := ocm.NewClient().
+			Logger(logging.NewLogger()).
+			Config(&config.Config{
+				URL:         apiServer.URL(),
+				AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+			}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		r = &rosa.Runtime{
+			Reporter: reporter.CreateReporterOrExit(),
+			Logger:   logging.NewLogger(),
+			AWSClient: aws.New(
+				logrus.New(),
+				mocks.NewMockIAMAPI(mockCtrl),
+				mocks.NewMockEC2API(mockCtrl),
+				mocks.NewMockOrganizationsAPI(mockCtrl),
+				mocks.NewMockS3API(mockCtrl),
+				mocks.NewMockSecretsManagerAPI(mockCtrl),
+				mockSTSAPI,
+				mocks.NewMockCloudFormationAPI(mockCtrl),
+				mocks.NewMockServiceQuotasAPI(mockCtrl),
+				&session.Session{},
+				&aws.AccessKey{},
+			),
+			OCMClient: ocmClient,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(r.OCMClient.Close()).To(Succeed())
+		apiServer.Close()
+		mockCtrl.Finish()
+	})
+
+	expectCallerIdentity := func() {
+		mockSTSAPI.EXPECT().GetCallerIdentity(gomock.Any()).Return(&sts.GetCallerIdentityOutput{
+			Arn: awssdk.String("arn:aws:iam::123456789012:user/dev"),
+		}, nil)
+	}
+
+	It("prints 'OK' when both credentials are valid", func() {
+		expectCallerIdentity()
+		apiServer.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/current_account"),
+			RespondWithJSON(http.StatusOK, `{"kind": "Account", "id": "123", "username": "dev"}`),
+		))
+		var out bytes.Buffer
+		Expect(checkCredentials(r, &out, false)).To(Succeed())
+		Expect(out.String()).To(Equal("OK\n"))
+	})
+
+	It("prints nothing when quiet", func() {
+		expectCallerIdentity()
+		apiServer.AppendHandlers(RespondWithJSON(http.StatusOK, `{"kind": "Account", "id": "123"}`))
+		var out bytes.Buffer
+		Expect(checkCredentials(r, &out, true)).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("tells that the AWS credentials failed without checking OCM", func() {
+		mockSTSAPI.EXPECT().GetCallerIdentity(gomock.Any()).Return(nil,
+			awserr.New("ExpiredToken", "The security token included in the request is expired", nil))
+		var out bytes.Buffer
+		err := checkCredentials(r, &out, false)
+		Expect(err).To(MatchError(
+			"AWS credentials aren't valid: ExpiredToken: The security token included in the request is expired"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitAuth))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("tells that the OCM credentials failed", func() {
+		expectCallerIdentity()
+		apiServer.AppendHandlers(RespondWithJSON(http.StatusUnauthorized, `{
+		  "kind": "Error",
+		  "id": "401",
+		  "reason": "Invalid token"
+		}`))
+		var out bytes.Buffer
+		err := checkCredentials(r, &out, false)
+		Expect(err).To(MatchError(HavePrefix("OCM credentials aren't valid: ")))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitAuth))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("reports the AWS failures that aren't caused by the credentials as upstream errors", func() {
+		mockSTSAPI.EXPECT().GetCallerIdentity(gomock.Any()).Return(nil, fmt.Errorf("connection reset by peer"))
+		var out bytes.Buffer
+		err := checkCredentials(r, &out, false)
+		Expect(err).To(MatchError("Failed to check the AWS credentials: connection reset by peer"))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUpstream))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("reports the OCM failures that aren't caused by the credentials as upstream errors", func() {
+		expectCallerIdentity()
+		apiServer.AppendHandlers(RespondWithJSON(http.StatusBadRequest, `{
+		  "kind": "Error",
+		  "id": "400",
+		  "reason": "Invalid request"
+		}`))
+		var out bytes.Buffer
+		err := checkCredentials(r, &out, false)
+		Expect(err).To(MatchError(HavePrefix("Failed to check the OCM credentials: ")))
+		Expect(rosa.ExitCode(err)).To(Equal(rosa.ExitUpstream))
+		Expect(out.String()).To(BeEmpty())
+	})
+})
@@ -18,8 +18,10 @@ package whoami
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strings"
 
 	amsv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 	"github.com/spf13/cobra"
@@ -27,6 +29,7 @@ import (
 	"github.com/openshift/rosa/pkg/arguments"
 	"github.com/openshift/rosa/pkg/aws"
 	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/helper"
 	"github.com/openshift/rosa/pkg/object"
 	"github.com/openshift/rosa/pkg/ocm"
 	"github.com/openshift/rosa/pkg/output"
@@ -38,18 +41,111 @@ var Cmd = &cobra.Command{
 	Short: "Displays user account information",
 	Long:  "Displays information about your AWS and Red Hat accounts",
 	Example: `  # Displays user information
-  rosa whoami`,
+  rosa whoami
+
+  # Displays the ARN of the AWS user
+  rosa whoami -o json | jq -r .aws_arn
+
+  # Displays only the AWS account ID, for use in scripts
+  rosa whoami --field aws_account_id
+
+  # Verifies that the AWS and OCM credentials are valid, for example before running other commands
+  rosa whoami --check --quiet`,
 	Run: run,
 }
 
+var args struct {
+	check bool
+	quiet bool
+	field string
+}
+
+// fieldLabels are the labels of the fields displayed, the names accepted by the '--field' flag are
+// their machine readable versions.
+var fieldLabels = []string{
+	"AWS Account ID",
+	"AWS Default Region",
+	"AWS ARN",
+	"OCM API",
+	"OCM Account ID",
+	"OCM Account Name",
+	"OCM Account Username",
+	"OCM Account Email",
+	"OCM Organization ID",
+	"OCM Organization Name",
+	"OCM Organization External ID",
+}
+
 func init() {
 	flags := Cmd.PersistentFlags()
 	arguments.AddProfileFlag(flags)
 	arguments.AddRegionFlag(flags)
 	output.AddFlag(Cmd)
+	Cmd.Flags().BoolVar(
+		&args.check,
+		"check",
+		false,
+		"Only verify that the AWS and OCM credentials are valid and print 'OK', instead of displaying "+
+			"the account information. The exit code is non-zero if they aren't.",
+	)
+	Cmd.Flags().BoolVar(
+		&args.quiet,
+		"quiet",
+		false,
+		"Don't print anything with '--check', only set the exit code.",
+	)
+	Cmd.Flags().StringVar(
+		&args.field,
+		"field",
+		"",
+		fmt.Sprintf("Display only the value of this field, without the label. Allowed fields are %s.",
+			fieldNames()),
+	)
+}
+
+// fieldNames returns the names accepted by the '--field' flag.
+func fieldNames() []string {
+	names := make([]string, len(fieldLabels))
+	for i, label := range fieldLabels {
+		names[i] = machineReadableKey(label)
+	}
+	return names
+}
+
+func validateFlags() error {
+	if args.quiet && !args.check {
+		return fmt.Errorf("The '--quiet' flag can only be used together with '--check'")
+	}
+	if args.field == "" {
+		return nil
+	}
+	if args.check || output.HasFlag() {
+		return fmt.Errorf("The '--field' flag can't be used together with '--check' or '--output'")
+	}
+	if !helper.Contains(fieldNames(), args.field) {
+		return fmt.Errorf("Invalid field '%s'. Allowed fields are %s", args.field, fieldNames())
+	}
+	return nil
 }
 
 func run(_ *cobra.Command, _ []string) {
+	err := validateFlags()
+	if err != nil {
+		rosa.NewRuntime().Reporter.Errorf("%s", err)
+		os.Exit(rosa.ExitUsage)
+	}
+	if args.check {
+		r := rosa.NewRuntime()
+		defer r.Cleanup()
+		err = checkCredentials(r, r.Writer, args.quiet)
+		if err != nil {
+			r.Reporter.Errorf("%s", err)
+			r.Cleanup()
+			os.Exit(rosa.ExitCode(err))
+		}
+		return
+	}
+
 	r := rosa.NewRuntime().WithAWS()
 
 	// Get default AWS region:
@@ -123,22 +219,55 @@ func run(_ *cobra.Command, _ []string) {
 	}
 
 	if output.HasFlag() {
-		err = output.Print(outputObject)
+		err = output.Print(os.Stdout, machineReadable(outputObject))
 		if err != nil {
 			r.Reporter.Errorf("%s", err)
 			os.Exit(1)
 		}
 		return
 	}
+	if args.field != "" {
+		printField(os.Stdout, outputObject, args.field)
+		return
+	}
+	printText(os.Stdout, outputObject)
+}
+
+// printField writes the value of the field with the given machine readable name, or an empty line
+// if the account doesn't have it.
+func printField(w io.Writer, outputObject object.Object, name string) {
+	value, ok := machineReadable(outputObject)[name]
+	if !ok {
+		value = ""
+	}
+	fmt.Fprintln(w, value)
+}
+
+// printText writes the fields sorted by name and aligned, followed by an empty line.
+func printText(w io.Writer, outputObject object.Object) {
 	keys := make([]string, 0, len(outputObject))
 	for key := range outputObject {
 		keys = append(keys, key)
 	}
 	sort.Strings(keys)
 	for _, key := range keys {
-		fmt.Printf("%-30s%v\n", key+":", outputObject[key])
+		fmt.Fprintf(w, "%-30s%v\n", key+":", outputObject[key])
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
+}
+
+// machineReadable returns a copy of the fields with keys suitable for JSON and YAML, for example
+// 'aws_account_id' instead of 'AWS Account ID'.
+func machineReadable(outputObject object.Object) object.Object {
+	result := object.Object{}
+	for key, value := range outputObject {
+		result[machineReadableKey(key)] = value
+	}
+	return result
+}
+
+func machineReadableKey(label string) string {
+	return strings.ReplaceAll(strings.ToLower(label), " ", "_")
 }
 
 func getAccountDataFromToken(cfg *config.Config) (*amsv1.Account, error) {
@@ -0,0 +1,60 @@
+package whoami
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/rosa/pkg/object"
+)
+
+var _ = Describe("Whoami", func() {
+	outputObject := object.Object{
+		"AWS Account ID":    "123456789012",
+		"AWS ARN":           "arn:aws:iam::123456789012:user/dev",
+		"OCM API":           "https://api.openshift.com",
+		"OCM Account Email": "dev@example.com",
+	}
+
+	It("prints the fields sorted and aligned", func() {
+		var out bytes.Buffer
+		printText(&out, outputObject)
+		Expect(out.String()).To(Equal(
+			"AWS ARN:                      arn:aws:iam::123456789012:user/dev\n" +
+				"AWS Account ID:               123456789012\n" +
+				"OCM API:                      https://api.openshift.com\n" +
+				"OCM Account Email:            dev@example.com\n" +
+				"\n"))
+	})
+
+	It("uses machine readable keys", func() {
+		Expect(machineReadable(outputObject)).To(Equal(object.Object{
+			"aws_account_id":    "123456789012",
+			"aws_arn":           "arn:aws:iam::123456789012:user/dev",
+			"ocm_api":           "https://api.openshift.com",
+			"ocm_account_email": "dev@example.com",
+		}))
+	})
+
+	It("prints only the value of the field", func() {
+		var out bytes.Buffer
+		printField(&out, outputObject, "aws_account_id")
+		Expect(out.String()).To(Equal("123456789012\n"))
+
+		out.Reset()
+		printField(&out, outputObject, "ocm_organization_external_id")
+		Expect(out.String()).To(Equal("\n"))
+	})
+
+	It("rejects an unknown field listing the valid ones", func() {
+		args.field = "aws_account"
+		defer func() {
+			args.field = ""
+		}()
+		Expect(validateFlags()).To(MatchError("Invalid field 'aws_account'. Allowed fields are " +
+			"[aws_account_id aws_default_region aws_arn ocm_api ocm_account_id ocm_account_name " +
+			"ocm_account_username ocm_account_email ocm_organization_id ocm_organization_name " +
+			"ocm_organization_external_id]"))
+	})
+})
@@ -0,0 +1,13 @@
+package whoami_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWhoami(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Whoami Suite")
+}
@@ -20,13 +20,16 @@ package arguments
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"github.com/openshift/rosa/pkg/aws/profile"
 	"github.com/openshift/rosa/pkg/aws/region"
+	"github.com/openshift/rosa/pkg/config"
 	"github.com/openshift/rosa/pkg/debug"
 	"github.com/openshift/rosa/pkg/helper"
 )
@@ -210,6 +213,103 @@ func GetProfile() string {
 	return profile.Profile()
 }
 
+// ValidateProfile checks that the AWS profile selected with the '--profile' flag or the AWS_PROFILE
+// environment variable exists.
+func ValidateProfile() error {
+	return profile.Validate()
+}
+
+// configDefaults lists the flags whose default can be set in the defaults file, the environment
+// variables that also set them, if any, and the commands they apply to, if not all of them.
+var configDefaults = []struct {
+	flag     string
+	envs     []string
+	commands []string
+	value    func(*config.Defaults) string
+}{
+	{"region", []string{"AWS_REGION", "AWS_DEFAULT_REGION"}, nil, func(d *config.Defaults) string { return d.Region }},
+	{"profile", []string{"AWS_PROFILE"}, nil, func(d *config.Defaults) string { return d.Profile }},
+	// Other commands use '--role-arn' for roles other than the installer one:
+	{"role-arn", nil, []string{"rosa create cluster", "rosa list instance-types", "rosa list regions"},
+		func(d *config.Defaults) string { return d.RoleARN }},
+	{"output", nil, nil, func(d *config.Defaults) string { return d.Output }},
+}
+
+// ApplyDefaults sets the flags that weren't given in the command line to the values of the defaults
+// file. The precedence is: command line flag, then environment variable, then defaults file, then
+// the built-in default of the flag. The flags set from the file aren't marked as changed, so that
+// they don't conflict with other flags the way explicit ones do.
+func ApplyDefaults(cmd *cobra.Command, defaults *config.Defaults) error {
+	for _, d := range configDefaults {
+		if d.commands != nil && !helper.Contains(d.commands, cmd.CommandPath()) {
+			continue
+		}
+		flag := cmd.Flags().Lookup(d.flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if anyEnvSet(d.envs) {
+			continue
+		}
+		value := d.value(defaults)
+		if value == "" {
+			continue
+		}
+		err := flag.Value.Set(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value '%s' for '%s' in the defaults file: %v", value, d.flag, err)
+		}
+	}
+	return nil
+}
+
+// anyEnvSet returns true if any of the given environment variables isn't empty.
+func anyEnvSet(names []string) bool {
+	for _, name := range names {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTimeout is the maximum time to wait for a request to OCM or AWS when the '--timeout' flag
+// isn't set.
+const DefaultTimeout = 30 * time.Second
+
+var timeout = DefaultTimeout
+
+// AddTimeoutFlag adds the '--timeout' flag to the given set of command line flags.
+func AddTimeoutFlag(fs *pflag.FlagSet) {
+	fs.DurationVar(
+		&timeout,
+		"timeout",
+		DefaultTimeout,
+		"Maximum time to wait for each request to OCM or AWS, for example '30s' or '2m'.",
+	)
+}
+
+func GetTimeout() time.Duration {
+	return timeout
+}
+
+var skipTokenCheck bool
+
+// AddSkipTokenCheckFlag adds the '--skip-token-check' flag to the given set of command line flags.
+func AddSkipTokenCheckFlag(fs *pflag.FlagSet) {
+	fs.BoolVar(
+		&skipTokenCheck,
+		"skip-token-check",
+		false,
+		"Don't check if the OCM token has expired or is about to expire before sending requests to OCM.",
+	)
+}
+
+// SkipTokenCheck returns true if the '--skip-token-check' flag was given.
+func SkipTokenCheck() bool {
+	return skipTokenCheck
+}
+
 // AddRegionFlag adds the '--region' flag to the given set of command line flags.
 func AddRegionFlag(fs *pflag.FlagSet) {
 	region.AddFlag(fs)
@@ -0,0 +1,95 @@
+package arguments
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/rosa/pkg/config"
+)
+
+var _ = Describe("Defaults", func() {
+	var cmd *cobra.Command
+	var defaults *config.Defaults
+
+	BeforeEach(func() {
+		file := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(file, []byte(
+			"region: eu-west-1\nprofile: dev\nrole_arn: arn:aws:iam::123:role/Installer\n"), 0600)).
+			To(Succeed())
+		GinkgoT().Setenv("ROSA_CONFIG", file)
+		GinkgoT().Setenv("AWS_REGION", "")
+		GinkgoT().Setenv("AWS_DEFAULT_REGION", "")
+		GinkgoT().Setenv("AWS_PROFILE", "")
+		var err error
+		defaults, err = config.LoadDefaults()
+		Expect(err).NotTo(HaveOccurred())
+
+		root := &cobra.Command{Use: "rosa"}
+		list := &cobra.Command{Use: "list"}
+		cmd = &cobra.Command{Use: "instance-types"}
+		root.AddCommand(list)
+		list.AddCommand(cmd)
+		cmd.Flags().String("region", "us-east-1", "")
+		cmd.Flags().String("profile", "", "")
+		cmd.Flags().String("role-arn", "", "")
+	})
+
+	It("reads the defaults file", func() {
+		Expect(defaults).To(Equal(&config.Defaults{
+			Region:  "eu-west-1",
+			Profile: "dev",
+			RoleARN: "arn:aws:iam::123:role/Installer",
+		}))
+	})
+
+	It("returns empty defaults when there is no file", func() {
+		GinkgoT().Setenv("ROSA_CONFIG", filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+		defaults, err := config.LoadDefaults()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(defaults).To(Equal(&config.Defaults{}))
+	})
+
+	It("prefers the command line flag", func() {
+		Expect(cmd.Flags().Set("region", "us-west-2")).To(Succeed())
+		GinkgoT().Setenv("AWS_REGION", "ap-south-1")
+		Expect(ApplyDefaults(cmd, defaults)).To(Succeed())
+		Expect(cmd.Flags().Lookup("region").Value.String()).To(Equal("us-west-2"))
+	})
+
+	It("prefers the environment over the file", func() {
+		GinkgoT().Setenv("AWS_REGION", "ap-south-1")
+		Expect(ApplyDefaults(cmd, defaults)).To(Succeed())
+		Expect(cmd.Flags().Lookup("region").Value.String()).To(Equal("us-east-1"))
+	})
+
+	It("prefers AWS_DEFAULT_REGION over the file", func() {
+		GinkgoT().Setenv("AWS_DEFAULT_REGION", "ap-south-1")
+		Expect(ApplyDefaults(cmd, defaults)).To(Succeed())
+		Expect(cmd.Flags().Lookup("region").Value.String()).To(Equal("us-east-1"))
+	})
+
+	It("prefers the file over the built-in default", func() {
+		Expect(ApplyDefaults(cmd, defaults)).To(Succeed())
+		region := cmd.Flags().Lookup("region")
+		Expect(region.Value.String()).To(Equal("eu-west-1"))
+		Expect(region.Changed).To(BeFalse())
+		Expect(cmd.Flags().Lookup("profile").Value.String()).To(Equal("dev"))
+		Expect(cmd.Flags().Lookup("role-arn").Value.String()).To(Equal("arn:aws:iam::123:role/Installer"))
+	})
+
+	It("uses the built-in default when nothing else is set", func() {
+		Expect(ApplyDefaults(cmd, &config.Defaults{})).To(Succeed())
+		Expect(cmd.Flags().Lookup("region").Value.String()).To(Equal("us-east-1"))
+	})
+
+	It("only applies the role ARN to the commands using the installer role", func() {
+		other := &cobra.Command{Use: "ocm-role"}
+		other.Flags().String("role-arn", "", "")
+		Expect(ApplyDefaults(other, defaults)).To(Succeed())
+		Expect(other.Flags().Lookup("role-arn").Value.String()).To(BeEmpty())
+	})
+})
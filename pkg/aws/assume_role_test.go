@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Assume role", func() {
+	const roleARN = "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+
+	var stsServer *ghttp.Server
+	var sess *session.Session
+
+	BeforeEach(func() {
+		stsServer = ghttp.NewServer()
+		var err error
+		sess, err = session.NewSession(&aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(stsServer.URL()),
+			Credentials: credentials.NewStaticCredentials("AKIDPROFILE", "profile-secret", ""),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		stsServer.Close()
+	})
+
+	It("assumes the role with the credentials of the session", func() {
+		stsServer.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodPost, "/"),
+			func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Authorization")).To(ContainSubstring("Credential=AKIDPROFILE/"))
+			},
+			ghttp.VerifyForm(url.Values{
+				"Action":     []string{"AssumeRole"},
+				"RoleArn":    []string{roleARN},
+				"ExternalId": []string{"my-external-id"},
+			}),
+			ghttp.RespondWith(http.StatusOK, `<AssumeRoleResponse>
+			  <AssumeRoleResult>
+			    <Credentials>
+			      <AccessKeyId>ASIAROLE</AccessKeyId>
+			      <SecretAccessKey>role-secret</SecretAccessKey>
+			      <SessionToken>role-token</SessionToken>
+			      <Expiration>2099-01-01T00:00:00Z</Expiration>
+			    </Credentials>
+			  </AssumeRoleResult>
+			</AssumeRoleResponse>`),
+		))
+
+		value, err := NewClient().AssumeRole(roleARN, "my-external-id").assumeRoleCredentials(sess).Get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value.AccessKeyID).To(Equal("ASIAROLE"))
+		Expect(value.SecretAccessKey).To(Equal("role-secret"))
+		Expect(value.SessionToken).To(Equal("role-token"))
+	})
+
+	It("doesn't send the external ID when it isn't given", func() {
+		stsServer.AppendHandlers(ghttp.CombineHandlers(
+			func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.ParseForm()).To(Succeed())
+				Expect(req.Form).NotTo(HaveKey("ExternalId"))
+			},
+			ghttp.RespondWith(http.StatusForbidden, `<ErrorResponse>
+			  <Error>
+			    <Code>AccessDenied</Code>
+			    <Message>Not authorized to perform sts:AssumeRole</Message>
+			  </Error>
+			</ErrorResponse>`),
+		))
+
+		_, err := NewClient().AssumeRole(roleARN, "").assumeRoleCredentials(sess).Get()
+		Expect(err).To(HaveOccurred())
+		Expect(WrapAuthError(err)).To(BeAssignableToTypeOf(&AuthError{}))
+	})
+})
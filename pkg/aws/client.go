@@ -17,6 +17,7 @@ limitations under the License.
 package aws
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -30,6 +31,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -54,11 +56,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/zgalor/weberr"
 
+	"github.com/openshift/rosa/pkg/aws/credentialsfile"
 	"github.com/openshift/rosa/pkg/aws/profile"
 	regionflag "github.com/openshift/rosa/pkg/aws/region"
 	"github.com/openshift/rosa/pkg/aws/tags"
 	"github.com/openshift/rosa/pkg/info"
 	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/transport"
 )
 
 // Name of the AWS user that will be used to create all the resources of the cluster:
@@ -167,7 +171,7 @@ type Client interface {
 	DeleteInlineRolePolicies(roleName string) error
 	IsUserRole(roleName *string) (bool, error)
 	GetRoleARNPath(prefix string) (string, error)
-	DescribeAvailabilityZones() ([]string, error)
+	DescribeAvailabilityZones(ctx context.Context) ([]string, error)
 	IsLocalAvailabilityZone(availabilityZoneName string) (bool, error)
 	DetachRolePolicies(roleName string) error
 	HasManagedPolicies(roleARN string) (bool, error)
@@ -189,6 +193,9 @@ type ClientBuilder struct {
 	logger      *logrus.Logger
 	region      *string
 	credentials *AccessKey
+	transport   *http.Transport
+	roleARN     string
+	externalID  string
 }
 
 type awsClient struct {
@@ -257,6 +264,22 @@ func (b *ClientBuilder) Logger(value *logrus.Logger) *ClientBuilder {
 	return b
 }
 
+// Transport sets the transport that the AWS client will use to send the requests. The default is the
+// one shared with the rest of the OCM and AWS clients.
+func (b *ClientBuilder) Transport(value *http.Transport) *ClientBuilder {
+	b.transport = value
+	return b
+}
+
+// httpClient returns the HTTP client used by the AWS session, with the transport given to the builder
+// or the shared one.
+func (b *ClientBuilder) httpClient() *http.Client {
+	if b.transport != nil {
+		return &http.Client{Transport: b.transport}
+	}
+	return &http.Client{Transport: transport.Shared()}
+}
+
 func (b *ClientBuilder) Region(value string) *ClientBuilder {
 	b.region = aws.String(value)
 	return b
@@ -268,6 +291,26 @@ func (b *ClientBuilder) AccessKeys(value *AccessKey) *ClientBuilder {
 	return b
 }
 
+// AssumeRole makes the client use the temporary credentials of the given role. The credentials that
+// the client would use otherwise, for example the ones of the selected profile, are only used to
+// assume it. The external ID is only sent if it isn't empty.
+func (b *ClientBuilder) AssumeRole(roleARN string, externalID string) *ClientBuilder {
+	b.roleARN = roleARN
+	b.externalID = externalID
+	return b
+}
+
+// assumeRoleCredentials returns the credentials of the role of the builder, assumed with the
+// credentials of the given session.
+func (b *ClientBuilder) assumeRoleCredentials(sess *session.Session) *credentials.Credentials {
+	stsClient := sts.New(sess, &aws.Config{HTTPClient: b.httpClient()})
+	return stscreds.NewCredentialsWithClient(stsClient, b.roleARN, func(provider *stscreds.AssumeRoleProvider) {
+		if b.externalID != "" {
+			provider.ExternalID = aws.String(b.externalID)
+		}
+	})
+}
+
 // Create AWS session with a specific set of credentials
 func (b *ClientBuilder) BuildSessionWithOptionsCredentials(value *AccessKey) (*session.Session, error) {
 	return session.NewSessionWithOptions(session.Options{
@@ -277,7 +320,7 @@ func (b *ClientBuilder) BuildSessionWithOptionsCredentials(value *AccessKey) (*s
 			Credentials: credentials.NewStaticCredentials(
 				value.AccessKeyID,
 				value.SecretAccessKey,
-				"",
+				value.SessionToken,
 			),
 		},
 	})
@@ -325,6 +368,20 @@ func (b *ClientBuilder) Build() (Client, error) {
 		return nil, fmt.Errorf("Failed to connect to AWS. Use a GovCloud region in your profile")
 	}
 
+	// Use the temporary credentials from the credentials file, if given, instead of the default
+	// credential chain:
+	if b.credentials == nil && credentialsfile.Path() != "" {
+		fileCredentials, err := credentialsfile.Load(credentialsfile.Path())
+		if err != nil {
+			return nil, err
+		}
+		b.credentials = &AccessKey{
+			AccessKeyID:     fileCredentials.AccessKeyID,
+			SecretAccessKey: fileCredentials.SecretAccessKey,
+			SessionToken:    fileCredentials.SessionToken,
+		}
+	}
+
 	// Create the AWS session:
 	if b.credentials != nil {
 		sess, err = b.BuildSessionWithOptionsCredentials(b.credentials)
@@ -341,6 +398,10 @@ func (b *ClientBuilder) Build() (Client, error) {
 	if profile.Profile() != "" {
 		b.logger.Debugf("Using AWS profile: %s", profile.Profile())
 	}
+	if b.roleARN != "" {
+		b.logger.Debugf("Assuming AWS role: %s", b.roleARN)
+		sess.Config.Credentials = b.assumeRoleCredentials(sess)
+	}
 
 	// Check that the AWS credentials are available:
 	// TODO: No need to do this twice, we're essentially doing the
@@ -349,7 +410,10 @@ func (b *ClientBuilder) Build() (Client, error) {
 	_, err = sess.Config.Credentials.Get()
 	if err != nil {
 		b.logger.Debugf("Failed to find credentials: %v", err)
-		return nil, fmt.Errorf("Failed to find credentials. Check your AWS configuration and try again")
+		if b.roleARN != "" {
+			return nil, WrapAuthError(fmt.Errorf("Failed to assume role '%s': %w", b.roleARN, err))
+		}
+		return nil, credentialsError(err)
 	}
 
 	// Check that the region is set:
@@ -360,11 +424,9 @@ func (b *ClientBuilder) Build() (Client, error) {
 
 	// Update session config
 	sess = sess.Copy(&aws.Config{
-		Retryer: buildCustomRetryer(),
-		Logger:  logger,
-		HTTPClient: &http.Client{
-			Transport: http.DefaultTransport,
-		},
+		Retryer:    buildCustomRetryer(),
+		Logger:     logger,
+		HTTPClient: b.httpClient(),
 	})
 
 	if b.logger.IsLevelEnabled(logrus.DebugLevel) {
@@ -574,7 +636,7 @@ type Creator struct {
 func (c *awsClient) GetCreator() (*Creator, error) {
 	getCallerIdentityOutput, err := c.stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
-		return nil, err
+		return nil, WrapAuthError(err)
 	}
 
 	creatorARN := aws.StringValue(getCallerIdentityOutput.Arn)
@@ -675,6 +737,8 @@ func (c *awsClient) TagUserRegion(username string, region string) error {
 type AccessKey struct {
 	AccessKeyID     string
 	SecretAccessKey string
+	// SessionToken is only set for temporary credentials.
+	SessionToken string
 }
 
 // GetAWSAccessKeys uses UpsertAccessKey to delete and create new access keys
@@ -689,12 +753,12 @@ func (c *awsClient) GetAWSAccessKeys() (*AccessKey, error) {
 
 	accessKey, err := c.UpsertAccessKey(AdminUserName)
 	if err != nil {
-		return nil, err
+		return nil, WrapAuthError(err)
 	}
 
 	err = c.ValidateAccessKeys(accessKey)
 	if err != nil {
-		return nil, err
+		return nil, WrapAuthError(err)
 	}
 
 	c.awsAccessKeys = accessKey
@@ -881,17 +945,18 @@ func (c *awsClient) GetRoleByARN(roleARN string) (*iam.Role, error) {
 }
 
 // DescribeAvailabilityZones fetches the region's availability zones with type `availability-zone`
-func (c *awsClient) DescribeAvailabilityZones() ([]string, error) {
-	describeAvailabilityZonesOutput, err := c.ec2Client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
-		Filters: []*ec2.Filter{
-			{
-				Name:   aws.String("zone-type"),
-				Values: []*string{aws.String("availability-zone")},
+func (c *awsClient) DescribeAvailabilityZones(ctx context.Context) ([]string, error) {
+	describeAvailabilityZonesOutput, err := c.ec2Client.DescribeAvailabilityZonesWithContext(ctx,
+		&ec2.DescribeAvailabilityZonesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("zone-type"),
+					Values: []*string{aws.String("availability-zone")},
+				},
 			},
-		},
-	})
+		})
 	if err != nil {
-		return nil, err
+		return nil, WrapAuthError(err)
 	}
 
 	var availabilityZones []string
@@ -0,0 +1,13 @@
+package credentialsfile_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCredentialsFile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Credentials File Suite")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--credentials-file' command line option.
+
+package credentialsfile
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlag adds the credentials file flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&path,
+		"credentials-file",
+		"",
+		"Use the temporary AWS credentials in this file instead of the default credential chain. "+
+			"The file must contain 'aws_access_key_id', 'aws_secret_access_key' and 'aws_session_token'. "+
+			"Only allowed together with '--role-arn', as OCM can't use temporary credentials.",
+	)
+}
+
+// Path returns the path of the credentials file given with the flag, or an empty string if it
+// wasn't given.
+func Path() string {
+	return path
+}
+
+// path is a string flag that indicates which credentials file is being used.
+var path string
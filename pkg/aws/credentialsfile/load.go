@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialsfile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Credentials are the temporary AWS credentials read from a credentials file.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Load reads the temporary credentials from the given file. The file uses the format of the AWS
+// shared credentials file, with a single optional section:
+//
+//	[default]
+//	aws_access_key_id = ...
+//	aws_secret_access_key = ...
+//	aws_session_token = ...
+func Load(path string) (*Credentials, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read credentials file: %v", err)
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	sections := 0
+	number := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections++
+			if sections > 1 {
+				return nil, fmt.Errorf("Credentials file '%s' must contain only one section, "+
+					"found another one in line %d", path, number)
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("Credentials file '%s' is malformed, expected 'key = value' "+
+				"in line %d", path, number)
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read credentials file: %v", err)
+	}
+
+	for _, key := range []string{"aws_access_key_id", "aws_secret_access_key", "aws_session_token"} {
+		if values[key] == "" {
+			return nil, fmt.Errorf("Credentials file '%s' doesn't contain '%s'. The file must contain "+
+				"temporary credentials, including the session token", path, key)
+		}
+	}
+	return &Credentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}, nil
+}
@@ -0,0 +1,51 @@
+package credentialsfile
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	write := func(content string) string {
+		file := filepath.Join(GinkgoT().TempDir(), "credentials")
+		Expect(os.WriteFile(file, []byte(content), 0600)).To(Succeed())
+		return file
+	}
+
+	It("Reads the temporary credentials", func() {
+		file := write("# Written by the pipeline\n[default]\naws_access_key_id = AKIA\n" +
+			"aws_secret_access_key=secret\naws_session_token = token=\n")
+		credentials, err := Load(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*credentials).To(Equal(Credentials{
+			AccessKeyID:     "AKIA",
+			SecretAccessKey: "secret",
+			SessionToken:    "token=",
+		}))
+	})
+
+	It("Requires the session token", func() {
+		file := write("aws_access_key_id = AKIA\naws_secret_access_key = secret\n")
+		_, err := Load(file)
+		Expect(err).To(MatchError(ContainSubstring("doesn't contain 'aws_session_token'")))
+	})
+
+	DescribeTable("Rejects malformed files",
+		func(content string, expected string) {
+			_, err := Load(write(content))
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("line without value", "[default]\naws_access_key_id\n",
+			"is malformed, expected 'key = value' in line 2"),
+		Entry("several sections", "[default]\naws_access_key_id = a\n[dev]\n",
+			"must contain only one section, found another one in line 3"),
+	)
+
+	It("Fails if the file doesn't exist", func() {
+		_, err := Load(filepath.Join(GinkgoT().TempDir(), "missing"))
+		Expect(err).To(MatchError(ContainSubstring("Failed to read credentials file")))
+	})
+})
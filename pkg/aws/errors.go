@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+
+	"github.com/openshift/rosa/pkg/helper"
+)
+
+// AuthErrorCodes are the AWS error codes returned when the credentials are missing, expired or
+// don't grant the needed permissions.
+var AuthErrorCodes = []string{
+	"AccessDenied",
+	"AccessDeniedException",
+	"AuthFailure",
+	"ExpiredToken",
+	"ExpiredTokenException",
+	"InvalidClientTokenId",
+	"NoCredentialProviders",
+	"SignatureDoesNotMatch",
+	"UnauthorizedOperation",
+	"UnrecognizedClientException",
+}
+
+// AuthError is an error caused by AWS credentials that are missing, expired or don't grant the
+// needed permissions. It unwraps to the error returned by the SDK.
+type AuthError struct {
+	// Code is the AWS error code, for example 'ExpiredToken'.
+	Code string
+
+	err error
+}
+
+func (e *AuthError) Error() string {
+	return e.err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.err
+}
+
+// WrapAuthError returns err wrapped in an AuthError if it is an AWS error with one of the
+// AuthErrorCodes, otherwise it returns err unchanged.
+func WrapAuthError(err error) error {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && helper.Contains(AuthErrorCodes, awsErr.Code()) {
+		return &AuthError{
+			Code: awsErr.Code(),
+			err:  err,
+		}
+	}
+	return err
+}
+
+// credentialsError returns the error reported when the AWS credentials can't be found, as an
+// AuthError with the code of err, or 'NoCredentialProviders' if it doesn't have one. The message of
+// err is only written to the debug log, as it lists every provider of the credential chain.
+func credentialsError(err error) error {
+	code := "NoCredentialProviders"
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		code = awsErr.Code()
+	}
+	return &AuthError{
+		Code: code,
+		err:  fmt.Errorf("Failed to find credentials. Check your AWS configuration and try again"),
+	}
+}
+
+// RegionError is an error caused by an AWS region that isn't set or that ROSA doesn't support, so
+// that the commands can report it as a usage error.
+type RegionError struct {
+	err error
+}
+
+func (e *RegionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RegionError) Unwrap() error {
+	return e.err
+}
@@ -22,6 +22,8 @@ import (
 	awscb "github.com/openshift/rosa/pkg/aws/commandbuilder"
 
 	"github.com/openshift/rosa/pkg/arguments"
+	"github.com/openshift/rosa/pkg/aws/profile"
+	regionflag "github.com/openshift/rosa/pkg/aws/region"
 	"github.com/openshift/rosa/pkg/aws/tags"
 	"github.com/openshift/rosa/pkg/fedramp"
 	"github.com/openshift/rosa/pkg/helper"
@@ -30,6 +32,22 @@ import (
 
 var RoleNameRE = regexp.MustCompile(`^[\w+=,.@-]+$`)
 
+// RoleARNRE matches the ARN of an IAM role in any partition, including an optional path.
+var RoleARNRE = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::\d{12}:role/([\w+=,.@-]+/)*[\w+=,.@-]+$`)
+
+// RoleARNFormat is the format that role ARNs must have, shown when one is invalid.
+const RoleARNFormat = "arn:<partition>:iam::<account-id>:role/<name>"
+
+// ValidateRoleARN checks that the given value looks like the ARN of an IAM role, so that a typo is
+// reported before it is sent to AWS or OCM.
+func ValidateRoleARN(roleARN string) error {
+	if !RoleARNRE.MatchString(roleARN) {
+		return fmt.Errorf("Invalid role ARN '%s'. Expected format is '%s', for example "+
+			"'arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role'", roleARN, RoleARNFormat)
+	}
+	return nil
+}
+
 // UserTagKeyRE , UserTagValueRE - https://docs.aws.amazon.com/general/latest/gr/aws_tagging.html#tag-conventions
 var UserTagKeyRE = regexp.MustCompile(`^[\pL\pZ\pN_.:/=+\-@]{1,128}$`)
 var UserTagValueRE = regexp.MustCompile(`^[\pL\pZ\pN_.:/=+\-@]{0,256}$`)
@@ -96,20 +114,41 @@ func ARNPathValidator(input interface{}) error {
 	return fmt.Errorf("can only validate strings, got %v", input)
 }
 
+// RegionSourcesHint explains the different ways the user can provide the AWS region.
+const RegionSourcesHint = "Set it with the '--region' flag, the AWS_REGION or AWS_DEFAULT_REGION " +
+	"environment variables or the region of the AWS profile"
+
 // GetRegion will return a region selected by the user or given as a default to the AWS client.
-// If the region given is empty, it will first attempt to use the default, and, failing that, will
-// prompt for user input.
+// If the region given is empty, it will fall back to the AWS_REGION and AWS_DEFAULT_REGION
+// environment variables, in that order, and then to the region configured for the AWS profile
+// selected with the '--profile' flag or the AWS_PROFILE environment variable, or the default
+// profile if none was selected. The source of the region is written to the debug log.
 func GetRegion(region string) (string, error) {
+	resolved, source := regionflag.Resolve()
+	if region == "" {
+		region = resolved
+	} else if region != resolved {
+		source = "the command"
+	}
 	if region == "" {
 		defaultSession, err := session.NewSessionWithOptions(session.Options{
 			SharedConfigState: session.SharedConfigEnable,
+			Profile:           profile.Profile(),
 		})
 
 		if err != nil {
 			return "", fmt.Errorf("Error creating default session for AWS client: %v", err)
 		}
 
-		region = *defaultSession.Config.Region
+		region = aws.StringValue(defaultSession.Config.Region)
+		profileName := profile.Profile()
+		if profileName == "" {
+			profileName = "default"
+		}
+		source = fmt.Sprintf("the '%s' AWS profile", profileName)
+	}
+	if region != "" {
+		rprtr.CreateReporterOrExit().Debugf("Using AWS region '%s' from %s", region, source)
 	}
 	return region, nil
 }
@@ -145,20 +184,28 @@ func getClientDetails(awsClient *awsClient) (*sts.GetCallerIdentityOutput, bool,
 // Currently user can rosa init using the region from their config or using --region
 // When checking for cloud formation we need to check in the region used by the user
 func GetAWSClientForUserRegion(reporter *rprtr.Object, logger *logrus.Logger, supportedRegions []string) Client {
+	client, err := NewClientForUserRegion(logger, supportedRegions)
+	if err != nil {
+		reporter.Errorf("%s", err)
+		os.Exit(1)
+	}
+	return client
+}
+
+// NewClientForUserRegion is like GetAWSClientForUserRegion, but returns the errors instead of
+// exiting. A region that isn't set or isn't supported is returned as a RegionError, and missing or
+// rejected credentials as an AuthError.
+func NewClientForUserRegion(logger *logrus.Logger, supportedRegions []string) (Client, error) {
 	// Get AWS region from env
 	awsRegionInUserConfig, err := GetRegion(arguments.GetRegion())
 	if err != nil {
-		reporter.Errorf("Error getting region: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error getting region: %v", err)
 	}
 	if awsRegionInUserConfig == "" {
-		reporter.Errorf("AWS Region not set")
-		os.Exit(1)
+		return nil, &RegionError{err: fmt.Errorf("AWS Region not set. %s", RegionSourcesHint)}
 	}
 	if !helper.Contains(supportedRegions, awsRegionInUserConfig) {
-		reporter.Errorf("Unsupported region '%s', available regions: %s",
-			awsRegionInUserConfig, helper.SliceToSortedString(supportedRegions))
-		os.Exit(1)
+		return nil, unsupportedRegionError(awsRegionInUserConfig, supportedRegions)
 	}
 
 	// Create the AWS client:
@@ -167,19 +214,16 @@ func GetAWSClientForUserRegion(reporter *rprtr.Object, logger *logrus.Logger, su
 		Region(awsRegionInUserConfig).
 		Build()
 	if err != nil {
-		reporter.Errorf("Error creating aws client for stack validation: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error creating aws client for stack validation: %w", err)
 	}
 	regionUsedForInit, err := client.GetClusterRegionTagForUser(AdminUserName)
 	if err != nil || regionUsedForInit == "" {
-		return client
+		return client, nil
 	}
 
 	if regionUsedForInit != awsRegionInUserConfig {
 		if !helper.Contains(supportedRegions, regionUsedForInit) {
-			reporter.Errorf("Unsupported region '%s', available regions: %s",
-				regionUsedForInit, helper.SliceToSortedString(supportedRegions))
-			os.Exit(1)
+			return nil, unsupportedRegionError(regionUsedForInit, supportedRegions)
 		}
 		// Create the AWS client with the region used in the init
 		//So we can check for the stack in that region
@@ -188,12 +232,16 @@ func GetAWSClientForUserRegion(reporter *rprtr.Object, logger *logrus.Logger, su
 			Region(regionUsedForInit).
 			Build()
 		if err != nil {
-			reporter.Errorf("Error creating aws client for stack validation: %v", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("Error creating aws client for stack validation: %w", err)
 		}
-		return awsClient
+		return awsClient, nil
 	}
-	return client
+	return client, nil
+}
+
+func unsupportedRegionError(region string, supportedRegions []string) error {
+	return &RegionError{err: fmt.Errorf("Unsupported region '%s', available regions: %s",
+		region, helper.SliceToSortedString(supportedRegions))}
 }
 
 func isSTS(ARN arn.ARN) bool {
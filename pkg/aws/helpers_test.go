@@ -0,0 +1,72 @@
+package aws_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/logging"
+)
+
+var _ = Describe("ValidateRoleARN", func() {
+	DescribeTable("Accepts role ARNs",
+		func(roleARN string) {
+			Expect(aws.ValidateRoleARN(roleARN)).To(Succeed())
+		},
+		Entry("commercial", "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"),
+		Entry("GovCloud", "arn:aws-us-gov:iam::123456789012:role/ManagedOpenShift-Installer-Role"),
+		Entry("China", "arn:aws-cn:iam::123456789012:role/ManagedOpenShift-Installer-Role"),
+		Entry("with a path", "arn:aws-us-gov:iam::123456789012:role/openshift/rosa/Installer-Role"),
+	)
+
+	DescribeTable("Rejects malformed ARNs",
+		func(roleARN string) {
+			Expect(aws.ValidateRoleARN(roleARN)).To(MatchError(ContainSubstring(
+				"Expected format is 'arn:<partition>:iam::<account-id>:role/<name>'")))
+		},
+		Entry("not an ARN", "ManagedOpenShift-Installer-Role"),
+		Entry("unknown partition", "arn:gcp:iam::123456789012:role/Installer-Role"),
+		Entry("short account ID", "arn:aws:iam::12345678901:role/Installer-Role"),
+		Entry("region in an IAM ARN", "arn:aws:iam:us-east-1:123456789012:role/Installer-Role"),
+		Entry("user instead of role", "arn:aws:iam::123456789012:user/admin"),
+		Entry("missing role name", "arn:aws:iam::123456789012:role/"),
+		Entry("wrong service", "arn:aws:s3::123456789012:role/Installer-Role"),
+		Entry("spaces in the name", "arn:aws:iam::123456789012:role/Installer Role"),
+	)
+})
+
+var _ = Describe("WrapAuthError", func() {
+	It("wraps the errors caused by the credentials", func() {
+		cause := awserr.New("ExpiredToken", "The security token included in the request is expired", nil)
+		err := aws.WrapAuthError(fmt.Errorf("Failed to get caller identity: %w", cause))
+		var authErr *aws.AuthError
+		Expect(errors.As(err, &authErr)).To(BeTrue())
+		Expect(authErr.Code).To(Equal("ExpiredToken"))
+		Expect(errors.Is(err, cause)).To(BeTrue())
+		Expect(err).To(MatchError(HavePrefix("Failed to get caller identity: ExpiredToken")))
+	})
+
+	It("leaves the rest of the errors unchanged", func() {
+		cause := awserr.New("Throttling", "Rate exceeded", nil)
+		Expect(aws.WrapAuthError(cause)).To(BeIdenticalTo(cause))
+	})
+})
+
+var _ = Describe("NewClientForUserRegion", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv("AWS_REGION")).To(Succeed())
+	})
+
+	It("returns a region error for a region that isn't supported", func() {
+		Expect(os.Setenv("AWS_REGION", "ap-south-2")).To(Succeed())
+		_, err := aws.NewClientForUserRegion(logging.NewLogger(), []string{"us-east-1", "eu-west-1"})
+		var regionErr *aws.RegionError
+		Expect(errors.As(err, &regionErr)).To(BeTrue())
+		Expect(err).To(MatchError("Unsupported region 'ap-south-2', available regions: [eu-west-1, us-east-1]"))
+	})
+})
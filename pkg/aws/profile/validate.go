@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/defaults"
+)
+
+// Validate checks that the selected AWS profile, if any, is defined in the shared credentials or
+// config files. The AWS SDK silently ignores unknown profiles, which makes credential errors
+// confusing.
+func Validate() error {
+	name := Profile()
+	if name == "" {
+		return nil
+	}
+	credentialsFile := sharedFile("AWS_SHARED_CREDENTIALS_FILE", defaults.SharedCredentialsFilename())
+	configFile := sharedFile("AWS_CONFIG_FILE", defaults.SharedConfigFilename())
+	if hasSection(credentialsFile, name) || hasSection(configFile, "profile "+name) ||
+		(name == "default" && hasSection(configFile, name)) {
+		return nil
+	}
+	return fmt.Errorf("AWS profile '%s' not found in '%s' or '%s'. "+
+		"Check the value of the '--profile' flag or the AWS_PROFILE environment variable",
+		name, credentialsFile, configFile)
+}
+
+func sharedFile(env string, fallback string) string {
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// hasSection returns true if the INI file contains a section with the given name. Files that can't
+// be read are treated as empty.
+func hasSection(path string, name string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		section := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+		if section == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		credentialsFile := filepath.Join(dir, "credentials")
+		configFile := filepath.Join(dir, "config")
+		Expect(os.WriteFile(credentialsFile, []byte("[default]\naws_access_key_id = a\n\n[dev]\n"), 0600)).
+			To(Succeed())
+		Expect(os.WriteFile(configFile, []byte("[profile  sso ]\nsso_start_url = b\n"), 0600)).
+			To(Succeed())
+		GinkgoT().Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+		GinkgoT().Setenv("AWS_CONFIG_FILE", configFile)
+		GinkgoT().Setenv("AWS_PROFILE", "")
+		DeferCleanup(func() {
+			profile = ""
+		})
+	})
+
+	DescribeTable("profiles",
+		func(name string, valid bool) {
+			profile = name
+			err := Validate()
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring("AWS profile '%s' not found", name)))
+			}
+		},
+		Entry("no profile", "", true),
+		Entry("default profile", "default", true),
+		Entry("profile in the credentials file", "dev", true),
+		Entry("profile in the config file", "sso", true),
+		Entry("unknown profile", "prod", false),
+	)
+})
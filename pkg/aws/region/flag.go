@@ -19,6 +19,7 @@ limitations under the License.
 package region
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/openshift/rosa/pkg/helper"
@@ -31,20 +32,30 @@ func AddFlag(flags *pflag.FlagSet) {
 		&region,
 		"region",
 		"",
-		"Use a specific AWS region, overriding the AWS_REGION environment variable.",
+		"Use a specific AWS region, overriding the AWS_REGION and AWS_DEFAULT_REGION environment variables.",
 	)
 }
 
 // Region returns a string with the name of the AWS region being used.
 func Region() string {
+	value, _ := Resolve()
+	return value
+}
+
+// Resolve returns the AWS region and where it was taken from. The '--region' flag takes precedence
+// over the AWS_REGION environment variable, which takes precedence over AWS_DEFAULT_REGION. Both
+// are empty if none of them is set, and then the region of the AWS profile should be used.
+func Resolve() (value string, source string) {
 	if helper.HandleEscapedEmptyString(region) != "" {
-		return region
+		return region, "the '--region' flag"
 	}
-	awsRegion := os.Getenv("AWS_REGION")
-	if helper.HandleEscapedEmptyString(awsRegion) != "" {
-		return awsRegion
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		value = os.Getenv(name)
+		if helper.HandleEscapedEmptyString(value) != "" {
+			return value, fmt.Sprintf("the %s environment variable", name)
+		}
 	}
-	return ""
+	return "", ""
 }
 
 // region is a string flag that indicates which AWS region is being used.
@@ -0,0 +1,46 @@
+package region
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resolve", func() {
+	BeforeEach(func() {
+		GinkgoT().Setenv("AWS_REGION", "eu-west-1")
+		GinkgoT().Setenv("AWS_DEFAULT_REGION", "ap-south-1")
+	})
+
+	AfterEach(func() {
+		region = ""
+	})
+
+	It("Prefers the '--region' flag", func() {
+		region = "us-east-2"
+		value, source := Resolve()
+		Expect(value).To(Equal("us-east-2"))
+		Expect(source).To(Equal("the '--region' flag"))
+	})
+
+	It("Prefers AWS_REGION over AWS_DEFAULT_REGION", func() {
+		value, source := Resolve()
+		Expect(value).To(Equal("eu-west-1"))
+		Expect(source).To(Equal("the AWS_REGION environment variable"))
+	})
+
+	It("Falls back to AWS_DEFAULT_REGION", func() {
+		GinkgoT().Setenv("AWS_REGION", "")
+		value, source := Resolve()
+		Expect(value).To(Equal("ap-south-1"))
+		Expect(source).To(Equal("the AWS_DEFAULT_REGION environment variable"))
+		Expect(Region()).To(Equal("ap-south-1"))
+	})
+
+	It("Returns nothing when no region is set", func() {
+		GinkgoT().Setenv("AWS_REGION", "")
+		GinkgoT().Setenv("AWS_DEFAULT_REGION", "")
+		value, source := Resolve()
+		Expect(value).To(BeEmpty())
+		Expect(source).To(BeEmpty())
+	})
+})
@@ -0,0 +1,21 @@
+package aws
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/rosa/pkg/transport"
+)
+
+var _ = Describe("Client transport", func() {
+	It("uses the transport shared with the OCM clients", func() {
+		Expect(NewClient().httpClient().Transport).To(BeIdenticalTo(transport.Shared()))
+	})
+
+	It("uses the transport given to the builder", func() {
+		given := transport.New(1, time.Second)
+		Expect(NewClient().Transport(given).httpClient().Transport).To(BeIdenticalTo(given))
+	})
+})
@@ -37,7 +37,8 @@ func AddFlag(cmd *cobra.Command) {
 		"color",
 		"auto",
 		fmt.Sprintf("Surround certain characters with escape sequences to display them in color "+
-			"on the terminal. Allowed options are %s", options),
+			"on the terminal. With 'auto' color is used only when the output is a terminal and the "+
+			"NO_COLOR environment variable isn't set. Allowed options are %s", options),
 	)
 
 	cmd.RegisterFlagCompletionFunc("color", completion)
@@ -57,6 +58,11 @@ func UseColor() bool {
 	case "auto":
 		fallthrough
 	default:
+		// See https://no-color.org: any non empty value disables color unless it is explicitly
+		// requested with '--color always'.
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
 		if runtime.GOOS == "windows" {
 			return false
 		}
@@ -0,0 +1,29 @@
+package color
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UseColor", func() {
+	AfterEach(func() {
+		color = "auto"
+	})
+
+	It("Disables color with NO_COLOR", func() {
+		GinkgoT().Setenv("NO_COLOR", "1")
+		Expect(UseColor()).To(BeFalse())
+	})
+
+	It("Uses color with 'always' even if NO_COLOR is set", func() {
+		GinkgoT().Setenv("NO_COLOR", "1")
+		color = "always"
+		Expect(UseColor()).To(BeTrue())
+	})
+
+	It("Doesn't use color with 'never'", func() {
+		GinkgoT().Setenv("NO_COLOR", "")
+		color = "never"
+		Expect(UseColor()).To(BeFalse())
+	})
+})
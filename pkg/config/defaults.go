@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+)
+
+// Defaults contains the default values of common command line flags, read from the
+// 'rosa/config.yaml' file of the user configuration directory.
+type Defaults struct {
+	Region  string `json:"region,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	RoleARN string `json:"role_arn,omitempty"`
+	Output  string `json:"output,omitempty"`
+}
+
+// DefaultsLocation returns the location of the defaults file. It can be changed with the
+// ROSA_CONFIG environment variable.
+func DefaultsLocation() (string, error) {
+	if path := os.Getenv("ROSA_CONFIG"); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "rosa", "config.yaml"), nil
+}
+
+// LoadDefaults loads the defaults file. If the file doesn't exist it returns empty defaults.
+func LoadDefaults() (*Defaults, error) {
+	defaults := &Defaults{}
+	file, err := DefaultsLocation()
+	if err != nil {
+		return defaults, err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, fmt.Errorf("Failed to read defaults file '%s': %v", file, err)
+	}
+	err = yaml.Unmarshal(data, defaults)
+	if err != nil {
+		return defaults, fmt.Errorf("Failed to parse defaults file '%s': %v", file, err)
+	}
+	return defaults, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// State contains the values that the tool remembers between runs to make interactive use more
+// convenient, for example the region selected last time.
+type State struct {
+	LastRegion string `json:"last_region,omitempty"`
+}
+
+// StateLocation returns the location of the state file. It can be changed with the ROSA_STATE
+// environment variable.
+func StateLocation() (string, error) {
+	if path := os.Getenv("ROSA_STATE"); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "rosa", "state.json"), nil
+}
+
+// LoadState loads the state file. If the file doesn't exist it returns an empty state.
+func LoadState() (*State, error) {
+	state := &State{}
+	file, err := StateLocation()
+	if err != nil {
+		return state, err
+	}
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("Failed to read state file '%s': %v", file, err)
+	}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return state, fmt.Errorf("Failed to parse state file '%s': %v", file, err)
+	}
+	return state, nil
+}
+
+// SaveState saves the given state to the state file.
+func SaveState(state *State) error {
+	file, err := StateLocation()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(file)
+	err = os.MkdirAll(dir, os.FileMode(0755))
+	if err != nil {
+		return fmt.Errorf("Failed to create directory %s: %v", dir, err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to marshal state: %v", err)
+	}
+	err = os.WriteFile(file, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write file '%s': %v", file, err)
+	}
+	return nil
+}
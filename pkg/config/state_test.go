@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("State", func() {
+	var file string
+
+	BeforeEach(func() {
+		file = filepath.Join(GinkgoT().TempDir(), "rosa", "state.json")
+		GinkgoT().Setenv("ROSA_STATE", file)
+	})
+
+	It("Returns an empty state when the file doesn't exist", func() {
+		state, err := LoadState()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*state).To(Equal(State{}))
+	})
+
+	It("Loads the saved state", func() {
+		Expect(SaveState(&State{LastRegion: "eu-west-1"})).To(Succeed())
+		state, err := LoadState()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.LastRegion).To(Equal("eu-west-1"))
+	})
+
+	It("Fails if the file is malformed", func() {
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(os.WriteFile(file, []byte("last_region: eu-west-1"), 0600)).To(Succeed())
+		_, err := LoadState()
+		Expect(err).To(MatchError(ContainSubstring("Failed to parse state file")))
+	})
+})
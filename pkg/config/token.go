@@ -87,3 +87,41 @@ func getTokenExpiry(token *jwt.Token, now time.Time) (expires bool, left time.Du
 	}
 	return
 }
+
+// SessionExpiry determines if the session stored in the configuration expires, and the time that
+// remains till it does. The access token is renewed with the refresh token when there is one, so
+// the session lasts as long as the token that expires last. Sessions that use client credentials or
+// an encrypted refresh token are considered to never expire, as there is no way to know.
+func (c *Config) SessionExpiry(now time.Time) (expires bool, left time.Duration, err error) {
+	if c.ClientID != "" && c.ClientSecret != "" {
+		return
+	}
+	for _, textToken := range []string{c.AccessToken, c.RefreshToken} {
+		if textToken == "" {
+			continue
+		}
+		if IsEncryptedToken(textToken) {
+			return false, 0, nil
+		}
+		var token *jwt.Token
+		token, err = ParseToken(textToken)
+		if err != nil {
+			err = fmt.Errorf("Failed to parse token: %v", err)
+			return
+		}
+		var tokenExpires bool
+		var tokenLeft time.Duration
+		tokenExpires, tokenLeft, err = getTokenExpiry(token, now)
+		if err != nil {
+			return
+		}
+		if !tokenExpires {
+			return false, 0, nil
+		}
+		if !expires || tokenLeft > left {
+			expires = true
+			left = tokenLeft
+		}
+	}
+	return
+}
@@ -0,0 +1,264 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instancetypes
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/helper"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+// Criteria selects, among the instance types returned by List, the ones that Select keeps. The zero
+// value keeps all of them except the deprecated ones.
+type Criteria struct {
+	// Categories keeps only the instance types of these categories, compared ignoring case.
+	Categories []string
+
+	// MinCPU and MinMemory are the minimum number of CPU cores and bytes of memory.
+	MinCPU    int
+	MinMemory uint64
+
+	// Family keeps only the instance types whose family starts with this prefix, ignoring case.
+	Family string
+
+	// MinGeneration keeps only the instance types of this generation or newer, when it isn't zero.
+	// The instance types whose family doesn't encode a generation are dropped, unless
+	// IncludeUnparseable is set.
+	MinGeneration      int
+	IncludeUnparseable bool
+
+	// Architecture keeps only the instance types with this CPU architecture.
+	Architecture string
+
+	// GPU keeps only the instance types with accelerators if it is true, or only those without them
+	// if it is false. A nil GPU doesn't filter by accelerators.
+	GPU *bool
+
+	// Exclude removes the instance types whose ID matches any of these exact IDs or glob patterns,
+	// like 'm5.*'. The patterns must have been checked with ValidateExcludePatterns.
+	Exclude []string
+
+	// ShowDeprecated keeps the instance types of the deprecated families.
+	ShowDeprecated bool
+}
+
+// Select keeps only the instance types that match the criteria. The debugf function, if not nil,
+// is used to explain why some of them are dropped.
+func Select(machineTypes ocm.MachineTypeList, criteria Criteria,
+	debugf func(format string, args ...interface{})) (ocm.MachineTypeList, error) {
+	if debugf == nil {
+		debugf = func(string, ...interface{}) {}
+	}
+	machineTypes, err := filterByCategory(machineTypes, criteria.Categories)
+	if err != nil {
+		return nil, err
+	}
+	machineTypes = filterBySize(machineTypes, criteria.MinCPU, criteria.MinMemory)
+	machineTypes = filterByFamily(machineTypes, criteria.Family)
+	machineTypes = filterByGeneration(machineTypes, criteria.MinGeneration, criteria.IncludeUnparseable, debugf)
+	machineTypes = filterByArchitecture(machineTypes, criteria.Architecture)
+	machineTypes = filterByGPU(machineTypes, criteria.GPU)
+	machineTypes = filterByExclude(machineTypes, criteria.Exclude)
+	machineTypes = filterDeprecated(machineTypes, criteria.ShowDeprecated)
+	return machineTypes, nil
+}
+
+// filterDeprecated removes the machine types of the deprecated families, unless show is set.
+func filterDeprecated(machineTypes ocm.MachineTypeList, show bool) ocm.MachineTypeList {
+	if show {
+		return machineTypes
+	}
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return !machineType.Deprecated()
+	})
+}
+
+// ParseMemory parses a human readable memory size, like '64Gi' or '131072Mi', into bytes.
+func ParseMemory(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	bytes, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid memory size '%s'. Expected a value such as '64Gi' or '131072Mi'", value)
+	}
+	return bytes, nil
+}
+
+// memoryUnitFactors contains the number of bytes of each of the memory units that OCM can use.
+var memoryUnitFactors = map[string]int{
+	"B":   1,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// ToBytes converts a value expressed in the given memory unit to bytes. The second return value is
+// false if the unit isn't known.
+func ToBytes(value int, uValue string) (int, bool) {
+	factor, ok := memoryUnitFactors[uValue]
+	if !ok {
+		return value, false
+	}
+	return value * factor, true
+}
+
+// MemoryBytes returns the memory of the machine type in bytes.
+func MemoryBytes(machineType *cmv1.MachineType) float64 {
+	factor, ok := memoryUnitFactors[machineType.Memory().Unit()]
+	if !ok {
+		factor = 1
+	}
+	return machineType.Memory().Value() * float64(factor)
+}
+
+// filterBySize keeps only the machine types that have at least the given number of CPU cores and
+// bytes of memory.
+func filterBySize(machineTypes ocm.MachineTypeList, minCPU int, minMemory uint64) ocm.MachineTypeList {
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return machineType.MachineType.CPU().Value() >= float64(minCPU) &&
+			MemoryBytes(machineType.MachineType) >= float64(minMemory)
+	})
+}
+
+// Categories returns the sorted list of distinct categories of the given machine types.
+func Categories(machineTypes ocm.MachineTypeList) []string {
+	var categories []string
+	for _, machineType := range machineTypes {
+		category := string(machineType.MachineType.Category())
+		if !helper.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// filterByCategory keeps only the machine types that belong to one of the given categories. The
+// comparison is case-insensitive, and categories that aren't present in the list are rejected.
+func filterByCategory(machineTypes ocm.MachineTypeList, categories []string) (ocm.MachineTypeList, error) {
+	if len(categories) == 0 {
+		return machineTypes, nil
+	}
+	available := Categories(machineTypes)
+	wanted := make([]string, len(categories))
+	for i, category := range categories {
+		wanted[i] = strings.ToLower(strings.TrimSpace(category))
+		if !helper.Contains(available, wanted[i]) {
+			return nil, fmt.Errorf("Invalid category '%s'. Categories available are %s", category, available)
+		}
+	}
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return helper.Contains(wanted, string(machineType.MachineType.Category()))
+	}), nil
+}
+
+// filterByArchitecture keeps only the machine types with the given CPU architecture.
+func filterByArchitecture(machineTypes ocm.MachineTypeList, architecture string) ocm.MachineTypeList {
+	if architecture == "" {
+		return machineTypes
+	}
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return machineType.Architecture() == architecture
+	})
+}
+
+// ValidateExcludePatterns checks that the given exclude patterns are valid glob patterns.
+func ValidateExcludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("Invalid exclude pattern '%s': %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// filterByExclude removes the machine types whose ID matches any of the given exact IDs or glob
+// patterns, like 'm5.*'. The patterns must have already been validated.
+func filterByExclude(machineTypes ocm.MachineTypeList, patterns []string) ocm.MachineTypeList {
+	if len(patterns) == 0 {
+		return machineTypes
+	}
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		for _, pattern := range patterns {
+			matched, _ := path.Match(pattern, machineType.MachineType.ID())
+			if matched {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// filterByFamily keeps only the machine types whose AWS instance family starts with the given prefix,
+// ignoring case.
+func filterByFamily(machineTypes ocm.MachineTypeList, family string) ocm.MachineTypeList {
+	if family == "" {
+		return machineTypes
+	}
+	prefix := strings.ToLower(family)
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return strings.HasPrefix(strings.ToLower(machineType.Family()), prefix)
+	})
+}
+
+// filterByGeneration keeps only the machine types whose generation is at least the given one, or all
+// of them when it is zero. The machine types whose family doesn't encode a generation are dropped,
+// unless includeUnparseable is set.
+func filterByGeneration(machineTypes ocm.MachineTypeList, minGeneration int, includeUnparseable bool,
+	debugf func(format string, args ...interface{})) ocm.MachineTypeList {
+	if minGeneration == 0 {
+		return machineTypes
+	}
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		generation, ok := machineType.Generation()
+		if !ok {
+			if !includeUnparseable {
+				debugf("Excluding instance type '%s', as its family doesn't encode a generation",
+					machineType.MachineType.ID())
+			}
+			return includeUnparseable
+		}
+		return generation >= minGeneration
+	})
+}
+
+// HasGPU returns true if the machine type has GPUs or other hardware accelerators. The machine types
+// returned by OCM don't include the number of GPUs, only their category.
+func HasGPU(machineType *ocm.MachineType) bool {
+	return machineType.MachineType.Category() == ocm.AcceleratedComputing
+}
+
+// filterByGPU keeps only the machine types with accelerators if gpu is true, or only those without
+// them if it is false. A nil gpu keeps all the machine types.
+func filterByGPU(machineTypes ocm.MachineTypeList, gpu *bool) ocm.MachineTypeList {
+	if gpu == nil {
+		return machineTypes
+	}
+	return machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+		return HasGPU(machineType) == *gpu
+	})
+}
@@ -0,0 +1,288 @@
+package instancetypes
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+func buildMachineType(id string, category cmv1.MachineTypeCategory, cpu float64, memory float64) *ocm.MachineType {
+	machineType, err := cmv1.NewMachineType().
+		ID(id).
+		Category(category).
+		CPU(cmv1.NewValue().Value(cpu).Unit("vCPU")).
+		Memory(cmv1.NewValue().Value(memory).Unit("B")).
+		Build()
+	Expect(err).NotTo(HaveOccurred())
+	return &ocm.MachineType{
+		MachineType: machineType,
+		Available:   true,
+	}
+}
+
+var _ = Describe("Filters", func() {
+	DescribeTable("ParseMemory",
+		func(value string, expected uint64, expectedError string) {
+			bytes, err := ParseMemory(value)
+			if expectedError != "" {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(expectedError))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bytes).To(Equal(expected))
+		},
+		Entry("empty", "", uint64(0), ""),
+		Entry("GiB", "64Gi", uint64(68719476736), ""),
+		Entry("MiB", "131072Mi", uint64(137438953472), ""),
+		Entry("invalid", "lots",
+			uint64(0), "Invalid memory size 'lots'. Expected a value such as '64Gi' or '131072Mi'"),
+	)
+
+	It("Filters by minimum CPU and memory", func() {
+		machineTypes := ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+			buildMachineType("c5.4xlarge", cmv1.MachineTypeCategoryComputeOptimized, 16, 34359738368),
+			buildMachineType("r5.2xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 8, 68719476736),
+		}
+		filtered := filterBySize(machineTypes, 16, 68719476736)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.4xlarge"}))
+		Expect(filterBySize(machineTypes, 0, 0)).To(HaveLen(4))
+	})
+})
+
+var _ = Describe("Category filter", func() {
+	var machineTypes ocm.MachineTypeList
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+			buildMachineType("c5.xlarge", cmv1.MachineTypeCategoryComputeOptimized, 4, 8589934592),
+		}
+	})
+
+	It("Lists the distinct categories", func() {
+		Expect(Categories(machineTypes)).To(Equal(
+			[]string{"compute_optimized", "general_purpose", "memory_optimized"}))
+	})
+
+	It("Keeps the machine types of the given categories ignoring case", func() {
+		filtered, err := filterByCategory(machineTypes, []string{"Memory_Optimized", "compute_optimized"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered.IDs()).To(Equal([]string{"r5.xlarge", "c5.xlarge"}))
+	})
+
+	It("Keeps everything when no category is given", func() {
+		filtered, err := filterByCategory(machineTypes, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filtered).To(HaveLen(3))
+	})
+
+	It("Fails listing the categories present for an unknown category", func() {
+		_, err := filterByCategory(machineTypes, []string{"accelerated_computing"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Invalid category 'accelerated_computing'. Categories available are " +
+			"[compute_optimized general_purpose memory_optimized]"))
+	})
+})
+
+var _ = Describe("Architecture filter", func() {
+	It("Keeps only the machine types with the given architecture", func() {
+		machineTypes := ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m6g.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		}
+		arm := filterByArchitecture(machineTypes, ocm.ArchitectureArm64)
+		Expect(arm.IDs()).To(Equal([]string{"m6g.xlarge"}))
+		x86 := filterByArchitecture(machineTypes, ocm.ArchitectureX86)
+		Expect(x86.IDs()).To(Equal([]string{"m5.xlarge"}))
+		Expect(filterByArchitecture(machineTypes, "")).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("Exclude filter", func() {
+	machineTypes := ocm.MachineTypeList{
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("m5.2xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 8, 34359738368),
+		buildMachineType("c5.xlarge", cmv1.MachineTypeCategoryComputeOptimized, 4, 8589934592),
+		buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 4, 34359738368),
+	}
+
+	DescribeTable("filterByExclude",
+		func(patterns []string, expected []string) {
+			Expect(ValidateExcludePatterns(patterns)).To(Succeed())
+			filtered := filterByExclude(machineTypes, patterns)
+			Expect(filtered.IDs()).To(Equal(expected))
+		},
+		Entry("nothing excluded", nil, []string{"m5.xlarge", "m5.2xlarge", "c5.xlarge", "r5.xlarge"}),
+		Entry("exact ID", []string{"c5.xlarge"}, []string{"m5.xlarge", "m5.2xlarge", "r5.xlarge"}),
+		Entry("glob pattern", []string{"m5.*"}, []string{"c5.xlarge", "r5.xlarge"}),
+		Entry("several patterns", []string{"m5.*", "c?.xlarge"}, []string{"r5.xlarge"}),
+		Entry("pattern matching nothing", []string{"p3.*"},
+			[]string{"m5.xlarge", "m5.2xlarge", "c5.xlarge", "r5.xlarge"}),
+	)
+
+	It("Rejects an invalid glob pattern", func() {
+		err := ValidateExcludePatterns([]string{"m5.*", "m5.[xlarge"})
+		Expect(err).To(MatchError("Invalid exclude pattern 'm5.[xlarge': syntax error in pattern"))
+	})
+})
+
+var _ = Describe("Deprecated filter", func() {
+	machineTypes := ocm.MachineTypeList{
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("m4.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("r3.2xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 8, 65498251264),
+		buildMachineType("c5.xlarge", cmv1.MachineTypeCategoryComputeOptimized, 4, 8589934592),
+	}
+
+	It("Hides the deprecated families by default", func() {
+		filtered := filterDeprecated(machineTypes, false)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge", "c5.xlarge"}))
+	})
+
+	It("Includes them when asked to", func() {
+		filtered := filterDeprecated(machineTypes, true)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.xlarge", "m4.xlarge", "r3.2xlarge", "c5.xlarge"}))
+	})
+})
+
+var _ = Describe("Family filter", func() {
+	withGenericName := func(machineType *ocm.MachineType, genericName string) *ocm.MachineType {
+		built, err := cmv1.NewMachineType().
+			ID(machineType.MachineType.ID()).
+			GenericName(genericName).
+			CPU(cmv1.NewValue().Value(machineType.MachineType.CPU().Value()).Unit("vCPU")).
+			Memory(cmv1.NewValue().Value(machineType.MachineType.Memory().Value()).Unit("B")).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		machineType.MachineType = built
+		return machineType
+	}
+
+	machineTypes := ocm.MachineTypeList{
+		withGenericName(buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose,
+			4, 17179869184), "standard-4"),
+		withGenericName(buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose,
+			16, 68719476736), "standard-16"),
+		withGenericName(buildMachineType("r5.xlarge", cmv1.MachineTypeCategoryMemoryOptimized,
+			4, 34359738368), "highmem-4"),
+		buildMachineType("m6g.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+	}
+
+	It("Derives the family from the ID, not from the generic name", func() {
+		Expect(machineTypes[0].Family()).To(Equal("m5"))
+		Expect(machineTypes[2].Family()).To(Equal("r5"))
+		Expect(machineTypes[3].Family()).To(Equal("m6g"))
+	})
+
+	DescribeTable("filterByFamily",
+		func(family string, expected []string) {
+			filtered := filterByFamily(machineTypes, family)
+			Expect(filtered.IDs()).To(Equal(expected))
+		},
+		Entry("no family", "", []string{"m5.xlarge", "m5.4xlarge", "r5.xlarge", "m6g.xlarge"}),
+		Entry("exact family", "r5", []string{"r5.xlarge"}),
+		Entry("prefix ignoring case", "M5", []string{"m5.xlarge", "m5.4xlarge"}),
+		Entry("prefix of several families", "m", []string{"m5.xlarge", "m5.4xlarge", "m6g.xlarge"}),
+		Entry("generic name", "standard", []string{}),
+	)
+
+	It("Composes with the size filter", func() {
+		filtered := filterBySize(filterByFamily(machineTypes, "m5"), 16, 0)
+		Expect(filtered.IDs()).To(Equal([]string{"m5.4xlarge"}))
+	})
+})
+
+var _ = Describe("Generation filter", func() {
+	var machineTypes ocm.MachineTypeList
+	var debugged []string
+
+	BeforeEach(func() {
+		machineTypes = ocm.MachineTypeList{
+			buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("m6i.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+			buildMachineType("c7gn.large", cmv1.MachineTypeCategoryComputeOptimized, 2, 4294967296),
+			buildMachineType("u-6tb1.metal", cmv1.MachineTypeCategoryMemoryOptimized, 448, 6597069766656),
+		}
+		debugged = nil
+	})
+
+	debugf := func(format string, args ...interface{}) {
+		debugged = append(debugged, fmt.Sprintf(format, args...))
+	}
+
+	DescribeTable("filterByGeneration",
+		func(minGeneration int, includeUnparseable bool, expected []string) {
+			filtered := filterByGeneration(machineTypes, minGeneration, includeUnparseable, debugf)
+			Expect(filtered.IDs()).To(Equal(expected))
+		},
+		Entry("no minimum", 0, false, []string{"m5.xlarge", "m6i.xlarge", "c7gn.large", "u-6tb1.metal"}),
+		Entry("including the minimum", 6, false, []string{"m6i.xlarge", "c7gn.large"}),
+		Entry("keeping the unparseable ones", 6, true, []string{"m6i.xlarge", "c7gn.large", "u-6tb1.metal"}),
+		Entry("above every generation", 8, false, []string{}),
+	)
+	It("Explains why the unparseable ones are dropped", func() {
+		filterByGeneration(machineTypes, 6, false, debugf)
+		Expect(debugged).To(Equal([]string{
+			"Excluding instance type 'u-6tb1.metal', as its family doesn't encode a generation",
+		}))
+	})
+})
+
+var _ = Describe("GPU filter", func() {
+	machineTypes := ocm.MachineTypeList{
+		buildMachineType("g4dn.xlarge", cmv1.MachineTypeCategoryAcceleratedComputing, 4, 17179869184),
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+	}
+	withGPU := true
+	withoutGPU := false
+
+	DescribeTable("filterByGPU",
+		func(gpu *bool, expected []string) {
+			filtered := filterByGPU(machineTypes, gpu)
+			Expect(filtered.IDs()).To(Equal(expected))
+		},
+		Entry("not given", nil, []string{"g4dn.xlarge", "m5.xlarge"}),
+		Entry("only GPUs", &withGPU, []string{"g4dn.xlarge"}),
+		Entry("without GPUs", &withoutGPU, []string{"m5.xlarge"}),
+	)
+})
+
+var _ = Describe("Select", func() {
+	machineTypes := ocm.MachineTypeList{
+		buildMachineType("m5.xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 4, 17179869184),
+		buildMachineType("m5.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+		buildMachineType("m4.4xlarge", cmv1.MachineTypeCategoryGeneralPurpose, 16, 68719476736),
+		buildMachineType("r5.4xlarge", cmv1.MachineTypeCategoryMemoryOptimized, 16, 137438953472),
+		buildMachineType("g4dn.4xlarge", cmv1.MachineTypeCategoryAcceleratedComputing, 16, 68719476736),
+	}
+
+	It("Keeps everything but the deprecated families with the zero criteria", func() {
+		selected, err := Select(machineTypes, Criteria{}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected.IDs()).To(Equal([]string{"m5.xlarge", "m5.4xlarge", "r5.4xlarge", "g4dn.4xlarge"}))
+	})
+
+	It("Applies all the criteria together", func() {
+		withoutGPU := false
+		selected, err := Select(machineTypes, Criteria{
+			MinCPU:  16,
+			GPU:     &withoutGPU,
+			Exclude: []string{"r5.*"},
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected.IDs()).To(Equal([]string{"m5.4xlarge"}))
+	})
+
+	It("Fails for an unknown category", func() {
+		_, err := Select(machineTypes, Criteria{Categories: []string{"storage_optimized"}}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
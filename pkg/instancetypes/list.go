@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package instancetypes contains the logic used to list the instance types available for use with
+// ROSA, so that it can be used without the 'rosa list instance-types' command.
+package instancetypes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+// Filters selects the instance types to list.
+type Filters struct {
+	// All lists all the instance types supported by ROSA, without checking their availability in
+	// a region. The rest of the filters must not be set.
+	All bool
+
+	// Region is the AWS region where the instance types must be available. It is mandatory unless
+	// All is set.
+	Region string
+
+	// AvailabilityZones restricts the instance types to those offered in any of these zones of
+	// the region, recording for each one the zones it is offered in.
+	AvailabilityZones []string
+
+	// HasQuota keeps only the instance types with enough quota to create a cluster.
+	HasQuota bool
+
+	// RoleARN is the role that OCM assumes to check the availability of the instance types, and
+	// ExternalID the identifier that the role may require. When RoleARN is empty the access keys
+	// of the AWS client are used instead.
+	RoleARN    string
+	ExternalID string
+}
+
+// List returns the instance types selected by the filters, sorted by ID so that the result doesn't
+// depend on the order of the responses of OCM. The same instance type is never returned twice.
+func List(ctx context.Context, ocmClient *ocm.Client, awsClient aws.Client,
+	filters Filters) (ocm.MachineTypeList, error) {
+	if filters.All {
+		if filters.Region != "" || len(filters.AvailabilityZones) > 0 || filters.HasQuota ||
+			filters.RoleARN != "" || filters.ExternalID != "" {
+			return nil, fmt.Errorf("No other filter can be used when listing all the instance types")
+		}
+		machineTypes, err := ocmClient.GetAvailableMachineTypes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		machineTypes = machineTypes.Deduplicate()
+		machineTypes.SortByID()
+		return machineTypes, nil
+	}
+
+	if filters.Region == "" {
+		return nil, fmt.Errorf("The region is mandatory unless listing all the instance types")
+	}
+	if filters.ExternalID != "" && filters.RoleARN == "" {
+		return nil, fmt.Errorf("The external ID can only be used together with a role ARN")
+	}
+
+	var machineTypes ocm.MachineTypeList
+	var err error
+	if len(filters.AvailabilityZones) > 0 {
+		machineTypes, err = ocmClient.GetAvailableMachineTypesInZones(ctx, filters.Region,
+			filters.AvailabilityZones, filters.RoleARN, filters.ExternalID, awsClient)
+		if err == nil {
+			machineTypes.SortByID()
+		}
+	} else {
+		machineTypes, err = ocmClient.GetSortedMachineTypesInRegion(ctx, filters.Region, nil,
+			filters.RoleARN, filters.ExternalID, awsClient)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if filters.HasQuota {
+		machineTypes = machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+			return machineType.HasQuota(false)
+		})
+	}
+
+	// OCM may return the same machine type more than once, for example once per availability zone:
+	return machineTypes.Deduplicate(), nil
+}
+
+// ListPage is like List, but returns only the given page of the instance types, along with the total
+// number of them. Pages are numbered from 1. The page is selected by OCM before the quota filter is
+// applied, so a page may contain fewer instance types than its size.
+func ListPage(ctx context.Context, ocmClient *ocm.Client, awsClient aws.Client, filters Filters,
+	page int, size int) (ocm.MachineTypeList, int, error) {
+	if page < 1 || size < 1 {
+		return nil, 0, fmt.Errorf("The page and its size must be greater than zero")
+	}
+	if len(filters.AvailabilityZones) > 0 {
+		return nil, 0, fmt.Errorf("The instance types of availability zones can't be listed by page")
+	}
+	if filters.All {
+		if filters.Region != "" || filters.HasQuota || filters.RoleARN != "" || filters.ExternalID != "" {
+			return nil, 0, fmt.Errorf("No other filter can be used when listing all the instance types")
+		}
+		machineTypes, total, err := ocmClient.GetAvailableMachineTypesPage(ctx, page, size)
+		if err != nil {
+			return nil, 0, err
+		}
+		return machineTypes.Deduplicate(), total, nil
+	}
+
+	if filters.Region == "" {
+		return nil, 0, fmt.Errorf("The region is mandatory unless listing all the instance types")
+	}
+	if filters.ExternalID != "" && filters.RoleARN == "" {
+		return nil, 0, fmt.Errorf("The external ID can only be used together with a role ARN")
+	}
+	machineTypes, total, err := ocmClient.GetAvailableMachineTypesPageInRegion(ctx, filters.Region,
+		filters.RoleARN, filters.ExternalID, awsClient, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filters.HasQuota {
+		machineTypes = machineTypes.Filter(func(machineType *ocm.MachineType) bool {
+			return machineType.HasQuota(false)
+		})
+	}
+	return machineTypes.Deduplicate(), total, nil
+}
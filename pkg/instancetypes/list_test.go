@@ -0,0 +1,152 @@
+package instancetypes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+const roleARN = "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+
+var _ = Describe("List", func() {
+	var apiServer *ghttp.Server
+	var ocmClient *ocm.Client
+
+	BeforeEach(func() {
+		apiServer = MakeTCPServer()
+		var err error
+		ocmClient, err = ocm.NewClient().
+			Logger(logging.NewLogger()).
+			Config(&config.Config{
+				AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+				URL:         apiServer.URL(),
+			}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		Expect(ocmClient.Close()).To(Succeed())
+	})
+
+	respondWithMachineTypes := func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "MachineTypeList",
+			  "page": 1,
+			  "size": 3,
+			  "total": 3,
+			  "items": [
+			    {"kind": "MachineType", "id": "p3.2xlarge", "category": "accelerated_computing"},
+			    {"kind": "MachineType", "id": "m5.xlarge", "category": "general_purpose"},
+			    {"kind": "MachineType", "id": "m5.xlarge", "category": "general_purpose"}
+			  ]
+			}`),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "Account",
+			  "organization": {"kind": "Organization", "id": "123"}
+			}`),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "QuotaCostList",
+			  "page": 1,
+			  "size": 0,
+			  "total": 0,
+			  "items": []
+			}`),
+		)
+	}
+
+	It("lists the instance types of the region once each, sorted by ID", func() {
+		respondWithMachineTypes()
+		machineTypes, err := List(context.Background(), ocmClient, nil, Filters{
+			Region:  "us-east-1",
+			RoleARN: roleARN,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "p3.2xlarge"}))
+	})
+
+	It("keeps only the instance types with enough quota", func() {
+		respondWithMachineTypes()
+		machineTypes, err := List(context.Background(), ocmClient, nil, Filters{
+			Region:   "us-east-1",
+			RoleARN:  roleARN,
+			HasQuota: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge"}))
+	})
+
+	DescribeTable("rejects invalid filters",
+		func(filters Filters, expected string) {
+			_, err := List(context.Background(), ocmClient, nil, filters)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("no region", Filters{RoleARN: roleARN},
+			"The region is mandatory unless listing all the instance types"),
+		Entry("all with other filters", Filters{All: true, HasQuota: true},
+			"No other filter can be used when listing all the instance types"),
+		Entry("external ID without role", Filters{Region: "us-east-1", ExternalID: "8f4a2c1e"},
+			"The external ID can only be used together with a role ARN"),
+	)
+
+	It("lists a page of the instance types of the region with the total", func() {
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/aws_inquiries/machine_types",
+					"order=category+asc&page=2&size=2"),
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "MachineTypeList",
+				  "page": 2,
+				  "size": 2,
+				  "total": 5,
+				  "items": [
+				    {"kind": "MachineType", "id": "m5.xlarge", "category": "general_purpose"},
+				    {"kind": "MachineType", "id": "r5.xlarge", "category": "memory_optimized"}
+				  ]
+				}`),
+			),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "Account",
+			  "organization": {"kind": "Organization", "id": "123"}
+			}`),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "QuotaCostList",
+			  "page": 1,
+			  "size": 0,
+			  "total": 0,
+			  "items": []
+			}`),
+		)
+		machineTypes, total, err := ListPage(context.Background(), ocmClient, nil, Filters{
+			Region:  "us-east-1",
+			RoleARN: roleARN,
+		}, 2, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "r5.xlarge"}))
+		Expect(total).To(Equal(5))
+	})
+
+	DescribeTable("rejects invalid pages",
+		func(filters Filters, page int, size int, expected string) {
+			_, _, err := ListPage(context.Background(), ocmClient, nil, filters, page, size)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("page zero", Filters{Region: "us-east-1"}, 0, 10,
+			"The page and its size must be greater than zero"),
+		Entry("size zero", Filters{Region: "us-east-1"}, 1, 0,
+			"The page and its size must be greater than zero"),
+		Entry("availability zones", Filters{Region: "us-east-1", AvailabilityZones: []string{"us-east-1a"}}, 1, 10,
+			"The instance types of availability zones can't be listed by page"),
+	)
+})
@@ -18,6 +18,7 @@ package ocm
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -30,17 +31,25 @@ import (
 	"github.com/openshift/rosa/pkg/info"
 	"github.com/openshift/rosa/pkg/logging"
 	"github.com/openshift/rosa/pkg/reporter"
+	"github.com/openshift/rosa/pkg/transport"
 )
 
 type Client struct {
-	ocm *sdk.Connection
+	ocm         *sdk.Connection
+	regionCache *regionCache
+	debug       func(format string, args ...interface{})
+	recorder    *cassetteRecorder
 }
 
 // ClientBuilder contains the information and logic needed to build a connection to OCM. Don't
 // create instances of this type directly; use the NewClient function instead.
 type ClientBuilder struct {
-	logger *logrus.Logger
-	cfg    *config.Config
+	logger    *logrus.Logger
+	reporter  *reporter.Object
+	cfg       *config.Config
+	offline   string
+	cassette  string
+	transport *http.Transport
 }
 
 // NewClient creates a builder that can then be used to configure and build an OCM connection.
@@ -51,6 +60,7 @@ func NewClient() *ClientBuilder {
 func CreateNewClientOrExit(logger *logrus.Logger, reporter *reporter.Object) *Client {
 	client, err := NewClient().
 		Logger(logger).
+		Reporter(reporter).
 		Build()
 	if err != nil {
 		reporter.Errorf("Failed to create OCM connection: %v", err)
@@ -60,6 +70,14 @@ func CreateNewClientOrExit(logger *logrus.Logger, reporter *reporter.Object) *Cl
 	return client
 }
 
+// Transport sets the transport that the connection will use to send the requests. The default is
+// the one shared with the rest of the OCM and AWS clients, except when the connection is insecure
+// as the TLS configuration of the shared transport can't be changed.
+func (b *ClientBuilder) Transport(value *http.Transport) *ClientBuilder {
+	b.transport = value
+	return b
+}
+
 // Logger sets the logger that the connection will use to send messages to the log. This is
 // mandatory.
 func (b *ClientBuilder) Logger(value *logrus.Logger) *ClientBuilder {
@@ -67,14 +85,55 @@ func (b *ClientBuilder) Logger(value *logrus.Logger) *ClientBuilder {
 	return b
 }
 
+// Reporter sets the reporter used to tell the user about retried requests. When it isn't set the
+// logger is used instead.
+func (b *ClientBuilder) Reporter(value *reporter.Object) *ClientBuilder {
+	b.reporter = value
+	return b
+}
+
 // Config sets the configuration that the connection will use to authenticate the user
 func (b *ClientBuilder) Config(value *config.Config) *ClientBuilder {
 	b.cfg = value
 	return b
 }
 
+// Offline sets the cassette directory whose recorded responses are returned instead of sending the
+// requests to OCM. The user doesn't need to be logged in when it is set.
+func (b *ClientBuilder) Offline(dir string) *ClientBuilder {
+	b.offline = dir
+	return b
+}
+
+// SaveCassette sets the file where the requests to OCM and their responses are saved when the client
+// is closed, in the format that Offline replays. It is meant for developers writing tests, and it
+// can't be used together with Offline.
+func (b *ClientBuilder) SaveCassette(path string) *ClientBuilder {
+	b.cassette = path
+	return b
+}
+
 // Build uses the information stored in the builder to create a new OCM connection.
 func (b *ClientBuilder) Build() (result *Client, err error) {
+	if b.offline != "" && b.cassette != "" {
+		return nil, fmt.Errorf("Responses can't be recorded while replaying them")
+	}
+	var replay *replayTransport
+	if b.offline != "" {
+		replay, err = loadCassettes(b.offline)
+		if err != nil {
+			return nil, err
+		}
+		token, err := offlineToken()
+		if err != nil {
+			return nil, err
+		}
+		if b.cfg == nil {
+			b.cfg = &config.Config{}
+		}
+		b.cfg.AccessToken = token
+		b.cfg.RefreshToken = ""
+	}
 	if b.cfg == nil {
 		// Load the configuration file:
 		b.cfg, err = config.Load()
@@ -136,6 +195,40 @@ func (b *ClientBuilder) Build() (result *Client, err error) {
 	}
 	builder.Insecure(b.cfg.Insecure)
 
+	// Requests are retried by our own transport, which unlike the one of the SDK honors the
+	// 'Retry-After' header, so disable the retries of the SDK to avoid retrying twice:
+	debugf := b.logger.Debugf
+	if b.reporter != nil {
+		debugf = b.reporter.Debugf
+	}
+	builder.RetryLimit(0)
+	var recorder *cassetteRecorder
+	if b.cassette != "" {
+		recorder = newCassetteRecorder(b.cassette)
+	}
+	if replay != nil {
+		builder.TransportWrapper(func(http.RoundTripper) http.RoundTripper {
+			return replay
+		})
+	} else {
+		shared := b.transport
+		if shared == nil && !b.cfg.Insecure {
+			shared = transport.Shared()
+		}
+		retryWrapper := newRetryWrapper(retryLimit(), debugf)
+		builder.TransportWrapper(func(next http.RoundTripper) http.RoundTripper {
+			if shared != nil {
+				next = shared
+			}
+			next = retryWrapper(next)
+			// Only the final response of each request is recorded, not the retried ones:
+			if recorder != nil {
+				next = recorder.wrap(next)
+			}
+			return next
+		})
+	}
+
 	// Create the connection:
 	conn, err := builder.Build()
 	if err != nil {
@@ -150,11 +243,29 @@ func (b *ClientBuilder) Build() (result *Client, err error) {
 		return nil, fmt.Errorf("error creating connection. Not able to get authentication token: %s", err)
 	}
 	return &Client{
-		ocm: conn,
+		ocm:      conn,
+		debug:    debugf,
+		recorder: recorder,
 	}, nil
 }
 
+// debugf sends a debug message to the reporter or the logger of the client, if it has any.
+func (c *Client) debugf(format string, args ...interface{}) {
+	if c.debug != nil {
+		c.debug(format, args...)
+	}
+}
+
+// Close closes the connection, saving first the cassette with the recorded requests if the client
+// was built with SaveCassette.
 func (c *Client) Close() error {
+	if c.recorder != nil {
+		err := c.recorder.save()
+		if err != nil {
+			c.ocm.Close()
+			return err
+		}
+	}
 	return c.ocm.Close()
 }
 
@@ -0,0 +1,73 @@
+package ocm
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/transport"
+)
+
+var _ = Describe("Client transport", func() {
+	var apiServer *ghttp.Server
+	var dials int
+	var dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	BeforeEach(func() {
+		apiServer = MakeTCPServer()
+		dials = 0
+		shared := transport.Shared()
+		dialContext = shared.DialContext
+		shared.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return dialContext(ctx, network, addr)
+		}
+	})
+
+	AfterEach(func() {
+		transport.Shared().DialContext = dialContext
+		transport.Shared().CloseIdleConnections()
+		apiServer.Close()
+	})
+
+	buildClient := func() *Client {
+		client, err := NewClient().
+			Logger(logrus.New()).
+			Config(&config.Config{
+				URL:         apiServer.URL(),
+				AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+			}).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		return client
+	}
+
+	It("reuses the connections of the shared transport across clients", func() {
+		for i := 0; i < 2; i++ {
+			apiServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/current_account"),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account", "id": "123"}`),
+			))
+		}
+		first := buildClient()
+		defer first.Close()
+		_, err := first.GetCurrentAccount()
+		Expect(err).NotTo(HaveOccurred())
+
+		second := buildClient()
+		defer second.Close()
+		_, err = second.GetCurrentAccount()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+		Expect(dials).To(Equal(1))
+	})
+})
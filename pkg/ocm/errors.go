@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+	"github.com/zgalor/weberr"
+)
+
+// APIError is an error returned by the OCM API. It keeps the HTTP status and the OCM error code, so
+// that callers can tell failures apart with errors.As, and it unwraps to the error returned by the
+// SDK.
+type APIError struct {
+	// Status is the HTTP status code of the response, for example 401 when the token has expired.
+	Status int
+
+	// Code is the OCM error code, for example 'CLUSTERS-MGMT-404'.
+	Code string
+
+	message string
+	err     error
+}
+
+func (e *APIError) Error() string {
+	return e.message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Type returns the status as the type used by the weberr package, so that the callers that check
+// it with weberr.GetType keep working.
+func (e *APIError) Type() weberr.ErrorType {
+	return weberr.ErrorType(e.Status)
+}
+
+// newAPIError returns the error for a failed request, with the given message. When the request
+// didn't get an OCM error in the response, for example because the connection failed, the result
+// is an untyped error with the message.
+func newAPIError(res *ocmerrors.Error, err error, message string) error {
+	if res == nil || res.Status() == 0 {
+		return weberr.Errorf("%s", message)
+	}
+	return &APIError{
+		Status:  res.Status(),
+		Code:    res.Code(),
+		message: message,
+		err:     err,
+	}
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/openshift/rosa/pkg/aws"
 	"github.com/openshift/rosa/pkg/logging"
@@ -27,6 +30,43 @@ import (
 
 var clusterKey string
 
+// DefaultMaxRetries is the number of times that failed requests are retried when the '--max-retries'
+// flag isn't set.
+const DefaultMaxRetries = 3
+
+var maxRetries = DefaultMaxRetries
+
+// AddMaxRetriesFlag adds the '--max-retries' flag to the given set of command line flags.
+func AddMaxRetriesFlag(flags *pflag.FlagSet) {
+	flags.IntVar(
+		&maxRetries,
+		"max-retries",
+		DefaultMaxRetries,
+		"Maximum number of times that requests to OCM failing with a transient error are retried.",
+	)
+}
+
+var failFast bool
+
+// AddFailFastFlag adds the '--fail-fast' flag to the given set of command line flags.
+func AddFailFastFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&failFast,
+		"fail-fast",
+		false,
+		"Don't retry the requests to OCM that fail with a transient error, ignoring '--max-retries'.",
+	)
+}
+
+// retryLimit returns the number of times that failed requests are retried, which is zero when the
+// '--fail-fast' flag is set.
+func retryLimit() int {
+	if failFast {
+		return 0
+	}
+	return maxRetries
+}
+
 func AddOptionalClusterFlag(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(
 		&clusterKey,
@@ -95,3 +135,44 @@ func clusterCompletion(cmd *cobra.Command, args []string, toComplete string) ([]
 	}
 	return res, cobra.ShellCompDirectiveDefault
 }
+
+// CompletionTimeout bounds the requests made to complete the values of flags, so that completion
+// returns nothing instead of hanging when offline.
+const CompletionTimeout = 5 * time.Second
+
+// RegionCompletion completes the '--region' flag with the regions available to the AWS account, or
+// with all the regions supported by ROSA when there are no AWS credentials.
+func RegionCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	logger := logging.NewLogger()
+
+	ocmClient, err := NewClient().Logger(logger).Build()
+	if err != nil {
+		return []string{}, cobra.ShellCompDirectiveDefault
+	}
+	defer ocmClient.Close()
+	ocmClient.EnableRegionCache()
+
+	roleARN := ""
+	if flag := cmd.Flags().Lookup("role-arn"); flag != nil {
+		roleARN = flag.Value.String()
+	}
+	var awsClient aws.Client
+	if roleARN == "" {
+		awsClient, err = aws.NewClient().Logger(logger).Build()
+		if err != nil {
+			regions, err := ocmClient.GetDatabaseRegionList()
+			if err != nil {
+				return []string{}, cobra.ShellCompDirectiveDefault
+			}
+			return regions, cobra.ShellCompDirectiveDefault
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CompletionTimeout)
+	defer cancel()
+	regions, _, err := ocmClient.GetRegionList(ctx, false, roleARN, "", "", awsClient, false, false)
+	if err != nil {
+		return []string{}, cobra.ShellCompDirectiveDefault
+	}
+	return regions, cobra.ShellCompDirectiveDefault
+}
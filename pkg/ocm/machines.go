@@ -17,42 +17,64 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	amsv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/helper"
 )
 
 const AcceleratedComputing = "accelerated_computing"
 
-func (c *Client) GetMachineTypesInRegion(cloudProviderData *cmv1.CloudProviderData) (MachineTypeList, error) {
-	collection := c.ocm.ClustersMgmt().V1().AWSInquiries().MachineTypes()
+// maxConcurrentZones is the number of availability zones whose machine types are fetched at the same
+// time.
+const maxConcurrentZones = 4
+
+const (
+	ArchitectureX86   = "x86_64"
+	ArchitectureArm64 = "arm64"
+)
+
+// deprecatedFamilies are the AWS instance families of the previous generation, which AWS recommends
+// replacing with current ones. OCM doesn't report which machine types are deprecated, so this list
+// has to be updated when AWS retires more families.
+var deprecatedFamilies = []string{"c1", "c3", "c4", "g2", "g3", "i2", "m1", "m2", "m3", "m4", "p2", "r3",
+	"r4", "t1"}
+
+// Architectures contains the CPU architectures that machine types can have.
+var Architectures = []string{ArchitectureX86, ArchitectureArm64}
+
+// gravitonFamilyRE matches the AWS instance families that run on Graviton (arm64) processors. These
+// have a 'g' right after the generation number, for example 'm6g', 'c7gn' or 'x2gd'.
+var gravitonFamilyRE = regexp.MustCompile(`^([a-z]+[0-9]+g|a1)`)
+
+// generationFamilyRE matches the AWS instance families that encode their generation as the number
+// after the letters of the class, for example 'm5', 'c7gn' or 'x2iedn'.
+var generationFamilyRE = regexp.MustCompile(`^[a-z]+([0-9]+)[a-z]*$`)
+
+func (c *Client) GetMachineTypesInRegion(ctx context.Context,
+	cloudProviderData *cmv1.CloudProviderData) (MachineTypeList, error) {
 	page := 1
 	size := 100
 
 	var machineTypes MachineTypeList
 	for {
-		response, err := collection.Search().
-			Parameter("order", "category asc").
-			Body(cloudProviderData).
-			Page(page).
-			Size(size).
-			Send()
+		items, _, err := c.GetMachineTypesPageInRegion(ctx, cloudProviderData, page, size)
 		if err != nil {
 			return MachineTypeList{}, err
 		}
+		machineTypes = append(machineTypes, items...)
 
-		response.Items().Each(func(item *cmv1.MachineType) bool {
-			machineTypes = append(machineTypes, &MachineType{
-				MachineType: item,
-			})
-			return true
-		})
-
-		if response.Size() < size {
+		if len(items) < size {
 			break
 		}
 		page++
@@ -61,40 +83,90 @@ func (c *Client) GetMachineTypesInRegion(cloudProviderData *cmv1.CloudProviderDa
 	return machineTypes, nil
 }
 
-func (c *Client) GetMachineTypes() (machineTypes MachineTypeList, err error) {
-	collection := c.ocm.ClustersMgmt().V1().MachineTypes()
+// GetMachineTypesPageInRegion returns the given page of the machine types in the region described
+// by the cloud provider data, and the total number of machine types. Pages are numbered from 1.
+func (c *Client) GetMachineTypesPageInRegion(ctx context.Context, cloudProviderData *cmv1.CloudProviderData,
+	page int, size int) (MachineTypeList, int, error) {
+	response, err := c.ocm.ClustersMgmt().V1().AWSInquiries().MachineTypes().Search().
+		Parameter("order", "category asc").
+		Body(cloudProviderData).
+		Page(page).
+		Size(size).
+		SendContext(ctx)
+	if err != nil {
+		return MachineTypeList{}, 0, handleErr(response.Error(), err)
+	}
+
+	var machineTypes MachineTypeList
+	response.Items().Each(func(item *cmv1.MachineType) bool {
+		machineTypes = append(machineTypes, &MachineType{
+			MachineType: item,
+		})
+		return true
+	})
+	return machineTypes, response.Total(), nil
+}
+
+func (c *Client) GetMachineTypes(ctx context.Context) (machineTypes MachineTypeList, err error) {
+	err = c.eachMachineTypesPage(ctx, func(page MachineTypeList) error {
+		machineTypes = append(machineTypes, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// eachMachineTypesPage calls fn with each page of the AWS machine types supported by ROSA, as soon
+// as it is received.
+func (c *Client) eachMachineTypesPage(ctx context.Context, fn func(page MachineTypeList) error) error {
 	page := 1
 	size := 100
 	for {
-		var response *cmv1.MachineTypesListResponse
-		response, err := collection.List().
-			Search("cloud_provider.id = 'aws'").
-			Order("category asc").
-			Page(page).
-			Size(size).
-			Send()
+		machineTypes, _, err := c.getMachineTypesPage(ctx, page, size)
 		if err != nil {
-			errMsg := response.Error().Reason()
-			if errMsg == "" {
-				errMsg = err.Error()
-			}
-			return nil, errors.New(errMsg)
+			return err
+		}
+		err = fn(machineTypes)
+		if err != nil {
+			return err
 		}
 
-		response.Items().Each(func(item *cmv1.MachineType) bool {
-			machineTypes = append(machineTypes, &MachineType{
-				MachineType: item,
-			})
-			return true
-		})
-
-		if response.Size() < size {
+		if len(machineTypes) < size {
 			break
 		}
 		page++
 	}
 
-	return
+	return nil
+}
+
+// getMachineTypesPage returns the given page of the AWS machine types supported by ROSA, and the
+// total number of them.
+func (c *Client) getMachineTypesPage(ctx context.Context, page int, size int) (MachineTypeList, int, error) {
+	response, err := c.ocm.ClustersMgmt().V1().MachineTypes().List().
+		Search("cloud_provider.id = 'aws'").
+		Order("category asc").
+		Page(page).
+		Size(size).
+		SendContext(ctx)
+	if err != nil {
+		errMsg := response.Error().Reason()
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return nil, 0, errors.New(errMsg)
+	}
+
+	var machineTypes MachineTypeList
+	response.Items().Each(func(item *cmv1.MachineType) bool {
+		machineTypes = append(machineTypes, &MachineType{
+			MachineType: item,
+		})
+		return true
+	})
+	return machineTypes, response.Total(), nil
 }
 
 func getDefaultNodes(multiAZ bool) int {
@@ -106,21 +178,113 @@ func getDefaultNodes(multiAZ bool) int {
 }
 
 type MachineType struct {
-	MachineType    *cmv1.MachineType
-	Available      bool
-	availableQuota int
+	MachineType *cmv1.MachineType
+	Available   bool
+	// AvailabilityZones lists the zones the machine type is offered in. It is only populated by
+	// GetAvailableMachineTypesInZones.
+	AvailabilityZones []string
+	availableQuota    int
+	hasQuotaCost      bool
+	hourlyPrice       float64
+	hasPrice          bool
+	supportsIPv6      bool
+	hasNetworkInfo    bool
+}
+
+// Family returns the AWS instance family of the machine type, which is the part of the ID before the
+// size, for example 'm5' for 'm5.xlarge'.
+func (mt MachineType) Family() string {
+	return strings.SplitN(mt.MachineType.ID(), ".", 2)[0]
+}
+
+// Architecture returns the CPU architecture of the machine type. The OCM API doesn't report it, so it
+// is derived from the AWS instance family encoded in the ID.
+func (mt MachineType) Architecture() string {
+	if gravitonFamilyRE.MatchString(mt.Family()) {
+		return ArchitectureArm64
+	}
+	return ArchitectureX86
+}
+
+// Deprecated returns true if the machine type belongs to an AWS instance family of the previous
+// generation.
+func (mt MachineType) Deprecated() bool {
+	return helper.Contains(deprecatedFamilies, mt.Family())
+}
+
+// Generation returns the generation of the AWS instance family of the machine type, for example 5 for
+// 'm5.xlarge' or 7 for 'c7gn.large', and false if the family doesn't encode it, like the high memory
+// 'u-6tb1' family.
+func (mt MachineType) Generation() (int, bool) {
+	match := generationFamilyRE.FindStringSubmatch(mt.Family())
+	if match == nil {
+		return 0, false
+	}
+	generation, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return generation, true
 }
 
 func (mt MachineType) HasQuota(multiAZ bool) bool {
 	return mt.MachineType.Category() != AcceleratedComputing || mt.availableQuota > getDefaultNodes(multiAZ)
 }
 
+// AvailableQuota returns the number of instances of the machine type that the account has quota
+// for, and false if there is no quota information for it, which is the case for all the machine
+// types except the accelerated computing ones.
+func (mt MachineType) AvailableQuota() (int, bool) {
+	return mt.availableQuota, mt.hasQuotaCost
+}
+
+// QuotaShortfall returns how many instances of the machine type are missing from the quota of the
+// account to run the given number of them. Only the accelerated computing machine types are limited
+// by quota, so for the rest it is always zero.
+func (mt MachineType) QuotaShortfall(count int) int {
+	if mt.MachineType.Category() != AcceleratedComputing || mt.availableQuota >= count {
+		return 0
+	}
+	return count - mt.availableQuota
+}
+
+// HourlyPrice returns the on-demand price of the machine type, in US dollars per hour, and false if
+// it isn't known. OCM doesn't report the prices of the machine types yet, so for now it is never
+// known.
+func (mt MachineType) HourlyPrice() (float64, bool) {
+	return mt.hourlyPrice, mt.hasPrice
+}
+
+// SupportsIPv6 returns true if the machine type supports IPv6 and dual-stack networking, and false
+// as second value if it isn't known. OCM doesn't report the network capabilities of the machine
+// types yet, so for now it is never known.
+func (mt MachineType) SupportsIPv6() (bool, bool) {
+	return mt.supportsIPv6, mt.hasNetworkInfo
+}
+
+// UnavailableReason explains why the machine type can't be used to create a cluster, or returns an
+// empty string if it can.
+func (mt MachineType) UnavailableReason() string {
+	if mt.MachineType.Category() != AcceleratedComputing {
+		return ""
+	}
+	if !mt.hasQuotaCost {
+		return "The account has no quota for this instance type"
+	}
+	if !mt.HasQuota(false) {
+		return fmt.Sprintf("The account has quota for only %d instances, a cluster needs more than %d",
+			mt.availableQuota, getDefaultNodes(false))
+	}
+	return ""
+}
+
 // GetAvailableMachineTypesInRegion get the supported machine type in the region.
 // The function triggers the 'api/clusters_mgmt/v1/aws_inquiries/machine_types'
-// and passes a role ARN for STS clusters or access keys for non-STS clusters.
-func (c *Client) GetAvailableMachineTypesInRegion(region string, availabilityZones []string, roleARN string,
-	awsClient aws.Client) (MachineTypeList, error) {
-	cloudProviderDataBuilder, err := c.createCloudProviderDataBuilder(roleARN, awsClient, "")
+// and passes a role ARN, and optionally the external ID needed to assume it, for STS clusters or
+// access keys for non-STS clusters.
+func (c *Client) GetAvailableMachineTypesInRegion(ctx context.Context, region string, availabilityZones []string,
+	roleARN string, externalID string, awsClient aws.Client) (MachineTypeList, error) {
+	cloudProviderDataBuilder, err := c.createCloudProviderDataBuilder(roleARN, awsClient, externalID)
 	if err != nil {
 		return MachineTypeList{}, err
 	}
@@ -132,12 +296,119 @@ func (c *Client) GetAvailableMachineTypesInRegion(region string, availabilityZon
 		return MachineTypeList{}, err
 	}
 
-	machineTypes, err := c.GetMachineTypesInRegion(cloudProviderData)
+	machineTypes, err := c.GetMachineTypesInRegion(ctx, cloudProviderData)
+	if err != nil {
+		return MachineTypeList{}, err
+	}
+
+	quotaCosts, err := c.getQuotaCosts(ctx)
+	if err != nil {
+		return MachineTypeList{}, err
+	}
+
+	machineTypes.UpdateAvailableQuota(quotaCosts)
+	return machineTypes, nil
+}
+
+// GetAvailableMachineTypesPageInRegion is like GetAvailableMachineTypesInRegion, but it returns only
+// the given page of machine types, along with the total number of them. Pages are numbered from 1.
+func (c *Client) GetAvailableMachineTypesPageInRegion(ctx context.Context, region string, roleARN string,
+	externalID string, awsClient aws.Client, page int, size int) (MachineTypeList, int, error) {
+	cloudProviderDataBuilder, err := c.createCloudProviderDataBuilder(roleARN, awsClient, externalID)
+	if err != nil {
+		return MachineTypeList{}, 0, err
+	}
+	cloudProviderData, err := cloudProviderDataBuilder.Region(cmv1.NewCloudRegion().ID(region)).Build()
+	if err != nil {
+		return MachineTypeList{}, 0, err
+	}
+
+	machineTypes, total, err := c.GetMachineTypesPageInRegion(ctx, cloudProviderData, page, size)
+	if err != nil {
+		return MachineTypeList{}, 0, err
+	}
+
+	quotaCosts, err := c.getQuotaCosts(ctx)
+	if err != nil {
+		return MachineTypeList{}, 0, err
+	}
+
+	machineTypes.UpdateAvailableQuota(quotaCosts)
+	return machineTypes, total, nil
+}
+
+// GetSortedMachineTypesInRegion is like GetAvailableMachineTypesInRegion, but the machine types are
+// sorted by ID, so that the result doesn't depend on the order of the response.
+func (c *Client) GetSortedMachineTypesInRegion(ctx context.Context, region string, availabilityZones []string,
+	roleARN string, externalID string, awsClient aws.Client) (MachineTypeList, error) {
+	machineTypes, err := c.GetAvailableMachineTypesInRegion(ctx, region, availabilityZones, roleARN, externalID,
+		awsClient)
+	if err != nil {
+		return MachineTypeList{}, err
+	}
+	machineTypes.SortByID()
+	return machineTypes, nil
+}
+
+// GetAvailableMachineTypesInZones gets the supported machine types in the region, recording for each
+// one the subset of the given availability zones it is offered in. The inquiry endpoint only returns
+// the machine types available in all of the zones it is given, so it is queried once per zone.
+func (c *Client) GetAvailableMachineTypesInZones(ctx context.Context, region string, availabilityZones []string,
+	roleARN string, externalID string, awsClient aws.Client) (MachineTypeList, error) {
+	// The credentials are the same for all the zones, so they are prepared only once:
+	cloudProviderDataBuilder, err := c.createCloudProviderDataBuilder(roleARN, awsClient, externalID)
 	if err != nil {
 		return MachineTypeList{}, err
 	}
+	cloudProviderData := make([]*cmv1.CloudProviderData, len(availabilityZones))
+	for i, zone := range availabilityZones {
+		cloudProviderData[i], err = cloudProviderDataBuilder.
+			AvailabilityZones(zone).
+			Region(cmv1.NewCloudRegion().ID(region)).
+			Build()
+		if err != nil {
+			return MachineTypeList{}, err
+		}
+	}
+
+	// Fetch the machine types of the zones concurrently, and merge them in the order of the zones so
+	// that the result doesn't depend on which request finishes first:
+	results := make([]MachineTypeList, len(availabilityZones))
+	errs := make([]error, len(availabilityZones))
+	durations := make([]time.Duration, len(availabilityZones))
+	start := time.Now()
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentZones)
+	for i := range availabilityZones {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			begin := time.Now()
+			results[i], errs[i] = c.GetMachineTypesInRegion(ctx, cloudProviderData[i])
+			durations[i] = time.Since(begin)
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	var machineTypes MachineTypeList
+	var sequential time.Duration
+	for i, zone := range availabilityZones {
+		if errs[i] != nil {
+			return MachineTypeList{}, errs[i]
+		}
+		machineTypes = machineTypes.mergeZone(results[i], zone)
+		sequential += durations[i]
+	}
+	if len(availabilityZones) > 1 {
+		c.debugf("Fetched the machine types of %d availability zones in %s, %s less than one after the other",
+			len(availabilityZones), elapsed.Round(time.Millisecond), (sequential - elapsed).Round(time.Millisecond))
+	}
 
-	quotaCosts, err := c.getQuotaCosts()
+	quotaCosts, err := c.getQuotaCosts(ctx)
 	if err != nil {
 		return MachineTypeList{}, err
 	}
@@ -146,13 +417,13 @@ func (c *Client) GetAvailableMachineTypesInRegion(region string, availabilityZon
 	return machineTypes, nil
 }
 
-func (c *Client) GetAvailableMachineTypes() (MachineTypeList, error) {
-	machineTypes, err := c.GetMachineTypes()
+func (c *Client) GetAvailableMachineTypes(ctx context.Context) (MachineTypeList, error) {
+	machineTypes, err := c.GetMachineTypes(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	quotaCosts, err := c.getQuotaCosts()
+	quotaCosts, err := c.getQuotaCosts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -161,10 +432,41 @@ func (c *Client) GetAvailableMachineTypes() (MachineTypeList, error) {
 	return machineTypes, nil
 }
 
-func (c *Client) getQuotaCosts() (*amsv1.QuotaCostList, error) {
+// GetAvailableMachineTypesPage is like GetAvailableMachineTypes, but it returns only the given page
+// of machine types, along with the total number of them. Pages are numbered from 1.
+func (c *Client) GetAvailableMachineTypesPage(ctx context.Context, page int,
+	size int) (MachineTypeList, int, error) {
+	machineTypes, total, err := c.getMachineTypesPage(ctx, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	quotaCosts, err := c.getQuotaCosts(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	machineTypes.UpdateAvailableQuota(quotaCosts)
+	return machineTypes, total, nil
+}
+
+// StreamAvailableMachineTypes is like GetAvailableMachineTypes, but calls fn with each page of
+// machine types as soon as it is received instead of returning them all at once.
+func (c *Client) StreamAvailableMachineTypes(ctx context.Context, fn func(page MachineTypeList) error) error {
+	quotaCosts, err := c.getQuotaCosts(ctx)
+	if err != nil {
+		return err
+	}
+	return c.eachMachineTypesPage(ctx, func(page MachineTypeList) error {
+		page.UpdateAvailableQuota(quotaCosts)
+		return fn(page)
+	})
+}
+
+func (c *Client) getQuotaCosts(ctx context.Context) (*amsv1.QuotaCostList, error) {
 	acctResponse, err := c.ocm.AccountsMgmt().V1().CurrentAccount().
 		Get().
-		Send()
+		SendContext(ctx)
 	if err != nil {
 		return nil, handleErr(acctResponse.Error(), err)
 	}
@@ -177,7 +479,7 @@ func (c *Client) getQuotaCosts() (*amsv1.QuotaCostList, error) {
 		Parameter("search", "quota_id~='gpu'").
 		Page(1).
 		Size(-1).
-		Send()
+		SendContext(ctx)
 	if err != nil {
 		return nil, handleErr(quotaCostResponse.Error(), err)
 	}
@@ -218,6 +520,48 @@ func (mtl *MachineTypeList) Filter(fn func(*MachineType) bool) MachineTypeList {
 	return res
 }
 
+// mergeZone adds the machine types offered in the given zone to the list, recording the zone on each
+// of them. Machine types already in the list keep their position.
+func (mtl MachineTypeList) mergeZone(zoneMachineTypes MachineTypeList, zone string) MachineTypeList {
+	for _, zoneMachineType := range zoneMachineTypes {
+		machineType := mtl.Find(zoneMachineType.MachineType.ID())
+		if machineType == nil {
+			machineType = zoneMachineType
+			mtl = append(mtl, machineType)
+		}
+		if !helper.Contains(machineType.AvailabilityZones, zone) {
+			machineType.AvailabilityZones = append(machineType.AvailabilityZones, zone)
+		}
+	}
+	return mtl
+}
+
+// Deduplicate returns a new MachineTypeList with a single element per machine type ID, keeping the
+// first one and merging into it the availability zones of the others.
+func (mtl MachineTypeList) Deduplicate() MachineTypeList {
+	var res MachineTypeList
+	for _, machineType := range mtl {
+		first := res.Find(machineType.MachineType.ID())
+		if first == nil {
+			res = append(res, machineType)
+			continue
+		}
+		for _, zone := range machineType.AvailabilityZones {
+			if !helper.Contains(first.AvailabilityZones, zone) {
+				first.AvailabilityZones = append(first.AvailabilityZones, zone)
+			}
+		}
+	}
+	return res
+}
+
+// SortByID sorts the machine types by ID in place.
+func (mtl MachineTypeList) SortByID() {
+	sort.SliceStable(mtl, func(i, j int) bool {
+		return mtl[i].MachineType.ID() < mtl[j].MachineType.ID()
+	})
+}
+
 func (mtl *MachineTypeList) UpdateAvailableQuota(quotaCosts *amsv1.QuotaCostList) {
 	for _, machineType := range *mtl {
 		if machineType.MachineType.Category() != AcceleratedComputing {
@@ -230,6 +574,7 @@ func (mtl *MachineTypeList) UpdateAvailableQuota(quotaCosts *amsv1.QuotaCostList
 					availableQuota := (quotaCost.Allowed() - quotaCost.Consumed()) / relatedResource.Cost()
 					machineType.Available = availableQuota > 1
 					machineType.availableQuota = availableQuota
+					machineType.hasQuotaCost = true
 					return false
 				}
 			}
@@ -0,0 +1,157 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+)
+
+var _ = Describe("Machine types inquiry", func() {
+	var apiServer *ghttp.Server
+	var ocmClient *Client
+
+	BeforeEach(func() {
+		apiServer = MakeTCPServer()
+		logger, err := logging.NewGoLoggerBuilder().Build()
+		Expect(err).NotTo(HaveOccurred())
+		connection, err := sdk.NewConnectionBuilder().
+			Logger(logger).
+			Tokens(MakeTokenString("Bearer", 15*time.Minute)).
+			URL(apiServer.URL()).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		ocmClient = &Client{ocm: connection}
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		Expect(ocmClient.Close()).To(Succeed())
+	})
+
+	It("passes the external ID needed to assume the role", func() {
+		roleARN := "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/aws_inquiries/machine_types"),
+				func(w http.ResponseWriter, r *http.Request) {
+					defer GinkgoRecover()
+					cloudProviderData, err := cmv1.UnmarshalCloudProviderData(r.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cloudProviderData.AWS().STS().RoleARN()).To(Equal(roleARN))
+					Expect(cloudProviderData.AWS().STS().ExternalID()).To(Equal("8f4a2c1e"))
+					Expect(cloudProviderData.Region().ID()).To(Equal("us-east-1"))
+				},
+				RespondWithJSON(http.StatusOK, `{
+				  "kind": "MachineTypeList",
+				  "page": 1,
+				  "size": 1,
+				  "total": 1,
+				  "items": [
+				    {
+				      "kind": "MachineType",
+				      "id": "m5.xlarge",
+				      "category": "general_purpose"
+				    }
+				  ]
+				}`),
+			),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "Account",
+			  "organization": {
+			    "kind": "Organization",
+			    "id": "123"
+			  }
+			}`),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "QuotaCostList",
+			  "page": 1,
+			  "size": 0,
+			  "total": 0,
+			  "items": []
+			}`),
+		)
+
+		machineTypes, err := ocmClient.GetAvailableMachineTypesInRegion(context.Background(), "us-east-1", nil, roleARN,
+			"8f4a2c1e", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge"}))
+	})
+
+	It("merges the machine types of the zones in their order when fetched concurrently", func() {
+		zoneItems := map[string]string{
+			"us-east-1a": `{"kind": "MachineType", "id": "m5.xlarge"}, {"kind": "MachineType", "id": "r5.xlarge"}`,
+			"us-east-1b": `{"kind": "MachineType", "id": "m5.xlarge"}`,
+			"us-east-1c": `{"kind": "MachineType", "id": "c5.xlarge"}`,
+		}
+		apiServer.RouteToHandler(http.MethodPost, "/api/clusters_mgmt/v1/aws_inquiries/machine_types",
+			func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				cloudProviderData, err := cmv1.UnmarshalCloudProviderData(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				zone := cloudProviderData.AvailabilityZones()[0]
+				// Make the first zone finish last:
+				if zone == "us-east-1a" {
+					time.Sleep(50 * time.Millisecond)
+				}
+				RespondWithJSON(http.StatusOK, fmt.Sprintf(
+					`{"kind": "MachineTypeList", "page": 1, "size": 1, "total": 1, "items": [%s]}`,
+					zoneItems[zone]))(w, r)
+			})
+		apiServer.RouteToHandler(http.MethodGet, "/api/accounts_mgmt/v1/current_account",
+			RespondWithJSON(http.StatusOK, `{"kind": "Account", "organization": {"kind": "Organization", "id": "123"}}`))
+		apiServer.RouteToHandler(http.MethodGet, "/api/accounts_mgmt/v1/organizations/123/quota_cost",
+			RespondWithJSON(http.StatusOK, `{"kind": "QuotaCostList", "page": 1, "size": 0, "total": 0, "items": []}`))
+
+		machineTypes, err := ocmClient.GetAvailableMachineTypesInZones(context.Background(), "us-east-1",
+			[]string{"us-east-1a", "us-east-1b", "us-east-1c"},
+			"arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "r5.xlarge", "c5.xlarge"}))
+		Expect(machineTypes[0].AvailabilityZones).To(Equal([]string{"us-east-1a", "us-east-1b"}))
+	})
+
+	It("sorts the machine types by ID regardless of the order of the response", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "MachineTypeList",
+			  "page": 1,
+			  "size": 4,
+			  "total": 4,
+			  "items": [
+			    {"kind": "MachineType", "id": "r5.xlarge", "category": "memory_optimized"},
+			    {"kind": "MachineType", "id": "c5.2xlarge", "category": "compute_optimized"},
+			    {"kind": "MachineType", "id": "m5.xlarge", "category": "general_purpose"},
+			    {"kind": "MachineType", "id": "m5.2xlarge", "category": "general_purpose"}
+			  ]
+			}`),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "Account",
+			  "organization": {
+			    "kind": "Organization",
+			    "id": "123"
+			  }
+			}`),
+			RespondWithJSON(http.StatusOK, `{
+			  "kind": "QuotaCostList",
+			  "page": 1,
+			  "size": 0,
+			  "total": 0,
+			  "items": []
+			}`),
+		)
+
+		machineTypes, err := ocmClient.GetSortedMachineTypesInRegion(context.Background(), "us-east-1", nil,
+			"arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"c5.2xlarge", "m5.2xlarge", "m5.xlarge", "r5.xlarge"}))
+	})
+})
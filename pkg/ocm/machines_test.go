@@ -0,0 +1,141 @@
+package ocm
+
+import (
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/ginkgo/v2/dsl/table"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Machine types", func() {
+	DescribeTable("Family",
+		func(id string, expected string) {
+			machineType, err := cmv1.NewMachineType().ID(id).GenericName("standard-4").Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(MachineType{MachineType: machineType}.Family()).To(Equal(expected))
+		},
+		Entry("general purpose", "m5.xlarge", "m5"),
+		Entry("with suffixes", "c7gn.large", "c7gn"),
+		Entry("high memory", "u-6tb1.metal", "u-6tb1"),
+	)
+
+	DescribeTable("Architecture",
+		func(id string, expected string) {
+			machineType, err := cmv1.NewMachineType().ID(id).Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(MachineType{MachineType: machineType}.Architecture()).To(Equal(expected))
+		},
+		Entry("general purpose x86", "m5.xlarge", ArchitectureX86),
+		Entry("GPU x86", "g4dn.2xlarge", ArchitectureX86),
+		Entry("Graviton", "m6g.xlarge", ArchitectureArm64),
+		Entry("Graviton with suffixes", "c7gn.large", ArchitectureArm64),
+		Entry("Graviton storage", "is4gen.xlarge", ArchitectureArm64),
+		Entry("Graviton GPU", "g5g.2xlarge", ArchitectureArm64),
+		Entry("first generation Graviton", "a1.large", ArchitectureArm64),
+	)
+
+	DescribeTable("Generation",
+		func(id string, expected int, ok bool) {
+			machineType, err := cmv1.NewMachineType().ID(id).Build()
+			Expect(err).NotTo(HaveOccurred())
+			generation, parsed := MachineType{MachineType: machineType}.Generation()
+			Expect(parsed).To(Equal(ok))
+			Expect(generation).To(Equal(expected))
+		},
+		Entry("general purpose", "m5.xlarge", 5, true),
+		Entry("with suffixes", "c7gn.large", 7, true),
+		Entry("with many suffixes", "x2iedn.xlarge", 2, true),
+		Entry("long class", "inf2.xlarge", 2, true),
+		Entry("two digits", "m10.large", 10, true),
+		Entry("metal size", "m5.metal", 5, true),
+		Entry("storage suffix after the generation", "is4gen.xlarge", 4, true),
+		Entry("high memory", "u-6tb1.metal", 0, false),
+		Entry("dash after the generation", "mac2-m2pro.metal", 0, false),
+		Entry("no generation", "cc.large", 0, false),
+		Entry("empty", "", 0, false),
+	)
+
+	It("merges machine types per availability zone", func() {
+		build := func(ids ...string) MachineTypeList {
+			var list MachineTypeList
+			for _, id := range ids {
+				machineType, err := cmv1.NewMachineType().ID(id).Build()
+				Expect(err).NotTo(HaveOccurred())
+				list = append(list, &MachineType{MachineType: machineType})
+			}
+			return list
+		}
+
+		var machineTypes MachineTypeList
+		machineTypes = machineTypes.mergeZone(build("m5.xlarge", "p3.2xlarge"), "us-east-1a")
+		machineTypes = machineTypes.mergeZone(build("m5.xlarge", "c5.xlarge"), "us-east-1b")
+
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "p3.2xlarge", "c5.xlarge"}))
+		Expect(machineTypes.Find("m5.xlarge").AvailabilityZones).To(Equal([]string{"us-east-1a", "us-east-1b"}))
+		Expect(machineTypes.Find("p3.2xlarge").AvailabilityZones).To(Equal([]string{"us-east-1a"}))
+		Expect(machineTypes.Find("c5.xlarge").AvailabilityZones).To(Equal([]string{"us-east-1b"}))
+	})
+
+	DescribeTable("UnavailableReason",
+		func(category string, hasQuotaCost bool, availableQuota int, expected string) {
+			machineType, err := cmv1.NewMachineType().
+				ID("p3.2xlarge").
+				Category(cmv1.MachineTypeCategory(category)).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(MachineType{
+				MachineType:    machineType,
+				hasQuotaCost:   hasQuotaCost,
+				availableQuota: availableQuota,
+			}.UnavailableReason()).To(Equal(expected))
+		},
+		Entry("not accelerated", "general_purpose", false, 0, ""),
+		Entry("no quota", AcceleratedComputing, false, 0, "The account has no quota for this instance type"),
+		Entry("not enough quota", AcceleratedComputing, true, 2,
+			"The account has quota for only 2 instances, a cluster needs more than 2"),
+		Entry("enough quota", AcceleratedComputing, true, 3, ""),
+	)
+
+	DescribeTable("QuotaShortfall",
+		func(category string, availableQuota int, count int, expected int) {
+			machineType, err := cmv1.NewMachineType().
+				ID("p3.2xlarge").
+				Category(cmv1.MachineTypeCategory(category)).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(MachineType{
+				MachineType:    machineType,
+				hasQuotaCost:   true,
+				availableQuota: availableQuota,
+			}.QuotaShortfall(count)).To(Equal(expected))
+		},
+		Entry("not accelerated", "general_purpose", 0, 10, 0),
+		Entry("enough quota", AcceleratedComputing, 4, 4, 0),
+		Entry("not enough quota", AcceleratedComputing, 4, 7, 3),
+	)
+
+	It("deduplicates machine types returned more than once", func() {
+		build := func(id string, zones ...string) *MachineType {
+			machineType, err := cmv1.NewMachineType().ID(id).Build()
+			Expect(err).NotTo(HaveOccurred())
+			return &MachineType{MachineType: machineType, AvailabilityZones: zones}
+		}
+
+		// A zone whose response lists the same machine type twice:
+		var machineTypes MachineTypeList
+		machineTypes = machineTypes.mergeZone(MachineTypeList{
+			build("m5.xlarge"), build("m5.xlarge"), build("c5.xlarge"),
+		}, "us-east-1a")
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "c5.xlarge"}))
+		Expect(machineTypes.Find("m5.xlarge").AvailabilityZones).To(Equal([]string{"us-east-1a"}))
+
+		machineTypes = MachineTypeList{
+			build("m5.xlarge", "us-east-1a"),
+			build("c5.xlarge", "us-east-1b"),
+			build("m5.xlarge", "us-east-1a", "us-east-1c"),
+		}.Deduplicate()
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "c5.xlarge"}))
+		Expect(machineTypes.Find("m5.xlarge").AvailabilityZones).To(Equal([]string{"us-east-1a", "us-east-1c"}))
+		Expect(machineTypes.Find("c5.xlarge").AvailabilityZones).To(Equal([]string{"us-east-1b"}))
+	})
+})
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/openshift/rosa/pkg/aws"
+)
+
+// rawPageSize is the number of machine types requested in each page of the raw queries, the same
+// used by the regular ones.
+const rawPageSize = 100
+
+// GetRawMachineTypesInRegion makes the same requests as GetAvailableMachineTypesInRegion, without
+// availability zones, but returns the bodies of the responses exactly as OCM sent them, one per
+// page, instead of parsing them.
+func (c *Client) GetRawMachineTypesInRegion(ctx context.Context, region string, roleARN string,
+	externalID string, awsClient aws.Client) ([][]byte, error) {
+	cloudProviderDataBuilder, err := c.createCloudProviderDataBuilder(roleARN, awsClient, externalID)
+	if err != nil {
+		return nil, err
+	}
+	cloudProviderData, err := cloudProviderDataBuilder.Region(cmv1.NewCloudRegion().ID(region)).Build()
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	err = cmv1.MarshalCloudProviderData(cloudProviderData, &body)
+	if err != nil {
+		return nil, err
+	}
+	return c.getRawPages(ctx, func(page int) *sdk.Request {
+		return c.ocm.Post().
+			Path("/api/clusters_mgmt/v1/aws_inquiries/machine_types").
+			Header("Content-Type", "application/json").
+			Parameter("order", "category asc").
+			Parameter("page", page).
+			Parameter("size", rawPageSize).
+			Bytes(body.Bytes())
+	})
+}
+
+// GetRawMachineTypes makes the same requests as GetMachineTypes, but returns the bodies of the
+// responses exactly as OCM sent them, one per page, instead of parsing them.
+func (c *Client) GetRawMachineTypes(ctx context.Context) ([][]byte, error) {
+	return c.getRawPages(ctx, func(page int) *sdk.Request {
+		return c.ocm.Get().
+			Path("/api/clusters_mgmt/v1/machine_types").
+			Parameter("search", "cloud_provider.id = 'aws'").
+			Parameter("order", "category asc").
+			Parameter("page", page).
+			Parameter("size", rawPageSize)
+	})
+}
+
+// getRawPages sends the requests returned by the given function for each page, until a page has
+// fewer items than requested. Responses with an error status are returned as OCM errors.
+func (c *Client) getRawPages(ctx context.Context, request func(page int) *sdk.Request) ([][]byte, error) {
+	var pages [][]byte
+	for page := 1; ; page++ {
+		response, err := request(page).SendContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if response.Status() >= http.StatusBadRequest {
+			ocmErr, err := ocmerrors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+			if err != nil {
+				return nil, err
+			}
+			return nil, ocmErr
+		}
+		pages = append(pages, response.Bytes())
+
+		var list struct {
+			Size int `json:"size"`
+		}
+		err = json.Unmarshal(response.Bytes(), &list)
+		if err != nil {
+			return nil, err
+		}
+		if list.Size < rawPageSize {
+			return pages, nil
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package ocm
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+	"github.com/openshift-online/ocm-sdk-go/logging"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+)
+
+var _ = Describe("Raw machine types", func() {
+	var apiServer *ghttp.Server
+	var ocmClient *Client
+
+	BeforeEach(func() {
+		apiServer = MakeTCPServer()
+		logger, err := logging.NewGoLoggerBuilder().Build()
+		Expect(err).NotTo(HaveOccurred())
+		connection, err := sdk.NewConnectionBuilder().
+			Logger(logger).
+			Tokens(MakeTokenString("Bearer", 15*time.Minute)).
+			URL(apiServer.URL()).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		ocmClient = &Client{ocm: connection}
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		Expect(ocmClient.Close()).To(Succeed())
+	})
+
+	It("returns the body of the inquiry exactly as received, including the unavailable types", func() {
+		const body = `{"kind":"MachineTypeList","page":1,"size":1,"total":1,` +
+			`"items":[{"kind":"MachineType","id":"p4d.24xlarge","category":"accelerated_computing"}]}`
+		roleARN := "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/aws_inquiries/machine_types",
+					"order=category+asc&page=1&size=100"),
+				func(w http.ResponseWriter, r *http.Request) {
+					defer GinkgoRecover()
+					cloudProviderData, err := cmv1.UnmarshalCloudProviderData(r.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cloudProviderData.AWS().STS().RoleARN()).To(Equal(roleARN))
+					Expect(cloudProviderData.Region().ID()).To(Equal("eu-west-1"))
+				},
+				RespondWithJSON(http.StatusOK, body),
+			),
+		)
+
+		pages, err := ocmClient.GetRawMachineTypesInRegion(context.Background(), "eu-west-1", roleARN, "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pages).To(HaveLen(1))
+		Expect(string(pages[0])).To(Equal(body))
+	})
+
+	It("requests the next page while the pages are full", func() {
+		fullPage := `{"kind":"MachineTypeList","page":1,"size":100,"total":101,"items":[]}`
+		lastPage := `{"kind":"MachineTypeList","page":2,"size":1,"total":101,"items":[]}`
+		apiServer.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/machine_types"),
+				ghttp.VerifyFormKV("page", "1"),
+				RespondWithJSON(http.StatusOK, fullPage),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyFormKV("page", "2"),
+				RespondWithJSON(http.StatusOK, lastPage),
+			),
+		)
+
+		pages, err := ocmClient.GetRawMachineTypes(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pages).To(HaveLen(2))
+		Expect(string(pages[1])).To(Equal(lastPage))
+	})
+
+	It("returns the errors of OCM with their status", func() {
+		apiServer.AppendHandlers(RespondWithJSON(http.StatusForbidden, `{
+		  "kind": "Error",
+		  "id": "403",
+		  "code": "CLUSTERS-MGMT-403",
+		  "reason": "Forbidden"
+		}`))
+
+		_, err := ocmClient.GetRawMachineTypes(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("Forbidden")))
+		ocmErr, ok := err.(*ocmerrors.Error)
+		Expect(ok).To(BeTrue())
+		Expect(ocmErr.Status()).To(Equal(http.StatusForbidden))
+	})
+})
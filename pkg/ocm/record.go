@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/openshift/rosa/pkg/helper"
+)
+
+// redactedFields are the fields of the request and response bodies whose values are replaced with
+// redactedValue before saving a cassette, as they are credentials.
+var redactedFields = []string{"access_key_id", "secret_access_key", "session_token", "access_token",
+	"refresh_token", "id_token"}
+
+const redactedValue = "REDACTED"
+
+// cassetteRecorder keeps the requests to the OCM API sent by the round trippers that it wraps, with
+// their responses, so that they can be saved as a cassette that the offline mode can replay. The
+// format is the one described in cassetteInteraction:
+//
+//   - The method, the path and the query of the request, and its body if it has one.
+//   - The status of the response and its body, if it is JSON.
+//
+// The headers aren't recorded, as they contain the access token, and neither are the requests
+// outside '/api/', like the ones that refresh the token. The values of the redactedFields are
+// replaced in the bodies. The interactions are kept in the order in which the requests completed.
+type cassetteRecorder struct {
+	path         string
+	lock         sync.Mutex
+	interactions []cassetteInteraction
+}
+
+func newCassetteRecorder(path string) *cassetteRecorder {
+	return &cassetteRecorder{
+		path: path,
+	}
+}
+
+// wrap returns a round tripper that sends the requests with next and records them. The SDK wraps
+// the transports of the token and of the API requests separately, so all of them share the
+// recorder.
+func (c *cassetteRecorder) wrap(next http.RoundTripper) http.RoundTripper {
+	return &recordTransport{
+		next:     next,
+		recorder: c,
+	}
+}
+
+// recordTransport is a round tripper that records the requests that it sends in a cassette
+// recorder.
+type recordTransport struct {
+	next     http.RoundTripper
+	recorder *cassetteRecorder
+}
+
+func (t *recordTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	response, err := t.next.RoundTrip(request)
+	if err != nil || !strings.HasPrefix(request.URL.Path, "/api/") {
+		return response, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	var interaction cassetteInteraction
+	interaction.Request.Method = request.Method
+	interaction.Request.Path = request.URL.Path
+	interaction.Request.Query = request.URL.RawQuery
+	interaction.Request.Body = redactBody(requestBody)
+	interaction.Response.Status = response.StatusCode
+	interaction.Response.Body = redactBody(responseBody)
+	t.recorder.lock.Lock()
+	t.recorder.interactions = append(t.recorder.interactions, interaction)
+	t.recorder.lock.Unlock()
+	return response, nil
+}
+
+// save writes the recorded interactions to the cassette file, replacing it if it exists. The file
+// is only readable by the user, as the responses may describe the account.
+func (c *cassetteRecorder) save() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	interactions := c.interactions
+	if interactions == nil {
+		interactions = []cassetteInteraction{}
+	}
+	data, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(c.path, append(data, '\n'), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to save cassette '%s': %v", c.path, err)
+	}
+	return nil
+}
+
+// redactBody returns the body with the values of the redactedFields replaced, or nil if it is empty
+// or isn't JSON, as cassettes can only contain JSON bodies.
+func redactBody(body []byte) json.RawMessage {
+	var value interface{}
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for name, field := range typed {
+			if _, ok := field.(string); ok && helper.Contains(redactedFields, name) {
+				typed[name] = redactedValue
+			} else {
+				typed[name] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = redactValue(item)
+		}
+	}
+	return value
+}
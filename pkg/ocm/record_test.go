@@ -0,0 +1,87 @@
+package ocm
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/rosa/pkg/config"
+)
+
+var _ = Describe("Recording cassettes", func() {
+	var dir string
+	var apiServer *ghttp.Server
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "cassettes")
+		Expect(err).NotTo(HaveOccurred())
+		apiServer = MakeTCPServer()
+	})
+
+	AfterEach(func() {
+		apiServer.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("saves the requests so that the offline mode can replay them", func() {
+		apiServer.AppendHandlers(RespondWithJSON(http.StatusOK, `{
+		  "kind": "MachineTypeList",
+		  "page": 1,
+		  "size": 1,
+		  "total": 1,
+		  "items": [{"kind": "MachineType", "id": "m5.xlarge", "category": "general_purpose"}]
+		}`))
+		cassette := filepath.Join(dir, "machine-types.json")
+		client, err := NewClient().
+			Logger(logrus.New()).
+			Config(&config.Config{
+				URL:         apiServer.URL(),
+				AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+			}).
+			SaveCassette(cassette).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		machineTypes, err := client.GetMachineTypes(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge"}))
+		Expect(client.Close()).To(Succeed())
+
+		data, err := os.ReadFile(cassette)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"path": "/api/clusters_mgmt/v1/machine_types"`))
+		Expect(string(data)).NotTo(ContainSubstring("Bearer"))
+
+		replay, err := NewClient().Logger(logrus.New()).Offline(dir).Build()
+		Expect(err).NotTo(HaveOccurred())
+		defer replay.Close()
+		machineTypes, err = replay.GetMachineTypes(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge"}))
+	})
+
+	It("can't record while replaying", func() {
+		_, err := NewClient().Logger(logrus.New()).Offline(dir).SaveCassette("cassette.json").Build()
+		Expect(err).To(MatchError("Responses can't be recorded while replaying them"))
+	})
+
+	It("redacts the credentials in the bodies", func() {
+		body := redactBody([]byte(`{
+		  "aws": {"access_key_id": "AKIA", "secret_access_key": "secret", "account_id": "123"},
+		  "items": [{"access_token": "token"}]
+		}`))
+		Expect(body).To(MatchJSON(`{
+		  "aws": {"access_key_id": "REDACTED", "secret_access_key": "REDACTED", "account_id": "123"},
+		  "items": [{"access_token": "REDACTED"}]
+		}`))
+		Expect(redactBody([]byte("not JSON"))).To(BeNil())
+	})
+})
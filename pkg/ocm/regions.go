@@ -17,8 +17,10 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/rosa/pkg/aws"
@@ -27,7 +29,7 @@ import (
 )
 
 // GetFilteredRegionsByVersion fetches a list of regions. The 'version' argument is optional for filtering.
-func (c *Client) GetFilteredRegionsByVersion(roleARN string, version string,
+func (c *Client) GetFilteredRegionsByVersion(ctx context.Context, roleARN string, version string,
 	awsClient aws.Client, externalID string) (regions []*cmv1.CloudRegion, err error) {
 	cloudProviderDataBuilder, err := c.createCloudProviderDataBuilder(roleARN, awsClient, externalID)
 	if err != nil {
@@ -42,10 +44,11 @@ func (c *Client) GetFilteredRegionsByVersion(roleARN string, version string,
 		return []*cmv1.CloudRegion{}, err
 	}
 
-	return c.getFilteredRegions(cloudProviderData)
+	return c.getFilteredRegions(ctx, cloudProviderData)
 }
 
-func (c *Client) getFilteredRegions(cloudProviderData *cmv1.CloudProviderData) ([]*cmv1.CloudRegion, error) {
+func (c *Client) getFilteredRegions(ctx context.Context,
+	cloudProviderData *cmv1.CloudProviderData) ([]*cmv1.CloudRegion, error) {
 	collection := c.ocm.ClustersMgmt().V1().AWSInquiries().Regions()
 	page := 1
 	size := 100
@@ -56,9 +59,9 @@ func (c *Client) getFilteredRegions(cloudProviderData *cmv1.CloudProviderData) (
 			Body(cloudProviderData).
 			Page(page).
 			Size(size).
-			Send()
+			SendContext(ctx)
 		if err != nil {
-			return []*cmv1.CloudRegion{}, err
+			return []*cmv1.CloudRegion{}, handleErr(response.Error(), err)
 		}
 
 		cloudRegions = append(cloudRegions, response.Items().Slice()...)
@@ -142,37 +145,196 @@ func (c *Client) GetRegions(roleARN string, externalID string) (regions []*cmv1.
 	return
 }
 
-func (c *Client) GetRegionList(multiAZ bool, roleARN string,
+// regionListKey identifies a call to GetRegionList by the arguments it received.
+type regionListKey struct {
+	multiAZ             bool
+	roleARN             string
+	externalID          string
+	version             string
+	awsClient           aws.Client
+	isHostedCP          bool
+	shardPinningEnabled bool
+}
+
+type regionListEntry struct {
+	regionList   []string
+	regionAZ     map[string]bool
+	displayNames map[string]string
+	fetched      time.Time
+}
+
+// DefaultRegionCacheTTL is how long the region lists kept by the region cache are considered fresh,
+// unless changed with SetRegionCacheTTL.
+const DefaultRegionCacheTTL = 5 * time.Minute
+
+// regionCacheNow returns the current time, it is a variable so that tests can move the clock.
+var regionCacheNow = time.Now
+
+// regionCache keeps the region lists fetched from OCM during a single command invocation.
+type regionCache struct {
+	ttl               time.Duration
+	regionLists       map[regionListKey]regionListEntry
+	databaseRegions   []string
+	databaseFetched   time.Time
+	hasDatabaseRegion bool
+}
+
+// fresh checks if a region list fetched at the given time can still be used. A zero TTL means that
+// the region lists never expire.
+func (c *regionCache) fresh(fetched time.Time) bool {
+	return c.ttl == 0 || regionCacheNow().Sub(fetched) < c.ttl
+}
+
+// EnableRegionCache makes the client keep the results of GetRegionList and GetDatabaseRegionList
+// in memory, so that repeated calls with the same arguments don't require a new round-trip to
+// OCM. The cache is disabled by default, as some callers need fresh data.
+func (c *Client) EnableRegionCache() {
+	if c.regionCache == nil {
+		c.regionCache = &regionCache{
+			ttl:         DefaultRegionCacheTTL,
+			regionLists: map[regionListKey]regionListEntry{},
+		}
+	}
+}
+
+// SetRegionCacheTTL enables the region cache, if it isn't already enabled, and changes how long the
+// region lists kept in it are considered fresh. Once expired they are fetched again from OCM. A zero
+// TTL means that they never expire.
+func (c *Client) SetRegionCacheTTL(ttl time.Duration) {
+	c.EnableRegionCache()
+	c.regionCache.ttl = ttl
+}
+
+// ClearRegionCache drops any region list kept in memory by the client, so that the next calls fetch
+// them again from OCM and populate the cache with the new results.
+func (c *Client) ClearRegionCache() {
+	if c.regionCache != nil {
+		c.regionCache.regionLists = map[regionListKey]regionListEntry{}
+		c.regionCache.databaseRegions = nil
+		c.regionCache.hasDatabaseRegion = false
+	}
+}
+
+// GetRegionList returns the enabled regions available to the AWS account, and whether each of
+// them supports multiple availability zones.
+func (c *Client) GetRegionList(ctx context.Context, multiAZ bool, roleARN string,
 	externalID string, version string, awsClient aws.Client, isHostedCP bool,
 	shardPinningEnabled bool) (regionList []string,
 	regionAZ map[string]bool, err error) {
-	regions, err := c.GetFilteredRegionsByVersion(roleARN, version, awsClient, externalID)
+	entry, err := c.regionListEntry(ctx, regionListKey{
+		multiAZ:             multiAZ,
+		roleARN:             roleARN,
+		externalID:          externalID,
+		version:             version,
+		awsClient:           awsClient,
+		isHostedCP:          isHostedCP,
+		shardPinningEnabled: shardPinningEnabled,
+	})
 	if err != nil {
-		err = fmt.Errorf("Failed to retrieve AWS regions: %s", err)
 		return
 	}
+	return copyRegionList(entry.regionList), copyRegionAZ(entry.regionAZ), nil
+}
 
-	regionAZ = make(map[string]bool, len(regions))
+// GetRegionDisplayNames returns the display names of the regions returned by GetRegionList with the
+// same arguments, for example 'US East, N. Virginia' for 'us-east-1'. The regions that don't have
+// one aren't included. When the region cache is enabled the regions fetched by GetRegionList are
+// used, without a new round-trip to OCM.
+func (c *Client) GetRegionDisplayNames(ctx context.Context, multiAZ bool, roleARN string,
+	externalID string, version string, awsClient aws.Client, isHostedCP bool,
+	shardPinningEnabled bool) (map[string]string, error) {
+	entry, err := c.regionListEntry(ctx, regionListKey{
+		multiAZ:             multiAZ,
+		roleARN:             roleARN,
+		externalID:          externalID,
+		version:             version,
+		awsClient:           awsClient,
+		isHostedCP:          isHostedCP,
+		shardPinningEnabled: shardPinningEnabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	displayNames := make(map[string]string, len(entry.displayNames))
+	for region, displayName := range entry.displayNames {
+		displayNames[region] = displayName
+	}
+	return displayNames, nil
+}
+
+// regionListEntry returns the region list for the given arguments from the region cache, if it is
+// enabled and fresh, or fetches it from OCM. The result must not be modified, as it may be kept in
+// the cache.
+func (c *Client) regionListEntry(ctx context.Context, key regionListKey) (regionListEntry, error) {
+	if c.regionCache != nil {
+		if entry, ok := c.regionCache.regionLists[key]; ok && c.regionCache.fresh(entry.fetched) {
+			return entry, nil
+		}
+	}
+
+	entry, err := c.getRegionList(ctx, key)
+	if err != nil {
+		return regionListEntry{}, err
+	}
+	entry.fetched = regionCacheNow()
+
+	if c.regionCache != nil {
+		c.regionCache.regionLists[key] = entry
+	}
+	return entry, nil
+}
+
+func (c *Client) getRegionList(ctx context.Context, key regionListKey) (entry regionListEntry, err error) {
+	regions, err := c.GetFilteredRegionsByVersion(ctx, key.roleARN, key.version, key.awsClient, key.externalID)
+	if err != nil {
+		err = fmt.Errorf("Failed to retrieve AWS regions: %w", err)
+		return
+	}
+
+	entry.regionAZ = make(map[string]bool, len(regions))
+	entry.displayNames = map[string]string{}
 
 	for _, v := range regions {
 		if !v.Enabled() {
 			continue
 		}
 
-		if isHostedCP && !shardPinningEnabled && !v.SupportsHypershift() {
+		if key.isHostedCP && !key.shardPinningEnabled && !v.SupportsHypershift() {
 			continue
 		}
 
-		if !multiAZ || v.SupportsMultiAZ() {
-			regionList = append(regionList, v.ID())
+		if !key.multiAZ || v.SupportsMultiAZ() {
+			entry.regionList = append(entry.regionList, v.ID())
+			if v.DisplayName() != "" {
+				entry.displayNames[v.ID()] = v.DisplayName()
+			}
 		}
-		regionAZ[v.ID()] = v.SupportsMultiAZ()
+		entry.regionAZ[v.ID()] = v.SupportsMultiAZ()
 	}
 
 	return
 }
 
 func (c *Client) GetDatabaseRegionList() ([]string, error) {
+	if c.regionCache != nil && c.regionCache.hasDatabaseRegion &&
+		c.regionCache.fresh(c.regionCache.databaseFetched) {
+		return copyRegionList(c.regionCache.databaseRegions), nil
+	}
+
+	supportedRegions, err := c.getDatabaseRegionList()
+	if err != nil {
+		return supportedRegions, err
+	}
+
+	if c.regionCache != nil {
+		c.regionCache.databaseRegions = copyRegionList(supportedRegions)
+		c.regionCache.databaseFetched = regionCacheNow()
+		c.regionCache.hasDatabaseRegion = true
+	}
+	return supportedRegions, nil
+}
+
+func (c *Client) getDatabaseRegionList() ([]string, error) {
 	response, err := c.ocm.ClustersMgmt().V1().CloudProviders().CloudProvider("aws").Regions().List().Send()
 	if err != nil {
 		return []string{}, weberr.Errorf("Failed to get regions listing: %v", err)
@@ -184,3 +346,21 @@ func (c *Client) GetDatabaseRegionList() ([]string, error) {
 	})
 	return supportedRegions, nil
 }
+
+func copyRegionList(regionList []string) []string {
+	if regionList == nil {
+		return nil
+	}
+	return append([]string{}, regionList...)
+}
+
+func copyRegionAZ(regionAZ map[string]bool) map[string]bool {
+	if regionAZ == nil {
+		return nil
+	}
+	result := make(map[string]bool, len(regionAZ))
+	for region, supportsMultiAZ := range regionAZ {
+		result[region] = supportsMultiAZ
+	}
+	return result
+}
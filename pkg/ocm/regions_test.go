@@ -1,6 +1,8 @@
 package ocm
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,8 +12,10 @@ import (
 	"github.com/onsi/gomega/ghttp"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
 	"github.com/openshift-online/ocm-sdk-go/logging"
 	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/zgalor/weberr"
 )
 
 var _ = Describe("Regions", Ordered, func() {
@@ -144,7 +148,7 @@ var _ = Describe("Regions", Ordered, func() {
 			)
 			cloudProviderData, err := cmv1.NewCloudProviderData().Build()
 			Expect(err).ToNot(HaveOccurred())
-			regions, err := ocmClient.getFilteredRegions(cloudProviderData)
+			regions, err := ocmClient.getFilteredRegions(context.Background(), cloudProviderData)
 			Expect(err).To(BeNil())
 			Expect(regions).Should(HaveLen(4))
 			Expect(regions[0].SupportsHypershift()).To(BeFalse())
@@ -164,7 +168,7 @@ var _ = Describe("Regions", Ordered, func() {
 			)
 			cloudProviderData, err := cmv1.NewCloudProviderData().Build()
 			Expect(err).ToNot(HaveOccurred())
-			regions, err := ocmClient.getFilteredRegions(cloudProviderData)
+			regions, err := ocmClient.getFilteredRegions(context.Background(), cloudProviderData)
 			Expect(err).To(BeNil())
 			// Region has available Service Clusters
 			Expect(regions).Should(HaveLen(0))
@@ -173,11 +177,207 @@ var _ = Describe("Regions", Ordered, func() {
 			// No handler registered, we get a 500, check we handle it
 			cloudProviderData, err := cmv1.NewCloudProviderData().Build()
 			Expect(err).ToNot(HaveOccurred())
-			regions, err := ocmClient.getFilteredRegions(cloudProviderData)
+			regions, err := ocmClient.getFilteredRegions(context.Background(), cloudProviderData)
 			Expect(regions).Should(HaveLen(0))
 			Expect(err).NotTo(BeNil())
 			Expect(err.Error()).To(Equal("expected response " +
 				"content type 'application/json' but received '' and content ''"))
 		})
+		It("CS rejects the token", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusUnauthorized,
+					`{
+					  "kind": "Error",
+					  "id": "401",
+					  "code": "CLUSTERS-MGMT-401",
+					  "reason": "Invalid token"
+					}`,
+				),
+			)
+			cloudProviderData, err := cmv1.NewCloudProviderData().Build()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ocmClient.getFilteredRegions(context.Background(), cloudProviderData)
+			Expect(err).To(MatchError("Invalid token"))
+			var apiErr *APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.Status).To(Equal(http.StatusUnauthorized))
+			Expect(apiErr.Code).To(Equal("CLUSTERS-MGMT-401"))
+			var ocmErr *ocmerrors.Error
+			Expect(errors.As(err, &ocmErr)).To(BeTrue())
+			Expect(ocmErr.Status()).To(Equal(http.StatusUnauthorized))
+			Expect(weberr.GetType(err)).To(Equal(weberr.Unauthorized))
+		})
+	})
+	When("the region cache is enabled", func() {
+		const regionsResponse = `{
+		  "kind": "CloudRegionList",
+		  "page": 1,
+		  "size": 2,
+		  "total": 2,
+		  "items": [
+			{
+			  "kind": "CloudRegion",
+			  "id": "us-east-1",
+			  "display_name": "US East, N. Virginia",
+			  "enabled": true,
+			  "supports_multi_az": true
+			},
+			{
+			  "kind": "CloudRegion",
+			  "id": "us-west-1",
+			  "enabled": true,
+			  "supports_multi_az": false
+			}
+		  ]
+		}`
+		const roleARN = "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role"
+
+		It("Reuses the region list for repeated calls with the same arguments", func() {
+			ocmClient.EnableRegionCache()
+			// Only one response is registered, so a second request would get a 500:
+			apiServer.AppendHandlers(RespondWithJSON(http.StatusOK, regionsResponse))
+
+			regionList, regionAZ, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(regionList).To(Equal([]string{"us-east-1", "us-west-1"}))
+			Expect(regionAZ).To(HaveKeyWithValue("us-west-1", false))
+
+			regionList, regionAZ, err = ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(regionList).To(Equal([]string{"us-east-1", "us-west-1"}))
+			Expect(regionAZ).To(HaveKeyWithValue("us-east-1", true))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Returns the display names of the cached region list", func() {
+			ocmClient.EnableRegionCache()
+			apiServer.AppendHandlers(RespondWithJSON(http.StatusOK, regionsResponse))
+
+			_, _, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			displayNames, err := ocmClient.GetRegionDisplayNames(context.Background(), false, roleARN, "", "", nil,
+				false, false)
+			Expect(err).To(BeNil())
+			Expect(displayNames).To(Equal(map[string]string{"us-east-1": "US East, N. Virginia"}))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Fetches again for different arguments or after clearing the cache", func() {
+			ocmClient.EnableRegionCache()
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, regionsResponse),
+				RespondWithJSON(http.StatusOK, regionsResponse),
+				RespondWithJSON(http.StatusOK, regionsResponse),
+			)
+
+			_, _, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			regionList, _, err := ocmClient.GetRegionList(context.Background(), true, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(regionList).To(Equal([]string{"us-east-1"}))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+
+			ocmClient.ClearRegionCache()
+			_, _, err = ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Doesn't cache failed requests", func() {
+			ocmClient.EnableRegionCache()
+			_, _, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).NotTo(BeNil())
+
+			apiServer.AppendHandlers(RespondWithJSON(http.StatusOK, regionsResponse))
+			regionList, _, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(regionList).To(HaveLen(2))
+		})
+
+		It("Fetches again the region lists that expired", func() {
+			now := time.Now()
+			regionCacheNow = func() time.Time { return now }
+			defer func() { regionCacheNow = time.Now }()
+			ocmClient.SetRegionCacheTTL(time.Minute)
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, regionsResponse),
+				RespondWithJSON(http.StatusOK, regionsResponse),
+			)
+
+			_, _, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			now = now.Add(59 * time.Second)
+			_, _, err = ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+
+			now = now.Add(time.Second)
+			_, _, err = ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+
+			// The new response is fresh again:
+			_, _, err = ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("Expires the region lists after the default TTL", func() {
+			now := time.Now()
+			regionCacheNow = func() time.Time { return now }
+			defer func() { regionCacheNow = time.Now }()
+			ocmClient.EnableRegionCache()
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, regionsResponse),
+				RespondWithJSON(http.StatusOK, regionsResponse),
+			)
+
+			_, err := ocmClient.GetDatabaseRegionList()
+			Expect(err).To(BeNil())
+			now = now.Add(DefaultRegionCacheTTL - time.Second)
+			_, err = ocmClient.GetDatabaseRegionList()
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+
+			now = now.Add(time.Second)
+			_, err = ocmClient.GetDatabaseRegionList()
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("Never expires the region lists with a zero TTL", func() {
+			now := time.Now()
+			regionCacheNow = func() time.Time { return now }
+			defer func() { regionCacheNow = time.Now }()
+			ocmClient.SetRegionCacheTTL(0)
+			apiServer.AppendHandlers(RespondWithJSON(http.StatusOK, regionsResponse))
+
+			_, _, err := ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			now = now.Add(24 * time.Hour)
+			_, _, err = ocmClient.GetRegionList(context.Background(), false, roleARN, "", "", nil, false, false)
+			Expect(err).To(BeNil())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Repopulates the cache after clearing it", func() {
+			ocmClient.EnableRegionCache()
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, regionsResponse),
+				RespondWithJSON(http.StatusOK, regionsResponse),
+			)
+
+			_, err := ocmClient.GetDatabaseRegionList()
+			Expect(err).To(BeNil())
+			ocmClient.ClearRegionCache()
+			regionList, err := ocmClient.GetDatabaseRegionList()
+			Expect(err).To(BeNil())
+			Expect(regionList).To(Equal([]string{"us-east-1", "us-west-1"}))
+			regionList, err = ocmClient.GetDatabaseRegionList()
+			Expect(err).To(BeNil())
+			Expect(regionList).To(Equal([]string{"us-east-1", "us-west-1"}))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+		})
 	})
 })
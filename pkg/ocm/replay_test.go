@@ -0,0 +1,86 @@
+package ocm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Offline mode", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "cassettes")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	writeCassette := func(name string, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)).To(Succeed())
+	}
+
+	buildClient := func() (*Client, error) {
+		return NewClient().Logger(logrus.New()).Offline(dir).Build()
+	}
+
+	It("replays the recorded responses without logging in", func() {
+		writeCassette("machine-types.json", `[
+		  {
+		    "request": {
+		      "method": "GET",
+		      "path": "/api/clusters_mgmt/v1/machine_types",
+		      "query": "size=100&page=1"
+		    },
+		    "response": {
+		      "status": 200,
+		      "body": {
+		        "kind": "MachineTypeList",
+		        "page": 1,
+		        "size": 2,
+		        "total": 2,
+		        "items": [
+		          {"kind": "MachineType", "id": "m5.xlarge", "category": "general_purpose"},
+		          {"kind": "MachineType", "id": "r5.xlarge", "category": "memory_optimized"}
+		        ]
+		      }
+		    }
+		  }
+		]`)
+		client, err := buildClient()
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		machineTypes, err := client.GetMachineTypes(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineTypes.IDs()).To(Equal([]string{"m5.xlarge", "r5.xlarge"}))
+	})
+
+	It("fails if there is no recorded response for a request", func() {
+		writeCassette("regions.json", `[
+		  {
+		    "request": {"method": "GET", "path": "/api/clusters_mgmt/v1/cloud_providers/aws/regions"},
+		    "response": {"status": 200, "body": {"kind": "CloudRegionList", "items": []}}
+		  }
+		]`)
+		client, err := buildClient()
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		_, err = client.GetMachineTypes(context.Background())
+		Expect(err).To(MatchError(ContainSubstring(
+			"No recorded response for 'GET /api/clusters_mgmt/v1/machine_types")))
+	})
+
+	It("fails if the directory has no cassettes", func() {
+		_, err := buildClient()
+		Expect(err).To(MatchError(ContainSubstring("No cassettes found in directory")))
+	})
+})
@@ -0,0 +1,212 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+const (
+	// retryInterval is the time to wait before the first retry. It is doubled for each retry.
+	retryInterval = 1 * time.Second
+
+	// retryJitter is the factor used to randomize the retry intervals, so that clients don't all
+	// retry at the same time when the server starts failing.
+	retryJitter = 0.2
+
+	// maxRetryAfter is the longest time to wait when the server asks for more with the 'Retry-After'
+	// header, so that a wrong value doesn't make the command hang.
+	maxRetryAfter = time.Minute
+)
+
+// RetriesExhaustedError is returned when a request still fails with a transient error after all the
+// retries, to tell it apart from a request that failed once with an error that can't be retried.
+type RetriesExhaustedError struct {
+	// Attempts is the number of times that the request was sent.
+	Attempts int
+
+	// Err is the error of the last attempt. For failed responses it is the error returned by OCM,
+	// if the body contains one.
+	Err error
+}
+
+func (e *RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("Request failed after %d attempts, giving up: %v", e.Attempts, e.Err)
+}
+
+func (e *RetriesExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// retryTransport is a round tripper that retries idempotent requests that fail with a network
+// error, a 429 or a 5xx response, waiting with exponential backoff or, when the server sends it,
+// the time given in the 'Retry-After' header. Other requests are only retried on 429 and 503
+// responses, as those mean that the server didn't process them.
+type retryTransport struct {
+	next   http.RoundTripper
+	limit  int
+	debugf func(format string, args ...interface{})
+	sleep  func(context.Context, time.Duration) error
+}
+
+func newRetryWrapper(limit int,
+	debugf func(format string, args ...interface{})) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &retryTransport{
+			next:   next,
+			limit:  limit,
+			debugf: debugf,
+			sleep:  sleep,
+		}
+	}
+}
+
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	idempotent := request.Method == http.MethodGet || request.Method == http.MethodHead
+
+	// Keep a copy of the body, if any, so that it can be sent again:
+	var body []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		response, err := t.next.RoundTrip(request)
+		if !retryable(response, err, idempotent) || request.Context().Err() != nil {
+			return response, err
+		}
+		if attempt >= t.limit {
+			if t.limit == 0 {
+				return response, err
+			}
+			return nil, exhausted(attempt+1, response, err)
+		}
+
+		delay := backoff(attempt)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = response.Status
+			if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			// Drain the body so that the connection can be reused:
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		t.debugf("Request %s %s failed with '%s', retrying in %s (%d/%d)",
+			request.Method, request.URL.Path, reason, delay.Round(time.Millisecond), attempt+1, t.limit)
+		err = t.sleep(request.Context(), delay)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for the given time, or until the context is done, returning its error in that case.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// exhausted returns the error for a request that failed the given number of attempts, taking the
+// error from the last response if there is no network error.
+func exhausted(attempts int, response *http.Response, err error) error {
+	if err == nil {
+		body, readErr := io.ReadAll(response.Body)
+		response.Body.Close()
+		err = fmt.Errorf("%s", response.Status)
+		if readErr == nil {
+			ocmErr, unmarshalErr := ocmerrors.UnmarshalErrorStatus(body, response.StatusCode)
+			if unmarshalErr == nil && ocmErr.Reason() != "" {
+				err = ocmErr
+			}
+		}
+	}
+	return &RetriesExhaustedError{Attempts: attempts, Err: err}
+}
+
+// retryable returns true for network errors and for 429 and 5xx responses. Other 4xx responses
+// won't succeed when retried.
+func retryable(response *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		return idempotent
+	}
+	code := response.StatusCode
+	if code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable {
+		return true
+	}
+	return idempotent && code >= 500
+}
+
+// backoff returns the time to wait before the given retry, doubling the interval for each attempt
+// and adding or subtracting a random jitter.
+func backoff(attempt int) time.Duration {
+	interval := retryInterval * time.Duration(1<<attempt)
+	factor := retryJitter * (1 - 2*rand.Float64())
+	return interval + time.Duration(float64(interval)*factor)
+}
+
+// parseRetryAfter parses the value of a 'Retry-After' header, which can be either a number of
+// seconds or an HTTP date. The result is at most maxRetryAfter.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		if seconds > int(maxRetryAfter/time.Second) {
+			return maxRetryAfter, true
+		}
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+	return delay, true
+}
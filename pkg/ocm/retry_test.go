@@ -0,0 +1,191 @@
+package ocm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/ginkgo/v2/dsl/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+var _ = Describe("Retries", func() {
+	var server *ghttp.Server
+	var client *http.Client
+	var delays []time.Duration
+	var messages []string
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		delays = nil
+		messages = nil
+		transport := newRetryWrapper(3, func(format string, args ...interface{}) {
+			messages = append(messages, fmt.Sprintf(format, args...))
+		})(http.DefaultTransport).(*retryTransport)
+		transport.sleep = func(_ context.Context, delay time.Duration) error {
+			delays = append(delays, delay)
+			return nil
+		}
+		client = &http.Client{Transport: transport}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("retries 5xx responses until they succeed", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusBadGateway, nil),
+			ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+			ghttp.RespondWith(http.StatusOK, nil),
+		)
+		response, err := client.Get(server.URL() + "/api/clusters_mgmt/v1/cloud_providers/aws/regions")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+		Expect(delays).To(HaveLen(2))
+		Expect(delays[0]).To(BeNumerically("~", time.Second, 200*time.Millisecond))
+		Expect(delays[1]).To(BeNumerically("~", 2*time.Second, 400*time.Millisecond))
+		Expect(messages).To(HaveLen(2))
+		Expect(messages[0]).To(ContainSubstring("failed with '502 Bad Gateway'"))
+		Expect(messages[0]).To(ContainSubstring("(1/3)"))
+	})
+
+	It("honors the Retry-After header", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusTooManyRequests, nil, http.Header{"Retry-After": {"7"}}),
+			ghttp.RespondWith(http.StatusOK, nil),
+		)
+		response, err := client.Get(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(delays).To(Equal([]time.Duration{7 * time.Second}))
+	})
+
+	It("stops waiting when the context of the request is done", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusTooManyRequests, nil, http.Header{"Retry-After": {"30"}}),
+		)
+		transport := newRetryWrapper(3, func(string, ...interface{}) {})(http.DefaultTransport)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		start := time.Now()
+		_, err = transport.RoundTrip(request)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("gives up after the retry limit", func() {
+		for i := 0; i < 4; i++ {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, nil))
+		}
+		_, err := client.Get(server.URL())
+		Expect(err).To(HaveOccurred())
+		var exhausted *RetriesExhaustedError
+		Expect(errors.As(err, &exhausted)).To(BeTrue())
+		Expect(exhausted.Attempts).To(Equal(4))
+		Expect(err.Error()).To(ContainSubstring("Request failed after 4 attempts, giving up: 500 Internal Server Error"))
+		Expect(server.ReceivedRequests()).To(HaveLen(4))
+		Expect(delays).To(HaveLen(3))
+	})
+
+	It("keeps the error returned by OCM when it gives up", func() {
+		for i := 0; i < 4; i++ {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusServiceUnavailable,
+				`{"kind": "Error", "id": "503", "reason": "Service is under maintenance"}`))
+		}
+		_, err := client.Get(server.URL())
+		Expect(err).To(MatchError(ContainSubstring("Request failed after 4 attempts, giving up: ")))
+		Expect(err).To(MatchError(ContainSubstring("Service is under maintenance")))
+	})
+
+	It("doesn't retry 4xx responses", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, nil))
+		response, err := client.Get(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+		Expect(delays).To(BeEmpty())
+	})
+
+	It("retries requests that aren't idempotent only when they weren't processed", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+			ghttp.CombineHandlers(
+				ghttp.VerifyBody([]byte(`{"region":"us-east-1"}`)),
+				ghttp.RespondWith(http.StatusInternalServerError, nil),
+			),
+		)
+		response, err := client.Post(server.URL(), "application/json",
+			strings.NewReader(`{"region":"us-east-1"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	Describe("with a mock transport", func() {
+		var attempts int
+		failing := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			attempts++
+			return nil, fmt.Errorf("connection reset by peer")
+		})
+
+		BeforeEach(func() {
+			attempts = 0
+		})
+
+		It("tells how many attempts were made when the retries are exhausted", func() {
+			transport := newRetryWrapper(2, func(string, ...interface{}) {})(failing).(*retryTransport)
+			transport.sleep = func(context.Context, time.Duration) error {
+				return nil
+			}
+			request, err := http.NewRequest(http.MethodGet, "https://api.openshift.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = transport.RoundTrip(request)
+			Expect(err).To(MatchError("Request failed after 3 attempts, giving up: connection reset by peer"))
+			Expect(attempts).To(Equal(3))
+		})
+
+		It("fails on the first attempt without retries", func() {
+			failFast = true
+			defer func() {
+				failFast = false
+			}()
+			transport := newRetryWrapper(retryLimit(), func(string, ...interface{}) {})(failing).(*retryTransport)
+			request, err := http.NewRequest(http.MethodGet, "https://api.openshift.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = transport.RoundTrip(request)
+			Expect(err).To(MatchError("connection reset by peer"))
+			Expect(attempts).To(Equal(1))
+		})
+	})
+
+	DescribeTable("parseRetryAfter",
+		func(value string, expected time.Duration, ok bool) {
+			delay, parsed := parseRetryAfter(value)
+			Expect(parsed).To(Equal(ok))
+			Expect(delay).To(Equal(expected))
+		},
+		Entry("empty", "", time.Duration(0), false),
+		Entry("seconds", "30", 30*time.Second, true),
+		Entry("too many seconds", "86400", maxRetryAfter, true),
+		Entry("date in the past", "Wed, 21 Oct 2015 07:28:00 GMT", time.Duration(0), true),
+		Entry("date too far in the future", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			maxRetryAfter, true),
+		Entry("invalid", "soon", time.Duration(0), false),
+	)
+})
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to describe the fields of the JSON output.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Field is a field of the JSON representation of a resource. The path uses the syntax of 'jq', for
+// example 'cpu.value' or 'availability_zones[]'.
+type Field struct {
+	Path string
+	Type string
+}
+
+// Fields returns the fields of the given JSON document, sorted by path. When the document is a list
+// the fields of its first item are returned, and the items of the arrays inside it are described by
+// their first item too.
+func Fields(body []byte) ([]Field, error) {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := data.([]interface{}); ok {
+		if len(items) == 0 {
+			return nil, fmt.Errorf("Can't describe the fields of an empty list")
+		}
+		data = items[0]
+	}
+	var fields []Field
+	collectFields(&fields, "", data)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return fields, nil
+}
+
+func collectFields(fields *[]Field, path string, value interface{}) {
+	if path != "" {
+		*fields = append(*fields, Field{Path: path, Type: jsonType(value)})
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		prefix := ""
+		if path != "" {
+			prefix = path + "."
+		}
+		for key, item := range value {
+			collectFields(fields, prefix+key, item)
+		}
+	case []interface{}:
+		if len(value) > 0 {
+			collectFields(fields, path+"[]", value[0])
+		}
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// PrintFields writes the fields to w as a table with their paths and types.
+func PrintFields(w io.Writer, fields []Field) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "FIELD\tTYPE\n")
+	for _, field := range fields {
+		fmt.Fprintf(writer, "%s\t%s\n", field.Path, field.Type)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fields", func() {
+	It("Describes nested objects and the items of arrays", func() {
+		fields, err := Fields([]byte(`[{"id": "m5.xlarge", "cpu": {"value": 4}, "zones": ["us-east-1a"],
+			"ccs_only": true, "quota": null}]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(Equal([]Field{
+			{Path: "ccs_only", Type: "boolean"},
+			{Path: "cpu", Type: "object"},
+			{Path: "cpu.value", Type: "number"},
+			{Path: "id", Type: "string"},
+			{Path: "quota", Type: "null"},
+			{Path: "zones", Type: "array"},
+			{Path: "zones[]", Type: "string"},
+		}))
+	})
+
+	It("Fails for an empty list", func() {
+		_, err := Fields([]byte(`[]`))
+		Expect(err).To(MatchError("Can't describe the fields of an empty list"))
+	})
+
+	It("Prints the fields as a table", func() {
+		var b bytes.Buffer
+		Expect(PrintFields(&b, []Field{{Path: "id", Type: "string"}, {Path: "cpu.value", Type: "number"}})).
+			To(Succeed())
+		Expect(b.String()).To(Equal("FIELD      TYPE\nid         string\ncpu.value  number\n"))
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--output-file' command line option.
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var outputFile string
+
+var fileFormat string
+
+// AddOutputFileFlag adds the '--output-file' flag to the given command, to write the results to a
+// file instead of the standard output, which keeps the warnings and debug messages out of it.
+func AddOutputFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&outputFile,
+		"output-file",
+		"",
+		"Write the results to this file instead of the standard output. Messages are still printed "+
+			"to the terminal.",
+	)
+}
+
+// AddFileFormatFlag adds the '--format' flag to the given command. With it the results are written
+// to the '--output-file' in that format, and the standard output keeps the format selected with the
+// '--output' flag, the table by default, so both are produced in one run.
+func AddFileFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().Var(
+		(*formatValue)(&fileFormat),
+		"format",
+		fmt.Sprintf("Format of the results written to the '--output-file', while the standard output keeps "+
+			"the format of the '--output' flag. Allowed formats are %s", formats),
+	)
+
+	cmd.RegisterFlagCompletionFunc("format", completion)
+}
+
+// FileFormat returns the format given with the '--format' flag, or an empty string if the results
+// are written to the '--output-file' in the same format as the standard output.
+func FileFormat() string {
+	return fileFormat
+}
+
+// ValidateFileFormat returns an error if the '--format' flag is given without a file to write to.
+func ValidateFileFormat() error {
+	if fileFormat != "" && outputFile == "" {
+		return fmt.Errorf("The '--format' flag can only be used together with '--output-file'")
+	}
+	return nil
+}
+
+// OpenFile creates the file given with the '--output-file' flag and returns it, so that the results
+// are written to it instead of w. Without the flag it returns w. The returned function closes the file,
+// it must be called even if no file was given. With the '--format' flag the file is written by WriteFile
+// instead, so w is returned.
+func OpenFile(w io.Writer) (io.Writer, func() error, error) {
+	if outputFile == "" || fileFormat != "" {
+		return w, func() error { return nil }, nil
+	}
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create output file: %v", err)
+	}
+	return file, func() error {
+		err := file.Close()
+		if err != nil {
+			return fmt.Errorf("Failed to write output file '%s': %v", outputFile, err)
+		}
+		return nil
+	}, nil
+}
+
+// WriteFile writes the resource to the file given with the '--output-file' flag, in the format given
+// with the '--format' flag. It does nothing without the '--format' flag, as then the results are
+// written to the file returned by OpenFile.
+func WriteFile(resource interface{}) error {
+	if fileFormat == "" {
+		return nil
+	}
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("Failed to create output file: %v", err)
+	}
+	err = PrintFormat(file, fileFormat, resource)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return fmt.Errorf("Failed to write output file '%s': %v", outputFile, closeErr)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Output file", func() {
+	AfterEach(func() {
+		o = ""
+		outputFile = ""
+		fileFormat = ""
+	})
+
+	It("Writes to the given writer without the flag", func() {
+		var b bytes.Buffer
+		w, closeOutput, err := OpenFile(&b)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(BeIdenticalTo(&b))
+		Expect(closeOutput()).To(Succeed())
+	})
+
+	It("Prints the resources to the file until it is closed", func() {
+		outputFile = filepath.Join(GinkgoT().TempDir(), "machine-types.json")
+		var b bytes.Buffer
+		w, closeOutput, err := OpenFile(&b)
+		Expect(err).NotTo(HaveOccurred())
+
+		machineType, err := cmv1.NewMachineType().ID("m5.xlarge").Build()
+		Expect(err).NotTo(HaveOccurred())
+		o = "json"
+		Expect(Print(w, []*cmv1.MachineType{machineType})).To(Succeed())
+		Expect(closeOutput()).To(Succeed())
+		Expect(b.Len()).To(BeZero())
+
+		content, err := os.ReadFile(outputFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring(`"id": "m5.xlarge"`))
+	})
+
+	It("Fails if the file can't be created", func() {
+		outputFile = filepath.Join(GinkgoT().TempDir(), "missing", "machine-types.json")
+		_, _, err := OpenFile(os.Stdout)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("Failed to create output file: "))
+	})
+
+	Context("With a file format", func() {
+		var machineType *cmv1.MachineType
+
+		BeforeEach(func() {
+			var err error
+			machineType, err = cmv1.NewMachineType().ID("m5.xlarge").Build()
+			Expect(err).NotTo(HaveOccurred())
+			outputFile = filepath.Join(GinkgoT().TempDir(), "machine-types.yaml")
+			fileFormat = "yaml"
+		})
+
+		It("Keeps the standard output and writes the file in its own format", func() {
+			var b bytes.Buffer
+			w, closeOutput, err := OpenFile(&b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w).To(BeIdenticalTo(&b))
+
+			o = "json"
+			Expect(Print(w, []*cmv1.MachineType{machineType})).To(Succeed())
+			Expect(WriteFile([]*cmv1.MachineType{machineType})).To(Succeed())
+			Expect(closeOutput()).To(Succeed())
+			Expect(b.String()).To(ContainSubstring(`"id": "m5.xlarge"`))
+
+			content, err := os.ReadFile(outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("- id: m5.xlarge"))
+		})
+
+		It("Requires the output file", func() {
+			Expect(ValidateFileFormat()).To(Succeed())
+			outputFile = ""
+			err := ValidateFileFormat()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("The '--format' flag can only be used together with '--output-file'"))
+		})
+
+		It("Doesn't write the file without the format", func() {
+			fileFormat = ""
+			Expect(WriteFile([]*cmv1.MachineType{machineType})).To(Succeed())
+			Expect(outputFile).NotTo(BeAnExistingFile())
+		})
+	})
+})
@@ -22,34 +22,119 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/openshift/rosa/pkg/helper"
 )
 
 var o string
 
+var compact bool
+
 var formats = []string{"json", "yaml"}
 
+// CSV is the format for the commands that print tables and can also print them as comma separated
+// values. It isn't in the list of formats because most commands don't support it.
+const CSV = "csv"
+
+// formatValue implements the pflag.Value interface so that unsupported output formats are
+// rejected when the command line is parsed, instead of after the resources have been fetched.
+type formatValue string
+
+func (f *formatValue) String() string {
+	return string(*f)
+}
+
+func (f *formatValue) Set(value string) error {
+	if value != "" && !helper.Contains(formats, value) {
+		return fmt.Errorf("Unknown format '%s'. Valid formats are %s", value, formats)
+	}
+	*f = formatValue(value)
+	return nil
+}
+
+func (f *formatValue) Type() string {
+	return "string"
+}
+
+// csvFormatValue is like formatValue, but it also accepts the CSV format.
+type csvFormatValue string
+
+func (f *csvFormatValue) String() string {
+	return string(*f)
+}
+
+func (f *csvFormatValue) Set(value string) error {
+	if value != "" && value != CSV && !helper.Contains(formats, value) {
+		return fmt.Errorf("Unknown format '%s'. Valid formats are %s", value, csvFormats())
+	}
+	*f = csvFormatValue(value)
+	return nil
+}
+
+func (f *csvFormatValue) Type() string {
+	return "string"
+}
+
+func csvFormats() []string {
+	return append(append([]string{}, formats...), CSV)
+}
+
 // AddFlag adds the interactive flag to the given set of command line flags.
 func AddFlag(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(
-		&o,
+	cmd.Flags().VarP(
+		(*formatValue)(&o),
 		"output",
 		"o",
-		"",
 		fmt.Sprintf("Output format. Allowed formats are %s", formats),
 	)
 
 	cmd.RegisterFlagCompletionFunc("output", completion)
 }
 
+// AddFlagWithCSV adds the output flag to the given command, accepting also the CSV format.
+func AddFlagWithCSV(cmd *cobra.Command) {
+	cmd.Flags().VarP(
+		(*csvFormatValue)(&o),
+		"output",
+		"o",
+		fmt.Sprintf("Output format. Allowed formats are %s", csvFormats()),
+	)
+
+	cmd.RegisterFlagCompletionFunc("output", csvCompletion)
+}
+
+// AddCompactFlag adds the '--compact' flag to the given command, to print the JSON output in a
+// single line instead of indented.
+func AddCompactFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(
+		&compact,
+		"compact",
+		false,
+		"Print the JSON output in a single line instead of indented. Only used with '-o json'.",
+	)
+}
+
+func csvCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return csvFormats(), cobra.ShellCompDirectiveDefault
+}
+
 func completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return formats, cobra.ShellCompDirectiveDefault
 }
 
+// Compact returns true if the JSON output is printed in a single line.
+func Compact() bool {
+	return compact
+}
+
 func HasFlag() bool {
-	return o != ""
+	return o != "" || hasTemplate()
 }
 
 // Enabled retursn a boolean flag that indicates if the interactive mode is enabled.
 func Output() string {
+	if o == "" && hasTemplate() {
+		return Template
+	}
 	return o
 }
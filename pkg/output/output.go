@@ -20,6 +20,7 @@ package output
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,7 +38,17 @@ import (
 // that the output can be shown correctly.
 var emptyBuffer = []byte{91, 10, 32, 32, 10, 93}
 
-func Print(resource interface{}) error {
+func Print(w io.Writer, resource interface{}) error {
+	format := o
+	if hasTemplate() {
+		format = Template
+	}
+	return PrintFormat(w, format, resource)
+}
+
+// PrintFormat is like Print, but it uses the given format instead of the one of the '--output' and
+// template flags, so that the same resource can be written to several places in different formats.
+func PrintFormat(w io.Writer, format string, resource interface{}) error {
 	var b bytes.Buffer
 	switch reflect.TypeOf(resource).String() {
 	case "[]*v1.CloudRegion":
@@ -104,17 +115,18 @@ func Print(resource interface{}) error {
 	case "map[string][]aws.Role":
 		{
 			for _, operatorRoles := range resource.(map[string][]aws.Role) {
-				err := Print(operatorRoles)
+				err := PrintFormat(w, format, operatorRoles)
 				if err != nil {
 					return err
 				}
 			}
 		}
-	case "object.Object", "map[string]interface {}":
+	case "object.Object", "map[string]interface {}", "[]map[string]interface {}", "[]*ordered.OrderedMap":
 		{
 			reqBodyBytes := new(bytes.Buffer)
 			json.NewEncoder(reqBodyBytes).Encode(resource)
-			err := json.Indent(&b, reqBodyBytes.Bytes(), "", "  ")
+			// The encoder ends the value with a line break, which would be printed twice for lists:
+			err := json.Indent(&b, bytes.TrimSpace(reqBodyBytes.Bytes()), "", "  ")
 			if err != nil {
 				return err
 			}
@@ -125,16 +137,31 @@ func Print(resource interface{}) error {
 	if b.String() == string(emptyBuffer) {
 		b = *bytes.NewBufferString("[]")
 	}
-	str, err := parseResource(b)
+	str, err := parseResource(b, format)
 	if err != nil {
 		return err
 	}
-	fmt.Print(str)
-	return nil
+	_, err = fmt.Fprint(w, str)
+	return err
 }
 
-func parseResource(body bytes.Buffer) (string, error) {
-	switch o {
+// PrintCSV writes the header, unless it is nil, and the records to w as comma separated values,
+// quoting the fields that contain commas, quotes or line breaks.
+func PrintCSV(w io.Writer, header []string, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if header != nil {
+		err := writer.Write(header)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.WriteAll(records)
+}
+
+func parseResource(body bytes.Buffer, format string) (string, error) {
+	switch format {
+	case Template:
+		return renderTemplate(body.Bytes())
 	case "json":
 		var out bytes.Buffer
 		prettifyJSON(&out, body.Bytes())
@@ -146,7 +173,7 @@ func parseResource(body bytes.Buffer) (string, error) {
 		}
 		return string(out), nil
 	default:
-		return "", fmt.Errorf("Unknown format '%s'. Valid formats are %s", o, formats)
+		return "", fmt.Errorf("Unknown format '%s'. Valid formats are %s", format, formats)
 	}
 }
 
@@ -154,6 +181,14 @@ func prettifyJSON(stream io.Writer, body []byte) error {
 	if len(body) == 0 {
 		return nil
 	}
+	if compact {
+		var out bytes.Buffer
+		err := json.Compact(&out, body)
+		if err != nil {
+			return dumpBytes(stream, body)
+		}
+		return dumpBytes(stream, out.Bytes())
+	}
 	data := ordered.NewOrderedMap()
 	err := json.Unmarshal(body, data)
 	if err != nil {
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Output", func() {
+	AfterEach(func() {
+		o = ""
+		compact = false
+	})
+
+	Context("formatValue", func() {
+		It("Accepts the supported formats", func() {
+			for _, format := range formats {
+				Expect((*formatValue)(&o).Set(format)).To(Succeed())
+				Expect(HasFlag()).To(BeTrue())
+				Expect(Output()).To(Equal(format))
+			}
+		})
+
+		It("Rejects unsupported formats listing the valid ones", func() {
+			err := (*formatValue)(&o).Set("xml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Unknown format 'xml'. Valid formats are [json yaml]"))
+			Expect(HasFlag()).To(BeFalse())
+		})
+	})
+
+	Context("csvFormatValue", func() {
+		It("Accepts the CSV format besides the supported ones", func() {
+			for _, format := range []string{"json", "yaml", "csv"} {
+				Expect((*csvFormatValue)(&o).Set(format)).To(Succeed())
+				Expect(Output()).To(Equal(format))
+			}
+		})
+
+		It("Rejects unsupported formats listing the valid ones", func() {
+			err := (*csvFormatValue)(&o).Set("xml")
+			Expect(err).To(MatchError("Unknown format 'xml'. Valid formats are [json yaml csv]"))
+		})
+
+		It("Isn't accepted by the commands that don't support it", func() {
+			Expect((*formatValue)(&o).Set(CSV)).NotTo(Succeed())
+		})
+	})
+
+	Context("PrintCSV", func() {
+		It("Writes the header and quotes the fields that need it", func() {
+			var b bytes.Buffer
+			err := PrintCSV(&b, []string{"ID", "NAME", "MEMORY_BYTES"}, [][]string{
+				{"m5.xlarge", "m5.xlarge - General Purpose", "17179869184"},
+				{"x1.custom", "Custom, \"large\"", "1024"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b.String()).To(Equal("" +
+				"ID,NAME,MEMORY_BYTES\n" +
+				"m5.xlarge,m5.xlarge - General Purpose,17179869184\n" +
+				"x1.custom,\"Custom, \"\"large\"\"\",1024\n"))
+		})
+
+		It("Writes only the header when there are no records", func() {
+			var b bytes.Buffer
+			Expect(PrintCSV(&b, []string{"ID"}, nil)).To(Succeed())
+			Expect(b.String()).To(Equal("ID\n"))
+		})
+	})
+
+	Context("parseResource", func() {
+		It("Indents JSON by default", func() {
+			out, err := parseResource(*bytes.NewBufferString(`{"id":"m5.xlarge","cpu":{"value":4}}`), "json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("{\n  \"id\": \"m5.xlarge\",\n  \"cpu\": {\n    \"value\": 4\n  }\n}\n"))
+		})
+
+		It("Prints compact JSON in a single line keeping the order of the fields", func() {
+			compact = true
+			out, err := parseResource(*bytes.NewBufferString("{\n  \"id\": \"m5.xlarge\",\n  \"cpu\": 4\n}"), "json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(`{"id":"m5.xlarge","cpu":4}` + "\n"))
+		})
+
+		It("Renders machine types as YAML", func() {
+			machineType, err := cmv1.NewMachineType().
+				ID("m5.xlarge").
+				Name("m5.xlarge - General Purpose").
+				Category(cmv1.MachineTypeCategoryGeneralPurpose).
+				CPU(cmv1.NewValue().Value(4).Unit("vCPU")).
+				Memory(cmv1.NewValue().Value(17179869184).Unit("B")).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			var b bytes.Buffer
+			Expect(cmv1.MarshalMachineTypeList([]*cmv1.MachineType{machineType}, &b)).To(Succeed())
+
+			out, err := parseResource(b, "yaml")
+			Expect(err).NotTo(HaveOccurred())
+
+			var items []map[string]interface{}
+			Expect(yaml.Unmarshal([]byte(out), &items)).To(Succeed())
+			Expect(items).To(HaveLen(1))
+			Expect(items[0]).To(HaveKeyWithValue("id", "m5.xlarge"))
+			Expect(items[0]).To(HaveKeyWithValue("category", "general_purpose"))
+			Expect(items[0]).To(HaveKey("cpu"))
+			Expect(items[0]).To(HaveKey("memory"))
+			Expect(items[0]["memory"]).To(HaveKeyWithValue("unit", "B"))
+		})
+	})
+})
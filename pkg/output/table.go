@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to print resources as tables, and to implement
+// the '--padding' and '--min-column-width' command line options.
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultPadding is the number of spaces added after the widest cell of each column of a table.
+const DefaultPadding = 2
+
+var padding = widthValue(DefaultPadding)
+
+var minColumnWidth widthValue
+
+// widthValue implements the pflag.Value interface so that negative widths are rejected when the
+// command line is parsed.
+type widthValue int
+
+func (v *widthValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+func (v *widthValue) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("Invalid width '%s'. It must be zero or greater", value)
+	}
+	*v = widthValue(n)
+	return nil
+}
+
+func (v *widthValue) Type() string {
+	return "int"
+}
+
+// AddTableFlags adds the '--padding' and '--min-column-width' flags to the given command, to change
+// the layout of the tables created with NewTableWriter.
+func AddTableFlags(cmd *cobra.Command) {
+	cmd.Flags().Var(
+		&padding,
+		"padding",
+		"Number of spaces between the columns of the table.",
+	)
+	cmd.Flags().Var(
+		&minColumnWidth,
+		"min-column-width",
+		"Minimum width of the columns of the table, including the padding.",
+	)
+}
+
+// NewTableWriter returns a writer that aligns the cells separated by tabs in columns, with the
+// padding and the minimum column width given in the command line. Unless the command has the flags,
+// it uses two spaces of padding and no minimum width.
+func NewTableWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, int(minColumnWidth), 0, int(padding), ' ', 0)
+}
+
+// Column describes one of the columns of a table. The value function receives each of the items of
+// the table. Columns whose value is meant for humans can have a different header and value in CSV
+// format.
+type Column struct {
+	Name      string
+	Header    string
+	Value     func(item interface{}) string
+	CSVHeader string
+	CSVValue  func(item interface{}) string
+}
+
+// ColumnNames returns the names of the given columns.
+func ColumnNames(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// SelectColumns returns the columns matching the given names, in the given order, for example the
+// values of a '--columns' flag. Names are matched ignoring case and surrounding spaces.
+func SelectColumns(columns []Column, names []string) ([]Column, error) {
+	var selected []Column
+	for _, name := range names {
+		found := false
+		for _, c := range columns {
+			if c.Name == strings.ToLower(strings.TrimSpace(name)) {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Invalid column '%s'. Valid columns are %s", name, ColumnNames(columns))
+		}
+	}
+	return selected, nil
+}
+
+// Table prints a slice of items with the given columns, as a table aligned with NewTableWriter or
+// as comma separated values. The header row is omitted when NoHeaders is set.
+type Table struct {
+	Columns   []Column
+	NoHeaders bool
+}
+
+// HeaderRow returns the headers of the columns separated by tabs, to be written to a table writer.
+func (t *Table) HeaderRow() string {
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Header
+	}
+	return strings.Join(headers, "\t") + "\t\n"
+}
+
+// Row returns the values of the columns for the item separated by tabs, to be written to a table
+// writer.
+func (t *Table) Row(item interface{}) string {
+	values := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		values[i] = c.Value(item)
+	}
+	return strings.Join(values, "\t") + "\n"
+}
+
+// CSVHeader returns the headers of the columns in CSV format.
+func (t *Table) CSVHeader() []string {
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Header
+		if c.CSVHeader != "" {
+			headers[i] = c.CSVHeader
+		}
+	}
+	return headers
+}
+
+// CSVRecord returns the values of the columns for the item in CSV format.
+func (t *Table) CSVRecord(item interface{}) []string {
+	values := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		if c.CSVValue != nil {
+			values[i] = c.CSVValue(item)
+		} else {
+			values[i] = c.Value(item)
+		}
+	}
+	return values
+}
+
+// Write writes the items, which must be a slice, to w as an aligned table.
+func (t *Table) Write(w io.Writer, items interface{}) error {
+	writer := NewTableWriter(w)
+	if !t.NoHeaders {
+		fmt.Fprint(writer, t.HeaderRow())
+	}
+	err := eachItem(items, func(item interface{}) {
+		fmt.Fprint(writer, t.Row(item))
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// WriteCSV writes the items, which must be a slice, to w as comma separated values.
+func (t *Table) WriteCSV(w io.Writer, items interface{}) error {
+	var header []string
+	if !t.NoHeaders {
+		header = t.CSVHeader()
+	}
+	var records [][]string
+	err := eachItem(items, func(item interface{}) {
+		records = append(records, t.CSVRecord(item))
+	})
+	if err != nil {
+		return err
+	}
+	return PrintCSV(w, header, records)
+}
+
+func eachItem(items interface{}, fn func(item interface{})) error {
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("Expected a slice of items, but got '%T'", items)
+	}
+	for i := 0; i < value.Len(); i++ {
+		fn(value.Index(i).Interface())
+	}
+	return nil
+}
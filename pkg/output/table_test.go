@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+type fruit struct {
+	name  string
+	grams int
+}
+
+var fruitColumns = []Column{
+	{
+		Name:   "name",
+		Header: "NAME",
+		Value: func(item interface{}) string {
+			return item.(fruit).name
+		},
+	},
+	{
+		Name:   "weight",
+		Header: "WEIGHT",
+		Value: func(item interface{}) string {
+			return fmt.Sprintf("%d g", item.(fruit).grams)
+		},
+		CSVHeader: "GRAMS",
+		CSVValue: func(item interface{}) string {
+			return fmt.Sprintf("%d", item.(fruit).grams)
+		},
+	},
+}
+
+var fruits = []fruit{{name: "apple", grams: 180}, {name: "watermelon", grams: 5000}}
+
+var _ = Describe("Table renderer", func() {
+	It("writes the header and the aligned rows", func() {
+		var b bytes.Buffer
+		table := &Table{Columns: fruitColumns}
+		Expect(table.Write(&b, fruits)).To(Succeed())
+		Expect(b.String()).To(Equal("" +
+			"NAME        WEIGHT  \n" +
+			"apple       180 g\n" +
+			"watermelon  5000 g\n"))
+	})
+
+	It("omits the header when asked to", func() {
+		var b bytes.Buffer
+		table := &Table{Columns: fruitColumns, NoHeaders: true}
+		Expect(table.Write(&b, fruits)).To(Succeed())
+		Expect(b.String()).To(Equal("apple       180 g\nwatermelon  5000 g\n"))
+	})
+
+	It("uses the CSV headers and values when they are different", func() {
+		var b bytes.Buffer
+		table := &Table{Columns: fruitColumns}
+		Expect(table.WriteCSV(&b, fruits)).To(Succeed())
+		Expect(b.String()).To(Equal("NAME,GRAMS\napple,180\nwatermelon,5000\n"))
+	})
+
+	It("selects the columns by name, in the given order", func() {
+		selected, err := SelectColumns(fruitColumns, []string{" Weight", "name"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ColumnNames(selected)).To(Equal([]string{"weight", "name"}))
+
+		_, err = SelectColumns(fruitColumns, []string{"color"})
+		Expect(err).To(MatchError("Invalid column 'color'. Valid columns are [name weight]"))
+	})
+
+	It("rejects items that aren't a slice", func() {
+		var b bytes.Buffer
+		table := &Table{Columns: fruitColumns}
+		Expect(table.Write(&b, fruits[0])).To(MatchError("Expected a slice of items, but got 'output.fruit'"))
+	})
+})
+
+var _ = Describe("Table", func() {
+	write := func() string {
+		var b bytes.Buffer
+		writer := NewTableWriter(&b)
+		fmt.Fprint(writer, "ID\tCPU\n")
+		fmt.Fprint(writer, "m5.xlarge\t4\n")
+		Expect(writer.Flush()).To(Succeed())
+		return b.String()
+	}
+
+	AfterEach(func() {
+		padding = DefaultPadding
+		minColumnWidth = 0
+	})
+
+	It("uses two spaces of padding by default", func() {
+		Expect(write()).To(Equal("ID         CPU\nm5.xlarge  4\n"))
+	})
+
+	It("uses the padding and the minimum column width of the flags", func() {
+		cmd := &cobra.Command{}
+		AddTableFlags(cmd)
+		Expect(cmd.Flags().Parse([]string{"--padding", "1", "--min-column-width", "12"})).To(Succeed())
+		Expect(write()).To(Equal("ID          CPU\nm5.xlarge   4\n"))
+	})
+
+	It("rejects negative widths", func() {
+		cmd := &cobra.Command{}
+		AddTableFlags(cmd)
+		Expect(cmd.Flags().Parse([]string{"--padding", "-1"})).To(MatchError(
+			ContainSubstring("Invalid width '-1'. It must be zero or greater")))
+	})
+})
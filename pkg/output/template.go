@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--template' and '--template-file' command line
+// options.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// Template is the value returned by Output when the resources are rendered with a Go template.
+const Template = "template"
+
+var templateText string
+
+var templateFile string
+
+// AddTemplateFlags adds the '--template' and '--template-file' flags to the given command, to render
+// each resource printed with Print through a Go template instead of printing it as JSON or YAML.
+func AddTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&templateText,
+		"template",
+		"",
+		"Render each resource with this Go template instead of printing it as JSON or YAML. Fields "+
+			"have the names of the JSON output, for example '{{.id}} {{.cpu.value}}'.",
+	)
+	cmd.Flags().StringVar(
+		&templateFile,
+		"template-file",
+		"",
+		"Like '--template', but reading the template from this file.",
+	)
+}
+
+func hasTemplate() bool {
+	return templateText != "" || templateFile != ""
+}
+
+// parseTemplate returns the template given with the '--template' or the '--template-file' flag.
+// Fields that don't exist are reported as errors, instead of being rendered as '<no value>'.
+func parseTemplate() (*template.Template, error) {
+	if templateText != "" && templateFile != "" {
+		return nil, fmt.Errorf("The '--template' and '--template-file' flags can't be used together")
+	}
+	if o != "" {
+		return nil, fmt.Errorf("The '--output' flag can't be used together with a template")
+	}
+	name := Template
+	text := templateText
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read template file: %v", err)
+		}
+		name = filepath.Base(templateFile)
+		text = string(data)
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplate renders the JSON representation of the resources through the template. When it is
+// a list each item is rendered separately, ending with a line break if the template doesn't add one.
+// Errors name the line of the template and the field that failed.
+func renderTemplate(body []byte) (string, error) {
+	tmpl, err := parseTemplate()
+	if err != nil {
+		return "", err
+	}
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err = decoder.Decode(&data)
+	if err != nil {
+		return "", err
+	}
+	items, ok := data.([]interface{})
+	if !ok {
+		items = []interface{}{data}
+	}
+	var out bytes.Buffer
+	for _, item := range items {
+		var b bytes.Buffer
+		err = tmpl.Execute(&b, item)
+		if err != nil {
+			return "", fmt.Errorf("Failed to render template: %v", err)
+		}
+		if b.Len() > 0 && !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteString("\n")
+		}
+		out.Write(b.Bytes())
+	}
+	return out.String(), nil
+}
+
+// ValidateTemplate checks the template given with the '--template' or the '--template-file' flag, if
+// any, so that a mistake in it is reported before fetching the resources.
+func ValidateTemplate() error {
+	if !hasTemplate() {
+		return nil
+	}
+	_, err := parseTemplate()
+	return err
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Template", func() {
+	var body []byte
+
+	BeforeEach(func() {
+		var machineTypes []*cmv1.MachineType
+		for _, builder := range []*cmv1.MachineTypeBuilder{
+			cmv1.NewMachineType().ID("m5.xlarge").Category(cmv1.MachineTypeCategoryGeneralPurpose).
+				CPU(cmv1.NewValue().Value(4).Unit("vCPU")).
+				Memory(cmv1.NewValue().Value(17179869184).Unit("B")),
+			cmv1.NewMachineType().ID("r5.2xlarge").Category(cmv1.MachineTypeCategoryMemoryOptimized).
+				CPU(cmv1.NewValue().Value(8).Unit("vCPU")).
+				Memory(cmv1.NewValue().Value(68719476736).Unit("B")),
+		} {
+			machineType, err := builder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			machineTypes = append(machineTypes, machineType)
+		}
+		var b bytes.Buffer
+		Expect(cmv1.MarshalMachineTypeList(machineTypes, &b)).To(Succeed())
+		body = b.Bytes()
+	})
+
+	AfterEach(func() {
+		o = ""
+		templateText = ""
+		templateFile = ""
+	})
+
+	It("renders each machine type, adding the missing line breaks", func() {
+		templateText = "{{.id}}: {{.cpu.value}} CPUs, {{.memory.value}} bytes, {{.category}}"
+		Expect(HasFlag()).To(BeTrue())
+		Expect(Output()).To(Equal(Template))
+		out, err := renderTemplate(body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("m5.xlarge: 4 CPUs, 17179869184 bytes, general_purpose\n" +
+			"r5.2xlarge: 8 CPUs, 68719476736 bytes, memory_optimized\n"))
+	})
+
+	It("reads the template from a file", func() {
+		templateFile = filepath.Join(GinkgoT().TempDir(), "ids.tmpl")
+		Expect(os.WriteFile(templateFile, []byte("{{.id}}\n"), 0600)).To(Succeed())
+		out, err := renderTemplate(body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("m5.xlarge\nr5.2xlarge\n"))
+	})
+
+	It("reports the line of a syntax error", func() {
+		templateText = "{{.id}}\n{{.cpu.value"
+		err := ValidateTemplate()
+		Expect(err).To(MatchError(ContainSubstring("template:2:")))
+	})
+
+	It("reports the field that doesn't exist", func() {
+		templateText = "{{.id}} {{.price}}"
+		_, err := renderTemplate(body)
+		Expect(err).To(MatchError(ContainSubstring("template:1:10")))
+		Expect(err).To(MatchError(ContainSubstring("<.price>")))
+	})
+
+	It("can't be used together with an output format", func() {
+		templateText = "{{.id}}"
+		o = "json"
+		Expect(ValidateTemplate()).To(MatchError("The '--output' flag can't be used together with a template"))
+	})
+})
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--log-format' command line option.
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/rosa/pkg/color"
+)
+
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+)
+
+var logFormat = textFormat
+
+var logFormats = []string{textFormat, jsonFormat}
+
+// command is the name of the command being run, added to the messages in JSON format.
+var command string
+
+// now returns the time added to the messages in JSON format. Tests replace it to get stable output.
+var now = time.Now
+
+// AddLogFormatFlag adds the '--log-format' flag to the given command and its sub-commands.
+func AddLogFormatFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(
+		&logFormat,
+		"log-format",
+		textFormat,
+		fmt.Sprintf("Format of the informative, warning and error messages. With 'json' each message "+
+			"is printed as a JSON object in its own line. Allowed values are %s", logFormats),
+	)
+
+	cmd.RegisterFlagCompletionFunc("log-format", logFormatCompletion)
+}
+
+func logFormatCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string,
+	cobra.ShellCompDirective) {
+	return logFormats, cobra.ShellCompDirectiveDefault
+}
+
+// ConfigureLogFormat checks the value of the '--log-format' flag and sets the name of the command
+// added to the messages in JSON format.
+func ConfigureLogFormat(commandPath string) error {
+	for _, format := range logFormats {
+		if logFormat == format {
+			command = commandPath
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid log format '%s'. Allowed values are %s", logFormat, logFormats)
+}
+
+// logLine is a message in JSON format.
+type logLine struct {
+	Level   string `json:"level"`
+	Msg     string `json:"msg"`
+	Time    string `json:"time"`
+	Command string `json:"command,omitempty"`
+}
+
+// formatLine returns the message with the given level as a complete line, using the selected log
+// format. In text format the message is preceded by either the colored or the plain prefix.
+func formatLine(level string, colorPrefix string, plainPrefix string, message string) string {
+	if logFormat == jsonFormat {
+		line, err := json.Marshal(logLine{
+			Level:   level,
+			Msg:     message,
+			Time:    now().UTC().Format(time.RFC3339),
+			Command: command,
+		})
+		if err == nil {
+			return string(line) + "\n"
+		}
+	}
+	if color.UseColor() {
+		return colorPrefix + message + "\n"
+	}
+	return plainPrefix + message + "\n"
+}
@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log format", func() {
+	AfterEach(func() {
+		logFormat = textFormat
+		command = ""
+		now = time.Now
+	})
+
+	It("Prints text by default", func() {
+		Expect(ConfigureLogFormat("rosa whoami")).To(Succeed())
+		Expect(formatLine("warn", warnPrefix, "WARN: ", "Something happened")).To(
+			Equal("WARN: Something happened\n"))
+	})
+
+	It("Prints one JSON object per line", func() {
+		logFormat = jsonFormat
+		now = func() time.Time {
+			return time.Date(2023, 5, 4, 10, 30, 0, 0, time.UTC)
+		}
+		Expect(formatLine("error", errorPrefix, "ERR: ", "Failed \"badly\"")).To(Equal(
+			`{"level":"error","msg":"Failed \"badly\"","time":"2023-05-04T10:30:00Z"}` + "\n"))
+
+		Expect(ConfigureLogFormat("rosa list instance-types")).To(Succeed())
+		Expect(formatLine("debug", infoPrefix, "INFO: ", "Fetching")).To(Equal(
+			`{"level":"debug","msg":"Fetching","time":"2023-05-04T10:30:00Z",` +
+				`"command":"rosa list instance-types"}` + "\n"))
+	})
+
+	It("Rejects an unknown format", func() {
+		logFormat = "yaml"
+		Expect(ConfigureLogFormat("rosa")).To(MatchError(
+			"Invalid log format 'yaml'. Allowed values are [text json]"))
+	})
+})
@@ -19,21 +19,23 @@ package reporter
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 
-	"github.com/openshift/rosa/pkg/color"
 	"github.com/openshift/rosa/pkg/debug"
 )
 
 // Builder contains the information and logic needed to create a new reporter.
 type Builder struct {
-	// Empty on purpose.
+	stream io.Writer
 }
 
-// Object is the reported object used by the tool. It prints the messages to the standard output or
-// error streams.
+// Object is the reported object used by the tool. It prints all the messages to the standard error
+// stream, so that the standard output only contains the result of the command and can be piped to
+// other tools.
 type Object struct {
 	errors int
+	stream io.Writer
 }
 
 // New creates a builder that can then be used to configure and build a reporter.
@@ -41,10 +43,21 @@ func New() *Builder {
 	return &Builder{}
 }
 
+// Stream sets the writer where the messages are printed. The default is the standard error stream.
+func (b *Builder) Stream(value io.Writer) *Builder {
+	b.stream = value
+	return b
+}
+
 // Build uses the information contained in the builder to create a new reporter.
 func (b *Builder) Build() (result *Object, err error) {
 	// Create and populate the object:
-	result = &Object{}
+	result = &Object{
+		stream: b.stream,
+	}
+	if result.stream == nil {
+		result.stream = os.Stderr
+	}
 
 	return
 }
@@ -54,27 +67,20 @@ func (r *Object) Debugf(format string, args ...interface{}) {
 	if !debug.Enabled() {
 		return
 	}
-	r.Infof(format, args...)
+	message := fmt.Sprintf(format, args...)
+	_, _ = fmt.Fprint(r.stream, formatLine("debug", infoPrefix, "INFO: ", message))
 }
 
 // Infof prints an informative message with the given format and arguments.
 func (r *Object) Infof(format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
-	if color.UseColor() {
-		_, _ = fmt.Fprintf(os.Stdout, "%s%s\n", infoPrefix, message)
-	} else {
-		_, _ = fmt.Fprintf(os.Stdout, "%s%s\n", "INFO: ", message)
-	}
+	_, _ = fmt.Fprint(r.stream, formatLine("info", infoPrefix, "INFO: ", message))
 }
 
 // Warnf prints an warning message with the given format and arguments.
 func (r *Object) Warnf(format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
-	if color.UseColor() {
-		_, _ = fmt.Fprintf(os.Stderr, "%s%s\n", warnPrefix, message)
-	} else {
-		_, _ = fmt.Fprintf(os.Stderr, "%s%s\n", "WARN: ", message)
-	}
+	_, _ = fmt.Fprint(r.stream, formatLine("warn", warnPrefix, "WARN: ", message))
 }
 
 // Errorf prints an error message with the given format and arguments. It also return an error
@@ -82,11 +88,7 @@ func (r *Object) Warnf(format string, args ...interface{}) {
 // report the error and also return it.
 func (r *Object) Errorf(format string, args ...interface{}) error {
 	message := fmt.Sprintf(format, args...)
-	if color.UseColor() {
-		_, _ = fmt.Fprintf(os.Stderr, "%s%s\n", errorPrefix, message)
-	} else {
-		_, _ = fmt.Fprintf(os.Stderr, "%s%s\n", "ERR: ", message)
-	}
+	_, _ = fmt.Fprint(r.stream, formatLine("error", errorPrefix, "ERR: ", message))
 	r.errors++
 	return errors.New(message)
 }
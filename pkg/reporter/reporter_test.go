@@ -0,0 +1,21 @@
+package reporter
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reporter", func() {
+	It("Prints all the levels to the same stream", func() {
+		var b bytes.Buffer
+		reporter, err := New().Stream(&b).Build()
+		Expect(err).NotTo(HaveOccurred())
+		reporter.Infof("Fetching")
+		reporter.Warnf("Ignoring")
+		Expect(reporter.Errorf("Failed")).To(MatchError("Failed"))
+		Expect(b.String()).To(Equal("INFO: Fetching\nWARN: Ignoring\nERR: Failed\n"))
+		Expect(reporter.Errors()).To(Equal(1))
+	})
+})
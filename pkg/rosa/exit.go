@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rosa
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/helper"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+// Exit codes of the commands, so that scripts can tell why a command failed without parsing its
+// messages.
+const (
+	// ExitSuccess means that the command succeeded.
+	ExitSuccess = 0
+
+	// ExitUnexpected is used for the errors that don't belong to any other category.
+	ExitUnexpected = 1
+
+	// ExitUsage means that the flags or arguments are invalid, for example an unknown column or a
+	// region that isn't supported.
+	ExitUsage = 2
+
+	// ExitAuth means that the user isn't logged in to OCM, or that the OCM or AWS credentials were
+	// rejected.
+	ExitAuth = 3
+
+	// ExitUpstream means that a request to OCM or AWS failed or timed out.
+	ExitUpstream = 4
+
+	// ExitQuota means that the request succeeded, but the account doesn't have enough quota for what
+	// was asked.
+	ExitQuota = 5
+
+	// ExitNoResults means that the request succeeded, but there was nothing to list. Commands that
+	// accept the '--quiet' flag exit with ExitSuccess instead.
+	ExitNoResults = 6
+)
+
+// exitError is an error with the exit code that the command should use when it fails with it.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// UsageError marks err as caused by invalid flags or arguments.
+func UsageError(err error) error {
+	return &exitError{code: ExitUsage, err: err}
+}
+
+// AuthError marks err as caused by missing or rejected credentials.
+func AuthError(err error) error {
+	return &exitError{code: ExitAuth, err: err}
+}
+
+// UpstreamError marks err as caused by a failed request to OCM or AWS.
+func UpstreamError(err error) error {
+	return &exitError{code: ExitUpstream, err: err}
+}
+
+// QuotaError marks err as caused by not having enough quota.
+func QuotaError(err error) error {
+	return &exitError{code: ExitQuota, err: err}
+}
+
+// NoResultsError marks err as caused by finding nothing to list.
+func NoResultsError(err error) error {
+	return &exitError{code: ExitNoResults, err: err}
+}
+
+// ExitCode returns the exit code for a command that failed with the given error, or ExitSuccess if
+// there is no error. Credentials rejected by OCM or AWS are reported as ExitAuth even if the error
+// was marked as an upstream one, otherwise the code of the mark is used. AWS regions that aren't
+// set or aren't supported are reported as ExitUsage, and the rest of the unmarked OCM and AWS errors
+// as ExitUpstream.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+
+	var ocmErr *ocmerrors.Error
+	isOCM := errors.As(err, &ocmErr)
+	if isOCM && (ocmErr.Status() == http.StatusUnauthorized || ocmErr.Status() == http.StatusForbidden) {
+		return ExitAuth
+	}
+	var apiErr *ocm.APIError
+	isAPI := errors.As(err, &apiErr)
+	if isAPI && (apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden) {
+		return ExitAuth
+	}
+	var authErr *aws.AuthError
+	if errors.As(err, &authErr) {
+		return ExitAuth
+	}
+	var awsErr awserr.Error
+	isAWS := errors.As(err, &awsErr)
+	if isAWS && helper.Contains(aws.AuthErrorCodes, awsErr.Code()) {
+		return ExitAuth
+	}
+
+	var marked *exitError
+	if errors.As(err, &marked) {
+		return marked.code
+	}
+	var regionErr *aws.RegionError
+	if errors.As(err, &regionErr) {
+		return ExitUsage
+	}
+	if isOCM || isAPI || isAWS {
+		return ExitUpstream
+	}
+	return ExitUnexpected
+}
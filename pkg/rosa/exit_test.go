@@ -0,0 +1,96 @@
+package rosa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/logging"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("ExitCode", func() {
+	ocmError := func(status int) error {
+		err, buildErr := ocmerrors.NewError().Status(status).Reason("failed").Build()
+		Expect(buildErr).NotTo(HaveOccurred())
+		return err
+	}
+
+	It("keeps the documented values", func() {
+		// The codes are part of the interface used by scripts, and documented in the help of the
+		// commands, so they must not change:
+		Expect([]int{ExitSuccess, ExitUnexpected, ExitUsage, ExitAuth, ExitUpstream, ExitQuota,
+			ExitNoResults}).To(Equal([]int{0, 1, 2, 3, 4, 5, 6}))
+	})
+
+	DescribeTable("maps errors to exit codes",
+		func(err func() error, expected int) {
+			Expect(ExitCode(err())).To(Equal(expected))
+		},
+		Entry("success", func() error { return nil }, ExitSuccess),
+		Entry("unexpected", func() error { return errors.New("boom") }, ExitUnexpected),
+		Entry("usage", func() error {
+			return UsageError(errors.New("Invalid sort key 'price'"))
+		}, ExitUsage),
+		Entry("auth", func() error {
+			return AuthError(errors.New("Not logged in"))
+		}, ExitAuth),
+		Entry("upstream", func() error {
+			return UpstreamError(errors.New("Failed to fetch instance types"))
+		}, ExitUpstream),
+		Entry("quota", func() error {
+			return QuotaError(errors.New("Not enough quota"))
+		}, ExitQuota),
+		Entry("no results", func() error {
+			return NoResultsError(errors.New("There are no machine types supported for your account"))
+		}, ExitNoResults),
+		Entry("wrapped mark", func() error {
+			return fmt.Errorf("listing: %w", UsageError(errors.New("bad region")))
+		}, ExitUsage),
+		Entry("OCM unauthorized", func() error {
+			return fmt.Errorf("Failed to fetch instance types: %w", ocmError(401))
+		}, ExitAuth),
+		Entry("OCM forbidden marked as upstream", func() error {
+			return UpstreamError(fmt.Errorf("Failed to fetch instance types: %w", ocmError(403)))
+		}, ExitAuth),
+		Entry("OCM server error", func() error {
+			return fmt.Errorf("Failed to fetch instance types: %w", ocmError(500))
+		}, ExitUpstream),
+		Entry("AWS expired token", func() error {
+			return awserr.New("ExpiredToken", "The security token included in the request is expired", nil)
+		}, ExitAuth),
+		Entry("AWS auth error", func() error {
+			return fmt.Errorf("Failed to get the list of the availability zones: %w",
+				aws.WrapAuthError(awserr.New("AuthFailure", "AWS was not able to validate the credentials", nil)))
+		}, ExitAuth),
+		Entry("OCM API error", func() error {
+			return UpstreamError(&ocm.APIError{Status: 401})
+		}, ExitAuth),
+		Entry("AWS throttling", func() error {
+			return awserr.New("Throttling", "Rate exceeded", nil)
+		}, ExitUpstream),
+	)
+
+	It("reports unsupported AWS regions as usage errors", func() {
+		Expect(os.Setenv("AWS_REGION", "ap-south-2")).To(Succeed())
+		defer os.Unsetenv("AWS_REGION")
+		_, err := aws.NewClientForUserRegion(logging.NewLogger(), []string{"us-east-1"})
+		Expect(err).To(HaveOccurred())
+		Expect(ExitCode(err)).To(Equal(ExitUsage))
+	})
+
+	It("reports timeouts as upstream errors", func() {
+		r := &Runtime{}
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		<-ctx.Done()
+		Expect(ExitCode(r.OperationError(ctx, errors.New("context deadline exceeded")))).To(Equal(ExitUpstream))
+	})
+})
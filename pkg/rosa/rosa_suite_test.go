@@ -0,0 +1,13 @@
+package rosa_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRosa(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rosa Suite")
+}
@@ -1,10 +1,17 @@
 package rosa
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"time"
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/rosa/pkg/arguments"
 	"github.com/openshift/rosa/pkg/aws"
+	"github.com/openshift/rosa/pkg/config"
 	"github.com/openshift/rosa/pkg/logging"
 	"github.com/openshift/rosa/pkg/ocm"
 	"github.com/openshift/rosa/pkg/reporter"
@@ -19,22 +26,110 @@ type Runtime struct {
 	Creator    *aws.Creator
 	ClusterKey string
 	Cluster    *cmv1.Cluster
+
+	// Writer is where the command writes its results. NewRuntime sets it to the standard output, tests
+	// can replace it with a buffer to check what is written.
+	Writer io.Writer
+
+	// Offline is the cassette directory whose recorded responses are used instead of sending the
+	// requests to OCM, if any.
+	Offline string
+
+	// SaveCassette is the file where the requests sent to OCM and their responses are saved when the
+	// runtime is cleaned up, if any.
+	SaveCassette string
 }
 
 func NewRuntime() *Runtime {
 	reporter := reporter.CreateReporterOrExit()
 	logger := logging.NewLogger()
-	return &Runtime{Reporter: reporter, Logger: logger}
+	return &Runtime{Reporter: reporter, Logger: logger, Writer: os.Stdout}
 }
 
+// tokenExpiryMargin is how long before the OCM session expires the commands start warning about
+// it, so that the user can log in again before a request fails in the middle of the command.
+const tokenExpiryMargin = 5 * time.Minute
+
 // Adds an OCM client to the runtime. Requires a deferred call to `.Cleanup()` to close connections.
 func (r *Runtime) WithOCM() *Runtime {
 	if r.OCMClient == nil {
+		err := r.checkToken()
+		if err != nil {
+			r.Reporter.Errorf("%s", err)
+			os.Exit(ExitAuth)
+		}
 		r.OCMClient = ocm.CreateNewClientOrExit(r.Logger, r.Reporter)
 	}
 	return r
 }
 
+// WithOCMOrError is like WithOCM, but returns an authentication error instead of exiting when the
+// OCM client can't be created, which usually means that the user isn't logged in.
+func (r *Runtime) WithOCMOrError() error {
+	if r.OCMClient == nil {
+		err := r.checkToken()
+		if err != nil {
+			return err
+		}
+		client, err := ocm.NewClient().
+			Logger(r.Logger).
+			Reporter(r.Reporter).
+			Offline(r.Offline).
+			SaveCassette(r.SaveCassette).
+			Build()
+		if err != nil && r.Offline != "" {
+			return UsageError(fmt.Errorf("Failed to replay OCM responses: %v", err))
+		}
+		if err != nil {
+			return AuthError(fmt.Errorf("Failed to create OCM connection: %v", err))
+		}
+		r.OCMClient = client
+	}
+	return nil
+}
+
+// checkToken checks the expiry of the OCM token stored in the configuration before the OCM client
+// is created, unless the '--skip-token-check' flag was given or the responses are replayed. The
+// problems loading the configuration are left to the creation of the client, which reports them.
+func (r *Runtime) checkToken() error {
+	if arguments.SkipTokenCheck() || r.Offline != "" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg == nil {
+		return nil
+	}
+	warning, err := checkTokenExpiry(cfg, time.Now())
+	if warning != "" {
+		r.Reporter.Warnf("%s", warning)
+	}
+	return err
+}
+
+// checkTokenExpiry returns an authentication error if the OCM session stored in the configuration
+// has already expired, or a warning if it expires within tokenExpiryMargin.
+func checkTokenExpiry(cfg *config.Config, now time.Time) (string, error) {
+	// Tokens that can't be parsed are reported when the client is created:
+	expires, left, err := cfg.SessionExpiry(now)
+	if err != nil || !expires || left > tokenExpiryMargin {
+		return "", nil
+	}
+	if left <= 0 {
+		return "", AuthError(fmt.Errorf("The OCM token expired %s ago, run 'rosa login' to log in again",
+			(-left).Round(time.Second)))
+	}
+	return fmt.Sprintf("The OCM token expires in %s, run 'rosa login' to log in again before it does",
+		left.Round(time.Second)), nil
+}
+
+// Enables the in-memory region cache of the OCM client, so that region lists fetched more than once
+// during the command reuse the first response. Initializes the OCM client if needed.
+func (r *Runtime) WithRegionCache() *Runtime {
+	r.WithOCM()
+	r.OCMClient.EnableRegionCache()
+	return r
+}
+
 // Adds an AWS client to the runtime
 func (r *Runtime) WithAWS() *Runtime {
 	if r.AWSClient == nil {
@@ -51,12 +146,49 @@ func (r *Runtime) WithAWS() *Runtime {
 	return r
 }
 
+// OperationContext returns a context for a single request to OCM or AWS. It is canceled when the
+// time given with the '--timeout' flag expires or when the user presses Ctrl-C, so that a hung
+// connection doesn't block the command forever. The returned function must be called to release
+// the resources of the context.
+func (r *Runtime) OperationContext() (context.Context, context.CancelFunc) {
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), arguments.GetTimeout())
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt)
+	return ctx, func() {
+		stopSignals()
+		cancelTimeout()
+	}
+}
+
+// OperationError explains why an operation using a context returned by OperationContext failed,
+// if it was because the context expired or was canceled. Otherwise it returns err unchanged.
+func (r *Runtime) OperationError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return UpstreamError(fmt.Errorf("operation timed out after %s", arguments.GetTimeout()))
+	case context.Canceled:
+		return UpstreamError(fmt.Errorf("operation canceled"))
+	}
+	return err
+}
+
 func (r *Runtime) Cleanup() {
+	if err := r.CleanupOrError(); err != nil {
+		r.Reporter.Errorf("%s", err)
+	}
+}
+
+// CleanupOrError is like Cleanup, but returns the error closing the OCM connection, for example when
+// the recorded responses can't be saved, instead of reporting it.
+func (r *Runtime) CleanupOrError() error {
 	if r.OCMClient != nil {
 		if err := r.OCMClient.Close(); err != nil {
-			r.Reporter.Errorf("Failed to close OCM connection: %v", err)
+			return fmt.Errorf("Failed to close OCM connection: %v", err)
 		}
 	}
+	return nil
 }
 
 // Load the cluster key provided by the user into the runtime and return it
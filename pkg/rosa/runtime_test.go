@@ -0,0 +1,108 @@
+package rosa
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/rosa/pkg/config"
+	"github.com/openshift/rosa/pkg/ocm"
+)
+
+var _ = Describe("Runtime", func() {
+	r := &Runtime{}
+
+	It("returns a context that expires", func() {
+		ctx, cancel := r.OperationContext()
+		defer cancel()
+		_, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+	})
+
+	It("explains timed out operations", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		<-ctx.Done()
+		err := r.OperationError(ctx, errors.New("context deadline exceeded"))
+		Expect(err).To(MatchError("operation timed out after 30s"))
+	})
+
+	It("explains canceled operations", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := r.OperationError(ctx, errors.New("context canceled"))
+		Expect(err).To(MatchError("operation canceled"))
+	})
+
+	It("returns the error saving the recorded responses when cleaning up", func() {
+		cassette := filepath.Join(GinkgoT().TempDir(), "missing", "cassette.json")
+		client, err := ocm.NewClient().
+			Logger(logrus.New()).
+			Config(&config.Config{
+				URL:         "https://api.openshift.com",
+				AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+			}).
+			SaveCassette(cassette).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		runtime := &Runtime{OCMClient: client}
+		Expect(runtime.CleanupOrError()).To(MatchError(HavePrefix(
+			"Failed to close OCM connection: Failed to save cassette '" + cassette + "'")))
+		Expect((&Runtime{}).CleanupOrError()).To(Succeed())
+	})
+
+	It("keeps other errors", func() {
+		ctx, cancel := r.OperationContext()
+		defer cancel()
+		Expect(r.OperationError(ctx, errors.New("not found"))).To(MatchError("not found"))
+		Expect(r.OperationError(ctx, nil)).To(Succeed())
+	})
+})
+
+var _ = Describe("checkTokenExpiry", func() {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	makeToken := func(typ string, expiry time.Time) string {
+		return MakeTokenObject(jwt.MapClaims{"typ": typ, "exp": expiry.Unix()}).Raw
+	}
+
+	It("fails when the token has expired", func() {
+		cfg := &config.Config{AccessToken: makeToken("Bearer", now.Add(-10*time.Minute))}
+		warning, err := checkTokenExpiry(cfg, now)
+		Expect(warning).To(BeEmpty())
+		Expect(err).To(MatchError("The OCM token expired 10m0s ago, run 'rosa login' to log in again"))
+		Expect(ExitCode(err)).To(Equal(ExitAuth))
+	})
+
+	It("warns when the token is about to expire", func() {
+		cfg := &config.Config{AccessToken: makeToken("Bearer", now.Add(3*time.Minute))}
+		warning, err := checkTokenExpiry(cfg, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warning).To(Equal("The OCM token expires in 3m0s, run 'rosa login' to log in again before it does"))
+	})
+
+	It("doesn't warn when the token expires later", func() {
+		cfg := &config.Config{AccessToken: makeToken("Bearer", now.Add(10*time.Minute))}
+		Expect(checkTokenExpiry(cfg, now)).To(BeEmpty())
+	})
+
+	It("uses the refresh token that renews an expired access token", func() {
+		cfg := &config.Config{
+			AccessToken:  makeToken("Bearer", now.Add(-time.Minute)),
+			RefreshToken: makeToken("Refresh", now.Add(10*time.Hour)),
+		}
+		Expect(checkTokenExpiry(cfg, now)).To(BeEmpty())
+	})
+
+	It("accepts client credentials", func() {
+		cfg := &config.Config{ClientID: "my-client", ClientSecret: "my-secret"}
+		Expect(checkTokenExpiry(cfg, now)).To(BeEmpty())
+	})
+})
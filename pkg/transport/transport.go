@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This package contains the HTTP transport shared by the OCM and AWS clients, so that the
+// connections opened by one request are reused by the next ones instead of doing a new TLS
+// handshake for each of them.
+
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// DefaultMaxIdleConnections is the number of idle connections kept open for each host when the
+	// '--max-idle-connections' flag isn't set.
+	DefaultMaxIdleConnections = 10
+
+	// DefaultIdleConnectionTimeout is the time that an idle connection is kept open when the
+	// '--idle-connection-timeout' flag isn't set.
+	DefaultIdleConnectionTimeout = 90 * time.Second
+)
+
+var (
+	maxIdleConnections    = DefaultMaxIdleConnections
+	idleConnectionTimeout = DefaultIdleConnectionTimeout
+
+	proxy   proxyValue
+	noProxy []string
+
+	insecureSkipTLSVerify bool
+
+	shared     *http.Transport
+	sharedOnce sync.Once
+)
+
+// proxyValue is the value of the '--proxy' flag. The URL is checked when the flag is parsed, so
+// that a typo is reported before sending any request.
+type proxyValue struct {
+	url *url.URL
+}
+
+func (v *proxyValue) String() string {
+	if v.url == nil {
+		return ""
+	}
+	return v.url.String()
+}
+
+func (v *proxyValue) Set(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https" &&
+		parsed.Scheme != "socks5") {
+		return fmt.Errorf("Invalid proxy URL '%s'. It must be like 'http://proxy.example.com:3128'", value)
+	}
+	v.url = parsed
+	return nil
+}
+
+func (v *proxyValue) Type() string {
+	return "url"
+}
+
+// AddFlags adds the '--max-idle-connections', '--idle-connection-timeout', '--proxy', '--no-proxy'
+// and '--insecure-skip-tls-verify' flags to the given set of command line flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.IntVar(
+		&maxIdleConnections,
+		"max-idle-connections",
+		DefaultMaxIdleConnections,
+		"Maximum number of idle connections to each OCM or AWS host kept open for later requests.",
+	)
+	flags.DurationVar(
+		&idleConnectionTimeout,
+		"idle-connection-timeout",
+		DefaultIdleConnectionTimeout,
+		"Time that an idle connection to OCM or AWS is kept open for later requests.",
+	)
+	flags.Var(
+		&proxy,
+		"proxy",
+		"URL of the proxy used for the requests to OCM and AWS, for example 'http://proxy.example.com:3128'. "+
+			"It takes precedence over the 'HTTPS_PROXY' and 'HTTP_PROXY' environment variables.",
+	)
+	flags.StringSliceVar(
+		&noProxy,
+		"no-proxy",
+		nil,
+		"Comma separated list of hosts, domains or CIDR ranges that are reached without the proxy, in "+
+			"addition to the ones of the 'NO_PROXY' environment variable. Use '*' to disable the proxy.",
+	)
+	flags.BoolVar(
+		&insecureSkipTLSVerify,
+		"insecure-skip-tls-verify",
+		false,
+		"Don't verify the TLS certificates of OCM and AWS. This is insecure and only intended for test "+
+			"environments with self-signed certificates.",
+	)
+	// Hidden to discourage its use, it is only useful when testing against local or staging servers:
+	_ = flags.MarkHidden("insecure-skip-tls-verify")
+}
+
+// InsecureSkipTLSVerify returns true if the '--insecure-skip-tls-verify' flag has been used, so that
+// the command can warn about it.
+func InsecureSkipTLSVerify() bool {
+	return insecureSkipTLSVerify
+}
+
+// Shared returns the transport used by all the OCM and AWS clients of the command. It is created
+// the first time that it is needed, after the command line flags have been parsed.
+func Shared() *http.Transport {
+	sharedOnce.Do(func() {
+		shared = New(maxIdleConnections, idleConnectionTimeout)
+	})
+	return shared
+}
+
+// New returns a transport with keep-alive enabled that keeps at most the given number of idle
+// connections to each host, for the given time. The rest of the settings, like the proxy from the
+// environment, are the ones of the default transport.
+func New(maxIdle int, idleTimeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdle
+	transport.IdleConnTimeout = idleTimeout
+	transport.Proxy = proxyFunc(proxy.url, noProxy)
+	if insecureSkipTLSVerify {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true // nolint:gosec
+	}
+	return transport
+}
+
+// proxyFunc returns the function that selects the proxy of each request: none for the hosts that
+// match the no proxy list, the given proxy if any, or else the one from the environment.
+func proxyFunc(proxyURL *url.URL, noProxy []string) func(*http.Request) (*url.URL, error) {
+	return func(request *http.Request) (*url.URL, error) {
+		if bypassProxy(request.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		if proxyURL != nil {
+			return proxyURL, nil
+		}
+		return http.ProxyFromEnvironment(request)
+	}
+}
+
+// bypassProxy returns true if the host matches one of the entries of the no proxy list: '*', an IP
+// address or CIDR range, or a domain, which also matches its subdomains.
+func bypassProxy(host string, noProxy []string) bool {
+	ip := net.ParseIP(host)
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		host = strings.ToLower(host)
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Transport", func() {
+	It("keeps the connections alive with the given limits", func() {
+		transport := New(5, time.Minute)
+		Expect(transport.DisableKeepAlives).To(BeFalse())
+		Expect(transport.MaxIdleConnsPerHost).To(Equal(5))
+		Expect(transport.IdleConnTimeout).To(Equal(time.Minute))
+		Expect(transport.Proxy).NotTo(BeNil())
+	})
+
+	It("verifies the TLS certificates unless told otherwise", func() {
+		transport := New(5, time.Minute)
+		if transport.TLSClientConfig != nil {
+			Expect(transport.TLSClientConfig.InsecureSkipVerify).To(BeFalse())
+		}
+		insecureSkipTLSVerify = true
+		defer func() {
+			insecureSkipTLSVerify = false
+		}()
+		transport = New(5, time.Minute)
+		Expect(transport.TLSClientConfig).NotTo(BeNil())
+		Expect(transport.TLSClientConfig.InsecureSkipVerify).To(BeTrue())
+	})
+
+	It("returns the same shared transport every time", func() {
+		Expect(Shared()).To(BeIdenticalTo(Shared()))
+		Expect(Shared().MaxIdleConnsPerHost).To(Equal(DefaultMaxIdleConnections))
+	})
+
+	Describe("Proxy", func() {
+		AfterEach(func() {
+			proxy = proxyValue{}
+			noProxy = nil
+		})
+
+		It("rejects an invalid proxy URL", func() {
+			Expect(proxy.Set("proxy.example.com:3128")).To(MatchError(
+				"Invalid proxy URL 'proxy.example.com:3128'. It must be like 'http://proxy.example.com:3128'"))
+			Expect(proxy.Set("ftp://proxy.example.com")).NotTo(Succeed())
+			Expect(proxy.Set("http://proxy.example.com:3128")).To(Succeed())
+		})
+
+		It("sends the requests of the client through the proxy", func() {
+			server := ghttp.NewServer()
+			defer server.Close()
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1"),
+				func(_ http.ResponseWriter, request *http.Request) {
+					Expect(request.Host).To(Equal("api.openshift.example"))
+				},
+				ghttp.RespondWith(http.StatusOK, "{}"),
+			))
+			Expect(proxy.Set(server.URL())).To(Succeed())
+			client := &http.Client{Transport: New(DefaultMaxIdleConnections, DefaultIdleConnectionTimeout)}
+			response, err := client.Get("http://api.openshift.example/api/clusters_mgmt/v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Body.Close()).To(Succeed())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("bypasses the proxy for the hosts in the no proxy list", func() {
+			Expect(proxy.Set("http://proxy.example.com:3128")).To(Succeed())
+			noProxy = []string{".internal.example", "10.0.0.0/8"}
+			transport := New(DefaultMaxIdleConnections, DefaultIdleConnectionTimeout)
+			for host, proxied := range map[string]bool{
+				"api.openshift.example": true,
+				"internal.example":      false,
+				"sts.internal.example":  false,
+				"10.1.2.3":              false,
+				"192.168.0.1":           true,
+			} {
+				request, err := http.NewRequest(http.MethodGet, "https://"+host+"/", nil)
+				Expect(err).NotTo(HaveOccurred())
+				proxyURL, err := transport.Proxy(request)
+				Expect(err).NotTo(HaveOccurred())
+				if proxied {
+					Expect(proxyURL).NotTo(BeNil(), host)
+					Expect(proxyURL.String()).To(Equal("http://proxy.example.com:3128"))
+				} else {
+					Expect(proxyURL).To(BeNil(), host)
+				}
+			}
+		})
+	})
+})